@@ -0,0 +1,34 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+	"quiz/quiz_logic"
+)
+
+// bankFiles holds the quizzes in the bank directory, compiled into the
+// binary for learners who do not have a quiz folder
+//
+//go:embed bank
+var bankFiles embed.FS
+
+// embeddedBank returns the embedded quizzes as a file system of their own
+func embeddedBank() fs.FS {
+	bank, err := fs.Sub(bankFiles, "bank")
+	if err != nil {
+		panic(err) // the directory is embedded, so this cannot happen
+	}
+	return bank
+}
+
+// usesEmbeddedBank reports whether the embedded quizzes stand in for the
+// default quiz folder, which happens only when no roots were configured
+// and that folder does not exist
+func usesEmbeddedBank(discovery quiz_logic.Discovery) bool {
+	if len(discovery.Roots) != 1 || discovery.Roots[0] != quiz_logic.DefaultRoot {
+		return false
+	}
+	_, err := os.Stat(quiz_logic.DefaultRoot)
+	return err != nil
+}
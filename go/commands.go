@@ -0,0 +1,1159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"quiz/quiz_logic"
+	"quiz/quizpb"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// runExam renders printable exam versions with answer keys for a quiz
+func runExam(args []string) error {
+	flags := flag.NewFlagSet("exam", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	versions := flags.Int("versions", 1, "number of distinct exam versions")
+	seed := flags.Int64("seed", 0, "seed for the set of versions (0 picks one at random)")
+	formats := flags.String("format", "markdown,html,text", "comma-separated output formats: markdown, html, text")
+	outDir := flags.String("out", "exams", "directory to write exams and answer keys to")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz exam [flags] <quiz ID or directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	quiz, err := source.find(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	var config quiz_logic.Config
+	var exams []quiz_logic.ExamVersion
+	if quiz.FS != nil {
+		config, exams, err = quiz_logic.GenerateExamsFS(quiz.FS, quiz.Path, *versions, *seed)
+	} else {
+		config, exams, err = quiz_logic.GenerateExams(quiz.Path, *versions, *seed)
+	}
+	if err != nil {
+		return err
+	}
+
+	var formatList []string
+	for _, format := range strings.Split(*formats, ",") {
+		formatList = append(formatList, strings.TrimSpace(format)) // such as "markdown, html"
+	}
+	written, err := quiz_logic.WriteExams(*outDir, config, exams, formatList)
+	if err != nil {
+		return err
+	}
+
+	for _, exam := range exams {
+		fmt.Printf("Version %d: seed %d\n", exam.Number, exam.Seed)
+	}
+	fmt.Printf("Wrote %d files to %s\n", len(written), *outDir)
+	return nil
+}
+
+// runExport converts a quiz to Moodle XML, a QTI package or CSV
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	format := flags.String("format", "", "output format: moodle, qti or csv")
+	outFile := flags.String("out", "", "file to write")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz export -format <format> -out <file> <quiz ID or directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *format == "" || *outFile == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+	quiz, err := source.find(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if quiz.FS != nil {
+		return fmt.Errorf("quiz %s is built into the program and cannot be exported", quiz.ID)
+	}
+
+	f, err := os.Create(*outFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	problems, err := quiz_logic.ExportQuiz(f, quiz.Path, *format)
+	if err != nil {
+		return err
+	}
+	printProblems(problems)
+	fmt.Printf("Exported %s to %s\n", quiz.Path, *outFile)
+	return nil
+}
+
+// listFlag collects a flag that may be given more than once
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func printProblems(problems []string) {
+	if len(problems) > 0 {
+		fmt.Println("Could not fully convert:")
+		for _, problem := range problems {
+			fmt.Printf("  %s\n", problem)
+		}
+	}
+}
+
+// runImport converts a GIFT, Aiken, Moodle XML or QTI file into a quiz
+// directory
+func runImport(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "input format: gift, aiken, moodle, qti, csv or bundle (default: from the file extension)")
+	title := flags.String("title", "", "quiz title (default: the file name)")
+	outDir := flags.String("out", "", "quiz directory to create")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz import [flags] -out <quiz directory> <file>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *outDir == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	result, err := quiz_logic.ImportFile(flags.Arg(0), *format)
+	if err != nil {
+		return err
+	}
+	if *title != "" {
+		result.Config.Title = *title
+	}
+
+	printProblems(result.Problems)
+	if len(result.Questions) == 0 {
+		return fmt.Errorf("no questions could be converted")
+	}
+
+	if err := quiz_logic.WriteQuizDir(*outDir, result.Config, result.Questions); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d questions into %s\n", len(result.Questions), *outDir)
+	return nil
+}
+
+// runImportCSV creates a quiz directory from a spreadsheet export. Unlike
+// import it refuses to write anything while a row is invalid.
+func runImportCSV(args []string) error {
+	flags := flag.NewFlagSet("import-csv", flag.ExitOnError)
+	title := flags.String("title", "", "quiz title (default: the file name)")
+	outDir := flags.String("out", "", "quiz directory to create")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz import-csv [flags] -out <quiz directory> <file.csv>")
+		fmt.Fprintf(flags.Output(), "Columns: %s\n", strings.Join(quiz_logic.CSVColumns, ", "))
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 || *outDir == "" {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	result, err := quiz_logic.ImportFile(flags.Arg(0), "csv")
+	if err != nil {
+		return err
+	}
+	if len(result.Problems) > 0 {
+		fmt.Println("Invalid rows:")
+		for _, problem := range result.Problems {
+			fmt.Printf("  %s\n", problem)
+		}
+		return fmt.Errorf("fix the rows above and import again")
+	}
+	if *title != "" {
+		result.Config.Title = *title
+	}
+
+	if err := quiz_logic.WriteQuizDir(*outDir, result.Config, result.Questions); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d questions into %s\n", len(result.Questions), *outDir)
+	return nil
+}
+
+// runExportCSV writes a quiz's questions as CSV, to stdout unless -out is
+// given
+func runExportCSV(args []string) error {
+	flags := flag.NewFlagSet("export-csv", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	outFile := flags.String("out", "", "file to write (default: standard output)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz export-csv [flags] <quiz ID or directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	quiz, err := source.find(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if quiz.FS != nil {
+		return fmt.Errorf("quiz %s is built into the program and cannot be exported", quiz.ID)
+	}
+
+	out := os.Stdout
+	if *outFile != "" {
+		f, err := os.Create(*outFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	problems, err := quiz_logic.ExportQuiz(out, quiz.Path, "csv")
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", problem)
+	}
+	return nil
+}
+
+// runBundle packs a quiz directory into a single .quiz.zip file
+func runBundle(args []string) error {
+	flags := flag.NewFlagSet("bundle", flag.ExitOnError)
+	outFile := flags.String("out", "", "bundle to write (default: the directory name with "+quiz_logic.BundleExt+")")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz bundle [-out <file"+quiz_logic.BundleExt+">] <quiz directory>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if *outFile == "" {
+		*outFile = filepath.Base(filepath.Clean(flags.Arg(0))) + quiz_logic.BundleExt
+	}
+
+	f, err := os.Create(*outFile)
+	if err != nil {
+		return err
+	}
+	if err := quiz_logic.WriteBundle(f, flags.Arg(0)); err != nil {
+		f.Close()
+		os.Remove(*outFile)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Bundled %s into %s\n", flags.Arg(0), *outFile)
+	return nil
+}
+
+// runQuote prints a quote, picked with the seed like a quiz's questions
+func runQuote(args []string) error {
+	flags := flag.NewFlagSet("quote", flag.ExitOnError)
+	seed := flags.Int64("seed", 0, "seed that picks the quote (0 picks one at random)")
+	kind := flags.String("kind", "learning", "kind of quote: learning, wisdom or humor")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz quote [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	quoter := quiz_logic.NewQuoter()
+	if *seed != 0 {
+		quoter = quiz_logic.NewQuoterWithSeed(*seed)
+	}
+	switch *kind {
+	case "learning":
+		fmt.Println(quoter.GetRandomQuote())
+	case "wisdom":
+		fmt.Println(quoter.GetWisdomQuote())
+	case "humor":
+		fmt.Println(quoter.GetHumorQuote())
+	default:
+		return fmt.Errorf("unknown kind %q, use learning, wisdom or humor", *kind)
+	}
+	return nil
+}
+
+// quizSource holds the flags that say where quizzes are found, shared by
+// the menu and every command that looks quizzes up
+type quizSource struct {
+	roots, include, exclude listFlag
+}
+
+func addQuizSourceFlags(flags *flag.FlagSet) *quizSource {
+	source := &quizSource{}
+	flags.Var(&source.roots, "root", "folder to find quizzes in, may be repeated (default: $"+quiz_logic.RootsEnv+", the settings file or ../quiz)")
+	flags.Var(&source.include, "include", "only list quizzes whose path matches this pattern, may be repeated")
+	flags.Var(&source.exclude, "exclude", "skip quizzes and folders whose path matches this pattern, may be repeated")
+	return source
+}
+
+// catalog discovers the quizzes from the settings file, overridden by the
+// flags. Without any quiz folder the quizzes built into the binary are used.
+func (s *quizSource) catalog() (*quiz_logic.Catalog, error) {
+	discovery, err := quiz_logic.LoadDiscovery()
+	if err != nil {
+		return nil, fmt.Errorf("error loading settings: %v", err)
+	}
+	if len(s.roots) > 0 {
+		discovery.Roots = s.roots
+	}
+	if len(s.include) > 0 {
+		discovery.Include = s.include
+	}
+	if len(s.exclude) > 0 {
+		discovery.Exclude = s.exclude
+	}
+
+	var bank fs.FS
+	if usesEmbeddedBank(discovery) {
+		bank = embeddedBank()
+	}
+	return quiz_logic.NewCatalog(discovery, bank)
+}
+
+// find looks a quiz up by ID or menu number, or else takes ref as the path
+// to a quiz directory, Markdown quiz file or bundle
+func (s *quizSource) find(ref string) (quiz_logic.QuizInfo, error) {
+	catalog, err := s.catalog()
+	if err != nil {
+		return quiz_logic.QuizInfo{}, err
+	}
+	quizzes, problems := catalog.Quizzes()
+	if quiz, ok := quiz_logic.FindQuiz(quizzes, ref); ok {
+		return quiz, nil
+	}
+	if _, err := os.Stat(ref); err == nil {
+		return quiz_logic.QuizInfo{Path: ref}, nil
+	}
+	for _, problem := range problems {
+		if strings.EqualFold(problem.Path, ref) {
+			return quiz_logic.QuizInfo{}, problem
+		}
+	}
+	return quiz_logic.QuizInfo{}, fmt.Errorf("no quiz with ID or path %q", ref)
+}
+
+// addResultsFlag adds the flag naming the results file; resultsFile
+// resolves its default
+func addResultsFlag(flags *flag.FlagSet) *string {
+	return flags.String("results", "", "file results are recorded in (default: $"+quiz_logic.ResultsEnv+" or results.jsonl next to the settings file)")
+}
+
+func resultsFile(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	return quiz_logic.ResultsPath()
+}
+
+// addProfilesFlag adds the flag naming the learner profiles file;
+// profilesFile resolves its default
+func addProfilesFlag(flags *flag.FlagSet) *string {
+	return flags.String("profiles", "", "file learner profiles are kept in (default: $"+quiz_logic.ProfilesEnv+" or profiles.json next to the settings file)")
+}
+
+func profilesFile(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+	return quiz_logic.ProfilesPath()
+}
+
+// openProfile returns the profile with ID or name ref, or the current
+// user's, adding it the first time
+func openProfile(profilesPath, ref string) (quiz_logic.Profile, error) {
+	path, err := profilesFile(profilesPath)
+	if err != nil {
+		return quiz_logic.Profile{}, err
+	}
+	if ref == "" {
+		ref = currentLearner()
+	}
+	return quiz_logic.OpenProfile(path, ref)
+}
+
+// findProfile returns the profile with ID or name ref, or the current
+// user's, without adding one, so a scripted run changes nothing but the
+// results. A learner without a profile is recorded by name alone.
+func findProfile(profilesPath, ref string) (quiz_logic.Profile, error) {
+	path, err := profilesFile(profilesPath)
+	if err != nil {
+		return quiz_logic.Profile{}, err
+	}
+	if ref == "" {
+		ref = currentLearner()
+	}
+	return quiz_logic.LookupProfile(path, ref)
+}
+
+// currentLearner is the default learner name: the user running the program
+func currentLearner() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+	return os.Getenv("USER")
+}
+
+// saveResult records result in the results file named by the -results flag
+func saveResult(resultsPath string, result quiz_logic.Result) error {
+	resultsPath, err := resultsFile(resultsPath)
+	if err != nil {
+		return fmt.Errorf("error saving result: %v", err)
+	}
+	return quiz_logic.SaveResult(resultsPath, result)
+}
+
+// loadResults reads the results file named by the -results flag
+func loadResults(resultsPath string) ([]quiz_logic.Result, error) {
+	resultsPath, err := resultsFile(resultsPath)
+	if err != nil {
+		return nil, err
+	}
+	return quiz_logic.LoadResults(resultsPath)
+}
+
+// rankingFlags choose how leaderboards rank attempts
+type rankingFlags struct {
+	attempts, ties *string
+}
+
+func addRankingFlags(flags *flag.FlagSet) rankingFlags {
+	return rankingFlags{
+		attempts: flags.String("attempts", "best", "which attempt of each learner leaderboards rank: best or latest"),
+		ties:     flags.String("ties", "shared", "how leaderboards rank equal scores and times: shared (1, 1, 3), dense (1, 1, 2) or first (who finished first ranks higher)"),
+	}
+}
+
+func (r rankingFlags) options() (quiz_logic.LeaderboardOptions, error) {
+	options := quiz_logic.LeaderboardOptions{Attempts: quiz_logic.AttemptRule(*r.attempts), Ties: quiz_logic.TieRule(*r.ties)}
+	return options, options.Check()
+}
+
+// addUIFlag adds the flag that picks the full-screen interface or line
+// prompts; chooseUI resolves it
+func addUIFlag(flags *flag.FlagSet) *string {
+	return flags.String("ui", "plain", "interface: plain for line prompts, tui for full screen, or auto to use full screen on capable terminals")
+}
+
+func chooseUI(mode string) (bool, error) {
+	switch mode {
+	case "auto":
+		return quiz_logic.CanUseTUI(), nil
+	case "tui":
+		return true, nil
+	case "plain":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown interface %q, use auto, tui or plain", mode)
+}
+
+// checkFormat rejects output formats a command does not write
+func checkFormat(format string, formats ...string) error {
+	for _, known := range formats {
+		if format == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown format %q, use %s", format, strings.Join(formats, " or "))
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// runList prints the quizzes that can be taken and those that cannot
+func runList(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	format := flags.String("format", "text", "output format: text or json")
+	category := flags.String("category", "", "only list quizzes in this category")
+	search := flags.String("search", "", "only list quizzes whose title, description or tags contain this")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz list [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+
+	catalog, err := source.catalog()
+	if err != nil {
+		return err
+	}
+	quizzes, problems := catalog.Quizzes()
+	quizzes = quiz_logic.FilterQuizzes(quizzes, quiz_logic.QuizFilter{Category: *category, Keyword: *search})
+
+	if *format == "json" {
+		return printJSON(struct {
+			Quizzes  []quiz_logic.QuizInfo    `json:"quizzes"`
+			Problems []quiz_logic.QuizProblem `json:"problems"`
+		}{append([]quiz_logic.QuizInfo{}, quizzes...), append([]quiz_logic.QuizProblem{}, problems...)})
+	}
+	quiz_logic.ListQuizzes(os.Stdout, quizzes, problems)
+	return nil
+}
+
+// runTake runs one quiz and records the result. With -answers the answers
+// come from a file instead of the learner, for automated runs that are
+// not recorded. With -output the result is also written for other tools.
+func runTake(args []string) error {
+	flags := flag.NewFlagSet("take", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	seed := flags.Int64("seed", 0, "seed for question selection and shuffling (0 picks one at random)")
+	learner := flags.String("learner", "", "profile ID or name of the learner; other names are recorded without a profile (default: the current user)")
+	profilesPath := addProfilesFlag(flags)
+	resultsPath := addResultsFlag(flags)
+	answersPath := flags.String("answers", "", "answer file keyed by question ID (.json, .yaml, .yml or .toml), or a file of one answer per line, - for standard input")
+	output := flags.String("output", "text", "result format: text, json, csv or junit")
+	outputFile := flags.String("output-file", "", "file to write the result to (default: standard output, with the quiz shown on standard error)")
+	ui := addUIFlag(flags)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz take [flags] <quiz ID or path>")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*output, "text", "json", "csv", "junit"); err != nil {
+		return err
+	}
+	useTUI, err := chooseUI(*ui)
+	if err != nil {
+		return err
+	}
+	// The full screen needs the keyboard and standard output to itself
+	useTUI = useTUI && *answersPath == "" && (*output == "text" || *outputFile != "")
+
+	info, err := source.find(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	quiz, err := info.Load(*seed)
+	if err != nil {
+		return err
+	}
+	profile, err := findProfile(*profilesPath, *learner)
+	if err != nil {
+		return err
+	}
+	quiz.SetProfile(profile)
+
+	switch {
+	case *answersPath == "":
+	case *answersPath == "-":
+		quiz.In = os.Stdin
+	case quiz_logic.IsAnswerFile(*answersPath):
+		if quiz.Answers, err = quiz_logic.LoadAnswers(*answersPath); err != nil {
+			return err
+		}
+		if err := quiz.CheckAnswers(); err != nil {
+			return err
+		}
+	default:
+		f, err := os.Open(*answersPath)
+		if err != nil {
+			return fmt.Errorf("error reading answers: %v", err)
+		}
+		defer f.Close()
+		quiz.In = f
+	}
+
+	// Keep standard output for the result alone
+	var out io.Writer = os.Stdout
+	if *output != "text" && *outputFile == "" {
+		quiz.Out = os.Stderr
+	}
+	if *output != "text" && *outputFile != "" {
+		f, err := os.Create(*outputFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	var result quiz_logic.Result
+	if useTUI {
+		tui, err := quiz_logic.OpenTUI()
+		if err != nil {
+			return err
+		}
+		result = tui.RunQuiz(quiz)
+		tui.Close()
+	} else {
+		result = quiz.Run()
+	}
+	if *answersPath == "" {
+		if err := saveResult(*resultsPath, result); err != nil {
+			return err
+		}
+	}
+	if *output == "text" {
+		return nil
+	}
+	return quiz_logic.WriteResult(out, result, *output)
+}
+
+// runLint checks the given quizzes, or every quiz below the roots, and
+// fails when any of them cannot be taken
+func runLint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz lint [flags] [quiz path ...]")
+		fmt.Fprintln(flags.Output(), "Without paths, every quiz below the roots is checked.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+
+	checked := flags.NArg()
+	problems := []quiz_logic.QuizProblem{}
+	if checked > 0 {
+		for _, quizPath := range flags.Args() {
+			if err := quiz_logic.LintQuiz(quizPath); err != nil {
+				problems = append(problems, quiz_logic.QuizProblem{Path: quizPath, Err: err})
+			}
+		}
+	} else {
+		catalog, err := source.catalog()
+		if err != nil {
+			return err
+		}
+		quizzes, found := catalog.Quizzes()
+		checked = len(quizzes) + len(found)
+		problems = append(problems, found...)
+	}
+
+	if *format == "json" {
+		if err := printJSON(struct {
+			Checked  int                      `json:"checked"`
+			Problems []quiz_logic.QuizProblem `json:"problems"`
+		}{checked, problems}); err != nil {
+			return err
+		}
+	} else {
+		for _, problem := range problems {
+			fmt.Printf("%s\n  %v\n", problem.Path, problem.Err)
+		}
+		fmt.Printf("Checked %d quizzes, %d with problems\n", checked, len(problems))
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+	return nil
+}
+
+// runResults prints the recorded results, oldest first
+func runResults(args []string) error {
+	flags := flag.NewFlagSet("results", flag.ExitOnError)
+	resultsPath := addResultsFlag(flags)
+	quizID := flags.String("quiz", "", "only show results of the quiz with this ID")
+	learner := flags.String("learner", "", "only show results of the learner with this profile ID or name")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz results [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+
+	path, err := resultsFile(*resultsPath)
+	if err != nil {
+		return err
+	}
+	results, err := quiz_logic.LoadResults(path)
+	if err != nil {
+		return err
+	}
+	results = quiz_logic.FilterResults(results, *quizID, *learner)
+
+	if *format == "json" {
+		return printJSON(append([]quiz_logic.Result{}, results...))
+	}
+	if len(results) == 0 {
+		fmt.Println("No results recorded.")
+		return nil
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "Finished\tLearner\tQuiz\tScore\tResult")
+	for _, result := range results {
+		outcome := "failed"
+		if result.Passed {
+			outcome = "passed"
+		}
+		fmt.Fprintf(table, "%s\t%s\t%s\t%d/%d (%d%%)\t%s\n", result.Finished.Local().Format("2006-01-02 15:04"),
+			result.Learner, result.QuizID, result.Correct, result.Total, result.Score, outcome)
+	}
+	return table.Flush()
+}
+
+// runProfiles lists, adds and removes the profiles learners pick to have
+// their results recorded under
+func runProfiles(args []string) error {
+	flags := flag.NewFlagSet("profiles", flag.ExitOnError)
+	profilesPath := addProfilesFlag(flags)
+	id := flags.String("id", "", "ID of the profile to add (default: made from the name)")
+	group := flags.String("group", "", "group of the profile to add, such as a class")
+	format := flags.String("format", "text", "output format of the list: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), `Usage: quiz profiles [flags]                 list the profiles
+       quiz profiles add [flags] <name>      add a profile
+       quiz profiles remove [flags] <ID>     remove a profile, keeping its results`)
+		flags.PrintDefaults()
+	}
+	action := "list"
+	if len(args) > 0 && (args[0] == "add" || args[0] == "remove") {
+		action, args = args[0], args[1:]
+	}
+	flags.Parse(args)
+
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+	if (action == "list") != (flags.NArg() == 0) || flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	path, err := profilesFile(*profilesPath)
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "add":
+		profile, err := quiz_logic.AddProfile(path, flags.Arg(0), *id, *group)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Added %s\n", profile)
+		return nil
+	case "remove":
+		return quiz_logic.RemoveProfile(path, flags.Arg(0))
+	}
+
+	profiles, err := quiz_logic.LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+	if *format == "json" {
+		return printJSON(append([]quiz_logic.Profile{}, profiles...))
+	}
+	if len(profiles) == 0 {
+		fmt.Println("No profiles yet.")
+		return nil
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tName\tGroup")
+	for _, profile := range profiles {
+		fmt.Fprintf(table, "%s\t%s\t%s\n", profile.ID, profile.Name, profile.Group)
+	}
+	return table.Flush()
+}
+
+// runDashboard shows, for each learner, how many times they took each
+// quiz, their best and last scores and whether they have passed it
+func runDashboard(args []string) error {
+	flags := flag.NewFlagSet("dashboard", flag.ExitOnError)
+	resultsPath := addResultsFlag(flags)
+	learner := flags.String("learner", "", "only show the learner with this profile ID or name")
+	group := flags.String("group", "", "only show learners in this group")
+	quizID := flags.String("quiz", "", "only show the quiz with this ID")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz dashboard [flags]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+
+	path, err := resultsFile(*resultsPath)
+	if err != nil {
+		return err
+	}
+	results, err := quiz_logic.LoadResults(path)
+	if err != nil {
+		return err
+	}
+	standings := quiz_logic.Standings(quiz_logic.FilterResults(results, *quizID, ""))
+	standings = quiz_logic.FilterStandings(standings, *learner, *group)
+
+	if *format == "json" {
+		return printJSON(append([]quiz_logic.Standing{}, standings...))
+	}
+	if len(standings) == 0 {
+		fmt.Println("No results recorded.")
+		return nil
+	}
+	// One table for each learner, as standings come sorted by learner
+	for start := 0; start < len(standings); {
+		end := start + 1
+		for end < len(standings) && standings[end].LearnerID == standings[start].LearnerID &&
+			strings.EqualFold(standings[end].Learner, standings[start].Learner) {
+			end++
+		}
+		printStandings(standings[start:end])
+		start = end
+	}
+	return nil
+}
+
+// printStandings prints one learner's standings under a heading
+func printStandings(standings []quiz_logic.Standing) {
+	first := standings[0]
+	heading := first.Learner
+	if first.LearnerID != "" {
+		heading = quiz_logic.Profile{ID: first.LearnerID, Name: first.Learner, Group: first.Group}.String()
+	}
+	passed := 0
+	for _, standing := range standings {
+		if standing.Passed {
+			passed++
+		}
+	}
+	fmt.Printf("\n%s: passed %d of %d quizzes taken\n", heading, passed, len(standings))
+
+	table := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "Quiz\tAttempts\tBest\tLast\tStatus\tLast taken")
+	for _, standing := range standings {
+		quiz, status := standing.QuizID, "not passed"
+		if quiz == "" {
+			quiz = standing.Title // recorded before quizzes had IDs
+		}
+		if standing.Passed {
+			status = "passed"
+		}
+		fmt.Fprintf(table, "%s\t%d\t%d%%\t%d%%\t%s\t%s\n", quiz, standing.Attempts,
+			standing.BestScore, standing.LastScore, status, standing.LastAttempt.Local().Format("2006-01-02 15:04"))
+	}
+	table.Flush()
+}
+
+// runLeaderboard prints the leaderboard of one quiz
+func runLeaderboard(args []string) error {
+	flags := flag.NewFlagSet("leaderboard", flag.ExitOnError)
+	resultsPath := addResultsFlag(flags)
+	ranking := addRankingFlags(flags)
+	window := flags.String("window", "all", "only rank attempts from this far back, such as 7d or 12h")
+	group := flags.String("group", "", "only rank learners in this group")
+	limit := flags.Int("limit", 0, "only show the top entries (0 shows all)")
+	format := flags.String("format", "text", "output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz leaderboard [flags] <quiz ID>")
+		fmt.Fprintln(flags.Output(), "Ranks learners by score, then by the time they took.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := checkFormat(*format, "text", "json"); err != nil {
+		return err
+	}
+	options, err := ranking.options()
+	if err != nil {
+		return err
+	}
+	options.Group, options.Limit = *group, *limit
+	since, err := quiz_logic.ParseWindow(*window)
+	if err != nil {
+		return err
+	}
+	if since > 0 {
+		options.Since = time.Now().Add(-since)
+	}
+	if err := options.Check(); err != nil {
+		return err
+	}
+
+	results, err := loadResults(*resultsPath)
+	if err != nil {
+		return err
+	}
+	board := quiz_logic.BuildLeaderboard(results, flags.Arg(0), options)
+	if *format == "json" {
+		return printJSON(board)
+	}
+	table := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	quiz_logic.WriteLeaderboard(table, board)
+	return table.Flush()
+}
+
+// runServe serves the learner front end, and the quizzes and recorded
+// results as JSON
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	resultsPath := addResultsFlag(flags)
+	profilesPath := addProfilesFlag(flags)
+	addr := flags.String("addr", "localhost:8080", "address to listen on")
+	grpcAddr := flags.String("grpc", "", "address to also serve the gRPC API on, such as localhost:9090 (empty turns it off)")
+	reload := flags.Duration("reload", 2*time.Second, "how often to look for changed quizzes (0 turns reloading off)")
+	token := flags.String("instructor-token", "", "token instructors send as \"Authorization: Bearer <token>\" (default: $"+quiz_logic.InstructorTokenEnv+" or a new one each start)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz serve [flags]")
+		fmt.Fprintln(flags.Output(), "Serves the quizzes to take in a browser, and GET /quizzes, /quizzes/{id}, /problems and /leaderboards/{id} as JSON.")
+		fmt.Fprintln(flags.Output(), "GET /results and /dashboard, and opening live rooms, need the instructor token.")
+		fmt.Fprintln(flags.Output(), "Learners who give a profile's ID or name are recorded under it; other names are recorded without a profile.")
+		fmt.Fprintln(flags.Output(), "With -grpc it also serves the quiz.v1.QuizService gRPC API defined in quizpb/quiz.proto; ListResults and sessions for a learner_id need the instructor token there too.")
+		fmt.Fprintln(flags.Output(), "Instructors host live quizzes at /host.html; use -addr :8080 so learners on the network can join and play.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	catalog, err := source.catalog()
+	if err != nil {
+		return err
+	}
+	if *reload > 0 {
+		go catalog.Watch(context.Background(), *reload, nil)
+	}
+	path, err := resultsFile(*resultsPath)
+	if err != nil {
+		return err
+	}
+	profiles, err := profilesFile(*profilesPath)
+	if err != nil {
+		return err
+	}
+	if *token == "" {
+		*token = os.Getenv(quiz_logic.InstructorTokenEnv)
+	}
+	hostPage := localURL(*addr) + "/host.html"
+	if *token == "" {
+		if *token, err = quiz_logic.NewInstructorToken(); err != nil {
+			return err
+		}
+		fmt.Printf("Instructor token: %s\n", *token)
+		hostPage += "#token=" + *token
+	}
+
+	if *grpcAddr != "" {
+		listener, err := net.Listen("tcp", *grpcAddr)
+		if err != nil {
+			return err
+		}
+		server := grpc.NewServer(grpc.UnaryInterceptor(quiz_logic.GRPCInstructorOnly(*token)))
+		quizpb.RegisterQuizServiceServer(server, quiz_logic.NewGRPCServer(catalog, path, profiles))
+		reflection.Register(server)
+		fmt.Printf("Serving the gRPC API on %s\n", listener.Addr())
+		go func() {
+			if err := server.Serve(listener); err != nil {
+				fmt.Printf("Error serving gRPC: %v\n", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	fmt.Printf("Serving quizzes on http://%s\n", *addr)
+	fmt.Printf("Host live quizzes at %s\n", hostPage)
+	for _, url := range networkURLs(*addr) {
+		fmt.Printf("Learners on your network can join live quizzes at %s/play.html\n", url)
+	}
+	return http.ListenAndServe(*addr, quiz_logic.NewWebHandler(catalog, path, profiles, *token))
+}
+
+// localURL returns the address to open the server at on this machine
+func localURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// networkURLs returns the addresses other machines on the local network
+// can reach the server at, when it listens on every interface
+func networkURLs(addr string) []string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || (host != "" && host != "0.0.0.0" && host != "::") {
+		return nil
+	}
+	interfaces, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var urls []string
+	for _, address := range interfaces {
+		ip, ok := address.(*net.IPNet)
+		if !ok || ip.IP.IsLoopback() || ip.IP.To4() == nil {
+			continue
+		}
+		urls = append(urls, "http://"+net.JoinHostPort(ip.IP.String(), port))
+	}
+	return urls
+}
+
+// runTerminals serves the menu to learners who connect from a terminal
+// with telnet, nc or ssh, each in a session of their own
+func runTerminals(args []string) error {
+	flags := flag.NewFlagSet("terminals", flag.ExitOnError)
+	source := addQuizSourceFlags(flags)
+	resultsPath := addResultsFlag(flags)
+	profilesPath := addProfilesFlag(flags)
+	ranking := addRankingFlags(flags)
+	seed := flags.Int64("seed", 0, "seed for question selection and shuffling (0 picks one at random)")
+	tcpAddr := flags.String("tcp", "localhost:2323", "address to accept telnet and other plain TCP clients on, such as :2323 for every machine on the network (empty turns it off)")
+	sshAddr := flags.String("ssh", "localhost:2222", "address to accept ssh clients on, such as :2222 for every machine on the network (empty turns it off)")
+	hostKey := flags.String("host-key", "", "ssh host key file, created if missing (default next to the settings file)")
+	reload := flags.Duration("reload", 2*time.Second, "how often to look for changed quizzes (0 turns reloading off)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: quiz terminals [flags]")
+		fmt.Fprintln(flags.Output(), "Runs the menu for every learner who connects with telnet, nc or ssh. Learners on ssh are recorded under the profile of their ssh user name, without a password; others are asked for their name or profile ID. Only learners with a profile get in; add them with quiz profiles.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	if flags.NArg() != 0 || (*tcpAddr == "" && *sshAddr == "") {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	catalog, err := source.catalog()
+	if err != nil {
+		return err
+	}
+	if *reload > 0 {
+		go catalog.Watch(context.Background(), *reload, nil)
+	}
+	path, err := resultsFile(*resultsPath)
+	if err != nil {
+		return err
+	}
+	profiles, err := profilesFile(*profilesPath)
+	if err != nil {
+		return err
+	}
+	options, err := ranking.options()
+	if err != nil {
+		return err
+	}
+	server := &quiz_logic.TerminalServer{
+		Catalog: catalog,
+		Seed:    *seed,
+		Save: func(result quiz_logic.Result) error {
+			return quiz_logic.SaveResult(path, result)
+		},
+		Results: func() ([]quiz_logic.Result, error) {
+			return quiz_logic.LoadResults(path)
+		},
+		Ranking:  options,
+		Profiles: profiles,
+		Log:      log.New(os.Stdout, "", log.LstdFlags),
+	}
+
+	errs := make(chan error, 2)
+	if *tcpAddr != "" {
+		listener, err := net.Listen("tcp", *tcpAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Accepting telnet on %s\n", listener.Addr())
+		go func() { errs <- server.ServeTCP(listener) }()
+	}
+	if *sshAddr != "" {
+		if *hostKey == "" {
+			if *hostKey, err = quiz_logic.HostKeyPath(); err != nil {
+				return err
+			}
+		}
+		if server.HostKey, err = quiz_logic.LoadHostKey(*hostKey); err != nil {
+			return err
+		}
+		listener, err := net.Listen("tcp", *sshAddr)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Accepting ssh on %s\n", listener.Addr())
+		go func() { errs <- server.ServeSSH(listener) }()
+	}
+	return <-errs
+}
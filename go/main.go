@@ -1,49 +1,264 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 	"quiz/quiz_logic"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
 )
 
+// usage describes the commands, then the flags of the interactive menu
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintln(out, `Usage: quiz [flags]            interactive menu
+       quiz <command> [flags] [arguments]
+
+Commands:
+  list         list the quizzes that can be taken and those that cannot
+  take         take one quiz by ID or path and record the result
+  lint         check quizzes without taking them
+  results      show recorded results
+  dashboard    show each learner's attempts, best scores and passes by quiz
+  leaderboard  rank the learners who took a quiz
+  profiles     list, add or remove learner profiles
+  serve        serve quizzes to take in a browser, and quizzes and results as JSON
+  terminals    run the menu for learners connecting with telnet or ssh
+  export       convert a quiz to Moodle XML, QTI or CSV
+  export-csv   write a quiz's questions as CSV
+  import       create a quiz from GIFT, Aiken, Moodle XML, QTI or CSV
+  import-csv   create a quiz from a spreadsheet export
+  exam         render printable exam versions with answer keys
+  bundle       pack a quiz directory into a single file
+  quote        print a quote about learning, a wise one or a joke
+
+Run "quiz <command> -h" for the flags of a command.
+
+Flags of the interactive menu:`)
+	flag.PrintDefaults()
+}
+
 func main() {
-	basePath := filepath.Join("..", "quiz")
+	seed := flag.Int64("seed", 0, "seed for question selection and shuffling (0 picks one at random)")
+	source := addQuizSourceFlags(flag.CommandLine)
+	learner := flag.String("learner", "", "profile ID or name of the learner; a new name adds a profile (default: asked at startup, or the current user when input is not a terminal)")
+	profilesPath := addProfilesFlag(flag.CommandLine)
+	resultsPath := addResultsFlag(flag.CommandLine)
+	ranking := addRankingFlags(flag.CommandLine)
+	reload := flag.Duration("reload", 2*time.Second, "how often to look for changed quizzes (0 turns reloading off)")
+	ui := addUIFlag(flag.CommandLine)
+	flag.Usage = usage
 
-	quizzes, err := quiz_logic.GetAvailableQuizzes(basePath)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "exam":
+			if err := runExam(os.Args[2:]); err != nil {
+				fmt.Printf("Error generating exams: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "export":
+			if err := runExport(os.Args[2:]); err != nil {
+				fmt.Printf("Error exporting quiz: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "import-csv":
+			if err := runImportCSV(os.Args[2:]); err != nil {
+				fmt.Printf("Error importing CSV: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "export-csv":
+			if err := runExportCSV(os.Args[2:]); err != nil {
+				fmt.Printf("Error exporting CSV: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "import":
+			if err := runImport(os.Args[2:]); err != nil {
+				fmt.Printf("Error importing quiz: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "bundle":
+			if err := runBundle(os.Args[2:]); err != nil {
+				fmt.Printf("Error bundling quiz: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "list":
+			if err := runList(os.Args[2:]); err != nil {
+				fmt.Printf("Error listing quizzes: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "take":
+			if err := runTake(os.Args[2:]); err != nil {
+				fmt.Printf("Error running quiz: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "lint":
+			if err := runLint(os.Args[2:]); err != nil {
+				fmt.Printf("Error checking quizzes: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "results":
+			if err := runResults(os.Args[2:]); err != nil {
+				fmt.Printf("Error reading results: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "dashboard":
+			if err := runDashboard(os.Args[2:]); err != nil {
+				fmt.Printf("Error reading results: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "leaderboard":
+			if err := runLeaderboard(os.Args[2:]); err != nil {
+				fmt.Printf("Error reading results: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "profiles":
+			if err := runProfiles(os.Args[2:]); err != nil {
+				fmt.Printf("Error managing profiles: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "serve":
+			if err := runServe(os.Args[2:]); err != nil {
+				fmt.Printf("Error serving quizzes: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "terminals":
+			if err := runTerminals(os.Args[2:]); err != nil {
+				fmt.Printf("Error serving terminals: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "quote":
+			if err := runQuote(os.Args[2:]); err != nil {
+				fmt.Printf("Error picking a quote: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		case "help":
+			usage()
+			return
+		default:
+			if !strings.HasPrefix(os.Args[1], "-") {
+				fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", os.Args[1])
+				usage()
+				os.Exit(2)
+			}
+		}
+	}
+
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments %q; commands go before their flags\n\n", flag.Args())
+		usage()
+		os.Exit(2)
+	}
 
+	catalog, err := source.catalog()
 	if err != nil {
 		fmt.Printf("Error loading quizzes: %v\n", err)
 		os.Exit(1)
 	}
+	if *reload > 0 {
+		go catalog.Watch(context.Background(), *reload, nil)
+	}
 
-	quoter := quiz_logic.NewQuoter()
+	useTUI, err := chooseUI(*ui)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	options, err := ranking.options()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(2)
+	}
+	in := bufio.NewReader(os.Stdin)
+	profile, err := startupProfile(in, *profilesPath, *learner)
+	if err != nil {
+		fmt.Printf("Error choosing a profile: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Taking quizzes as %s.\n", profile)
+	if useTUI {
+		err := runTUIMenu(catalog, *seed, profile, *resultsPath)
+		if err == nil {
+			return
+		}
+		fmt.Printf("%v; using line prompts instead.\n", err)
+	}
+
+	menu := &quiz_logic.Menu{
+		Catalog: catalog,
+		In:      in,
+		Out:     os.Stdout,
+		Seed:    *seed,
+		Profile: profile,
+		Save: func(result quiz_logic.Result) error {
+			return saveResult(*resultsPath, result)
+		},
+		Results: func() ([]quiz_logic.Result, error) {
+			return loadResults(*resultsPath)
+		},
+		Ranking: options,
+	}
+	menu.Run()
+}
+
+// startupProfile returns the profile the -learner flag names. Without one,
+// a learner at a terminal is asked who they are; otherwise the current
+// user's profile is used.
+func startupProfile(in *bufio.Reader, profilesPath, learner string) (quiz_logic.Profile, error) {
+	if learner != "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return openProfile(profilesPath, learner)
+	}
+	path, err := profilesFile(profilesPath)
+	if err != nil {
+		return quiz_logic.Profile{}, err
+	}
+	return quiz_logic.ChooseProfile(in, os.Stdout, path, currentLearner())
+}
+
+// runTUIMenu lets the learner pick and take quizzes in the full-screen
+// interface until they quit
+func runTUIMenu(catalog *quiz_logic.Catalog, seed int64, profile quiz_logic.Profile, resultsPath string) error {
+	tui, err := quiz_logic.OpenTUI()
+	if err != nil {
+		return err
+	}
+	defer tui.Close()
 
 	for {
-		quiz_logic.ShowMenu()
-		var choice string
-		fmt.Scanln(&choice)
-
-		switch choice {
-		case "1":
-			quiz_logic.ListQuizzes(quizzes)
-		case "2":
-			if selectedQuiz := quiz_logic.PromptForQuiz(quizzes); selectedQuiz != nil {
-				if err := quiz_logic.StartQuiz(selectedQuiz.Path); err != nil {
-					fmt.Printf("Error running quiz: %v\n", err)
-				}
-			}
-		case "3":
-			fmt.Println("Goodbye!")
-			return
-		case "42":
-			fmt.Println(quoter.GetLifeQuote())
-		case "1337":
-			fmt.Println(quoter.GetPasswordQuote())
-		case "1234":
-			fmt.Println(quoter.GetWisdomQuote())
-		default:
-			fmt.Println("Invalid choice. Please try again.")
+		info, ok := tui.ChooseQuiz(catalog.Quizzes)
+		if !ok {
+			return nil
+		}
+		quiz, err := info.Load(seed)
+		if err != nil {
+			tui.ShowError(err)
+			continue
+		}
+		quiz.SetProfile(profile)
+		if err := saveResult(resultsPath, tui.RunQuiz(quiz)); err != nil {
+			tui.ShowError(err)
 		}
 	}
 }
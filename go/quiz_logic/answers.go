@@ -0,0 +1,79 @@
+package quiz_logic
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IsAnswerFile reports whether answerPath is an answer file keyed by
+// question ID rather than a stream of answers, one per line
+func IsAnswerFile(answerPath string) bool {
+	return isQuizFile(answerPath)
+}
+
+// LoadAnswers reads a JSON, YAML or TOML answer file that maps question IDs
+// to answers, for Quiz.Answers. Answers are written as they would be typed:
+// an option number or text, true or false, or the word for a blank. A
+// question left out, or given null, is skipped.
+func LoadAnswers(answerPath string) (map[string]string, error) {
+	data, err := os.ReadFile(answerPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading answers: %v", err)
+	}
+
+	var values map[string]interface{}
+	if err := decodeQuizFile(answerPath, data, &values); err != nil {
+		return nil, fmt.Errorf("error parsing answers: %v", err)
+	}
+
+	// Sorted so the first bad answer reported does not change between runs
+	ids := make([]string, 0, len(values))
+	for id := range values {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	answers := make(map[string]string)
+	for _, id := range ids {
+		switch value := values[id].(type) {
+		case nil:
+		case string:
+			answers[id] = value
+		case float64:
+			answers[id] = strconv.FormatFloat(value, 'f', -1, 64)
+		case bool:
+			answers[id] = strconv.FormatBool(value)
+		default:
+			message := fmt.Sprintf("answer to %s must be text, a number or true/false", id)
+			if line := keyLine(data, id); line > 0 {
+				return nil, fmt.Errorf("error parsing answers: line %d: %s", line, message)
+			}
+			return nil, fmt.Errorf("error parsing answers: %s", message)
+		}
+	}
+	return answers, nil
+}
+
+// CheckAnswers rejects answers in Answers for questions that are not on
+// the quiz's form, such as misspelt IDs or alternatives this seed left out,
+// which would otherwise be ignored without a word
+func (q *Quiz) CheckAnswers() error {
+	asked := make(map[string]bool)
+	for _, question := range q.Questions {
+		asked[question.getID()] = true
+	}
+	var unknown []string
+	for id := range q.Answers {
+		if !asked[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("answers given for questions not in this quiz: %s", strings.Join(unknown, ", "))
+}
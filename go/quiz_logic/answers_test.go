@@ -0,0 +1,112 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadAnswers(t *testing.T) {
+	dir := writeQuizFiles(t, map[string]string{
+		"answers.json": `{"capital": "Paris", "sqrt": 12, "earth": true, "skipped": null}`,
+		"answers.yaml": "capital: Paris\nsqrt: 12\nearth: true\nskipped:\n",
+		"answers.toml": "capital = \"Paris\"\nsqrt = 12\nearth = true\n",
+		"bad.yaml":     "capital: Paris\nsqrt: [12]\n",
+	})
+	want := map[string]string{"capital": "Paris", "sqrt": "12", "earth": "true"}
+
+	for _, name := range []string{"answers.json", "answers.yaml", "answers.toml"} {
+		answers, err := LoadAnswers(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatalf("LoadAnswers(%s) error = %v", name, err)
+		}
+		if !reflect.DeepEqual(answers, want) {
+			t.Errorf("LoadAnswers(%s) = %v, want %v", name, answers, want)
+		}
+	}
+
+	_, err := LoadAnswers(filepath.Join(dir, "bad.yaml"))
+	if err == nil || !strings.Contains(err.Error(), "line 2: answer to sqrt") {
+		t.Errorf("LoadAnswers(bad.yaml) error = %v, want one naming line 2", err)
+	}
+}
+
+func TestQuiz_RunScripted(t *testing.T) {
+	questions := []Question{
+		&MultipleChoiceQuestion{
+			BaseQuestion: BaseQuestion{ID: "capital", QuestionText: "Capital of France?", Answers: []string{"Paris"}},
+			Options:      []string{"London", "Paris"},
+		},
+		&FillInBlankQuestion{BaseQuestion: BaseQuestion{ID: "water", QuestionText: "Water turns to gas by ___", Answers: []string{"boiling point"}}},
+		&TrueFalseQuestion{BaseQuestion: BaseQuestion{ID: "earth", QuestionText: "The Earth is flat.", Answers: []string{"false"}}},
+	}
+
+	tests := []struct {
+		name        string
+		answers     map[string]string
+		in          string
+		wantCorrect int
+	}{
+		{"Answer file", map[string]string{"capital": "2", "water": "Boiling point", "earth": "true"}, "", 2},
+		{"Answer file missing an answer", map[string]string{"capital": "Paris"}, "", 1},
+		{"Answer lines", nil, "Paris\nboiling point\n2\n", 3},
+		{"Answer lines ending early", nil, "1\n", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			quiz := &Quiz{
+				ID:        "scripted",
+				Config:    Config{Title: "Scripted"},
+				Questions: questions,
+				Answers:   tt.answers,
+				Out:       &out,
+			}
+			quiz.Config.Settings.AllowSkipping = true
+			if tt.answers == nil {
+				quiz.In = strings.NewReader(tt.in)
+			}
+
+			result := quiz.Run()
+			if result.Correct != tt.wantCorrect || result.Total != len(questions) {
+				t.Errorf("Run() = %d/%d correct, want %d/%d", result.Correct, result.Total, tt.wantCorrect, len(questions))
+			}
+			if len(result.Questions) != len(questions) {
+				t.Fatalf("Expected every question in the result, got %+v", result.Questions)
+			}
+			for _, question := range result.Questions {
+				if question.Correct != (question.Points == question.MaxPoints) || question.Skipped != (question.Answer == "") {
+					t.Errorf("Inconsistent question result %+v", question)
+				}
+			}
+			if !strings.Contains(out.String(), "Starting Quiz: Scripted") {
+				t.Errorf("Expected the quiz to be shown on Out, got %q", out.String())
+			}
+		})
+	}
+}
+
+func TestQuiz_CheckAnswers(t *testing.T) {
+	quiz := &Quiz{Questions: []Question{
+		&TrueFalseQuestion{BaseQuestion: BaseQuestion{ID: "capital", QuestionText: "Paris is the capital of France.", Answers: []string{"true"}}},
+		&TrueFalseQuestion{BaseQuestion: BaseQuestion{ID: "earth", QuestionText: "The Earth is flat.", Answers: []string{"false"}}},
+		&FillInBlankQuestion{BaseQuestion: BaseQuestion{ID: "water", QuestionText: "Water boils at ___ degrees", Answers: []string{"100"}}},
+	}}
+	if err := quiz.CheckAnswers(); err != nil {
+		t.Errorf("CheckAnswers() without answers = %v", err)
+	}
+
+	quiz.Answers = map[string]string{"capital": "Paris", "earth": "false"}
+	if err := quiz.CheckAnswers(); err != nil {
+		t.Errorf("CheckAnswers() = %v, want nil", err)
+	}
+
+	quiz.Answers = map[string]string{"capital": "Paris", "watr": "100", "moon": "true"}
+	err := quiz.CheckAnswers()
+	if err == nil || !strings.HasSuffix(err.Error(), "not in this quiz: moon, watr") {
+		t.Errorf("CheckAnswers() = %v, want moon and watr reported", err)
+	}
+}
@@ -0,0 +1,147 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleAPI adds to mux a read-only JSON view of the catalog and of the
+// results recorded at resultsPath:
+//
+//	GET /quizzes            quizzes, filtered by ?category=, ?q= and ?maxDuration=
+//	GET /quizzes/{id}       one quiz by ID
+//	GET /problems           quizzes that cannot be taken
+//	GET /results            results, filtered by ?quiz= and ?learner=
+//	GET /dashboard          how each learner did at each quiz, see Standings,
+//	                        filtered by ?quiz=, ?learner= and ?group=
+//	GET /leaderboards/{id}  a quiz's leaderboard, see LeaderboardOptions, for
+//	                        ?window= (such as 7d), ?group=, ?attempts=best or
+//	                        latest, ?ties=shared, dense or first, and ?limit=
+//
+// Results hold every learner's answers and the answer keys, so /results and
+// /dashboard go through instructor.
+func handleAPI(mux *http.ServeMux, catalog *Catalog, resultsPath string, instructor func(http.HandlerFunc) http.HandlerFunc) {
+	mux.HandleFunc("GET /quizzes", func(w http.ResponseWriter, r *http.Request) {
+		quizzes, _ := catalog.Quizzes()
+		filter := QuizFilter{
+			Category: r.URL.Query().Get("category"),
+			Keyword:  r.URL.Query().Get("q"),
+		}
+		if value := r.URL.Query().Get("maxDuration"); value != "" {
+			minutes, err := strconv.Atoi(value)
+			if err != nil || minutes < 0 {
+				writeAPIError(w, http.StatusBadRequest, "maxDuration must be a number of minutes")
+				return
+			}
+			filter.MaxDuration = minutes
+		}
+		writeJSON(w, http.StatusOK, nonNil(FilterQuizzes(quizzes, filter)))
+	})
+
+	mux.HandleFunc("GET /quizzes/{id...}", func(w http.ResponseWriter, r *http.Request) {
+		quizzes, _ := catalog.Quizzes()
+		for _, quiz := range quizzes {
+			if strings.EqualFold(quiz.ID, r.PathValue("id")) {
+				writeJSON(w, http.StatusOK, quiz)
+				return
+			}
+		}
+		writeAPIError(w, http.StatusNotFound, "no quiz with ID "+r.PathValue("id"))
+	})
+
+	mux.HandleFunc("GET /problems", func(w http.ResponseWriter, r *http.Request) {
+		_, problems := catalog.Quizzes()
+		writeJSON(w, http.StatusOK, nonNil(problems))
+	})
+
+	mux.HandleFunc("GET /results", instructor(func(w http.ResponseWriter, r *http.Request) {
+		results, err := LoadResults(resultsPath)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		results = FilterResults(results, r.URL.Query().Get("quiz"), r.URL.Query().Get("learner"))
+		writeJSON(w, http.StatusOK, nonNil(results))
+	}))
+
+	mux.HandleFunc("GET /dashboard", instructor(func(w http.ResponseWriter, r *http.Request) {
+		results, err := LoadResults(resultsPath)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		standings := Standings(FilterResults(results, r.URL.Query().Get("quiz"), ""))
+		standings = FilterStandings(standings, r.URL.Query().Get("learner"), r.URL.Query().Get("group"))
+		writeJSON(w, http.StatusOK, nonNil(standings))
+	}))
+
+	mux.HandleFunc("GET /leaderboards/{id...}", func(w http.ResponseWriter, r *http.Request) {
+		options, err := leaderboardOptions(r.URL.Query())
+		if err != nil {
+			writeAPIError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		results, err := LoadResults(resultsPath)
+		if err != nil {
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		id := r.PathValue("id")
+		board := BuildLeaderboard(results, id, options)
+		quizzes, _ := catalog.Quizzes()
+		if quiz, ok := FindQuiz(quizzes, id); ok && strings.EqualFold(quiz.ID, id) {
+			board.QuizID, board.Title = quiz.ID, quiz.Title
+		} else if len(FilterResults(results, id, "")) == 0 {
+			writeAPIError(w, http.StatusNotFound, "no quiz with ID "+id)
+			return
+		}
+		writeJSON(w, http.StatusOK, board)
+	})
+}
+
+// leaderboardOptions reads the query of GET /leaderboards/{id}
+func leaderboardOptions(query url.Values) (LeaderboardOptions, error) {
+	options := LeaderboardOptions{
+		Group:    query.Get("group"),
+		Attempts: AttemptRule(query.Get("attempts")),
+		Ties:     TieRule(query.Get("ties")),
+	}
+	window, err := ParseWindow(query.Get("window"))
+	if err != nil {
+		return options, err
+	}
+	if window > 0 {
+		options.Since = time.Now().Add(-window)
+	}
+	if value := query.Get("limit"); value != "" {
+		if options.Limit, err = strconv.Atoi(value); err != nil {
+			return options, errors.New("limit must be a number of entries")
+		}
+	}
+	return options, options.Check()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
+
+func writeAPIError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// nonNil makes empty lists encode as [] rather than null
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
@@ -0,0 +1,82 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWebHandler_API(t *testing.T) {
+	root := t.TempDir()
+	writeQuizTree(t, root, "basics", "science/physics")
+	if err := os.MkdirAll(filepath.Join(root, "broken"), 0755); err != nil {
+		t.Fatalf("Failed to create broken: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "broken", "config.json"), []byte(`{"title": `), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	resultsPath := filepath.Join(t.TempDir(), "results.jsonl")
+	for _, result := range []Result{{QuizID: "basics", Learner: "ada"}, {QuizID: "science/physics", Learner: "grace", Group: "7b"}, {QuizID: "basics", Learner: "ada"}} {
+		if err := SaveResult(resultsPath, result); err != nil {
+			t.Fatalf("SaveResult() error = %v", err)
+		}
+	}
+
+	catalog, err := NewCatalog(Discovery{Roots: []string{root}}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	handler := NewWebHandler(catalog, resultsPath, "", testInstructorToken)
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		wantStatus int
+		wantCount  int // length of the returned list, -1 for an object
+	}{
+		{"All quizzes", "GET", "/quizzes", http.StatusOK, 2},
+		{"By category", "GET", "/quizzes?category=science", http.StatusOK, 1},
+		{"No match", "GET", "/quizzes?q=nothing", http.StatusOK, 0},
+		{"Bad duration", "GET", "/quizzes?maxDuration=soon", http.StatusBadRequest, -1},
+		{"Quiz by ID", "GET", "/quizzes/Science/Physics", http.StatusOK, -1},
+		{"Unknown quiz", "GET", "/quizzes/history", http.StatusNotFound, -1},
+		{"Problems", "GET", "/problems", http.StatusOK, 1},
+		{"All results", "GET", "/results", http.StatusOK, 3},
+		{"Results by learner", "GET", "/results?learner=ada", http.StatusOK, 2},
+		{"Dashboard", "GET", "/dashboard", http.StatusOK, 2},
+		{"Dashboard by group", "GET", "/dashboard?group=7B", http.StatusOK, 1},
+		{"Leaderboard", "GET", "/leaderboards/science/physics?window=30d&ties=dense", http.StatusOK, -1},
+		{"Leaderboard of an unknown quiz", "GET", "/leaderboards/history", http.StatusNotFound, -1},
+		{"Leaderboard with a bad rule", "GET", "/leaderboards/basics?attempts=worst", http.StatusBadRequest, -1},
+		{"Leaderboard with a bad window", "GET", "/leaderboards/basics?window=soon", http.StatusBadRequest, -1},
+		{"Read only", "POST", "/quizzes", http.StatusMethodNotAllowed, -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, tt.target, nil)
+			request.Header.Set("Authorization", "Bearer "+testInstructorToken)
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d", tt.method, tt.target, recorder.Code, tt.wantStatus)
+			}
+			if tt.wantCount < 0 {
+				return
+			}
+			var list []map[string]interface{}
+			if err := json.Unmarshal(recorder.Body.Bytes(), &list); err != nil {
+				t.Fatalf("Expected a JSON list, got %s", recorder.Body)
+			}
+			if len(list) != tt.wantCount {
+				t.Errorf("%s returned %d items, want %d: %s", tt.target, len(list), tt.wantCount, recorder.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package quiz_logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BundleExt marks a quiz distributed as a single zip archive holding its
+// config and question files
+const BundleExt = ".quiz.zip"
+
+// isBundle reports whether quizPath names a quiz bundle
+func isBundle(quizPath string) bool {
+	return strings.HasSuffix(strings.ToLower(quizPath), BundleExt)
+}
+
+// osQuiz splits an OS path to a quiz into a file system and the quiz's
+// name within it. The path is made absolute first, so that "." and ".."
+// name a folder within the one above them.
+func osQuiz(quizPath string) (fs.FS, string) {
+	if abs, err := filepath.Abs(quizPath); err == nil {
+		quizPath = abs
+	}
+	quizPath = filepath.Clean(quizPath)
+	return os.DirFS(filepath.Dir(quizPath)), filepath.Base(quizPath)
+}
+
+// openQuiz returns the file system holding the quiz called name in fsys
+// and the quiz's name within it. Directories and Markdown files are used
+// in place; a bundle becomes a file system of its own.
+func openQuiz(fsys fs.FS, name string) (fs.FS, string, error) {
+	if !isBundle(name) {
+		return fsys, name, nil
+	}
+
+	// Bundles are small, so read them whole rather than keep the file open
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading quiz bundle: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, "", fmt.Errorf("error reading quiz bundle %s: %v", path.Base(name), err)
+	}
+	return archive, bundleRoot(archive), nil
+}
+
+// bundleRoot finds the quiz inside a bundle: the top of the archive, or the
+// single folder in it when a quiz directory was zipped as a whole
+func bundleRoot(fsys fs.FS) string {
+	if _, err := findConfigFile(fsys, "."); err == nil {
+		return "."
+	}
+
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return "."
+	}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && entry.Name() != "__MACOSX" {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	if len(dirs) == 1 {
+		return dirs[0]
+	}
+	return "."
+}
+
+// WriteBundle packs the quiz directory at quizPath into a bundle written to
+// w. The quiz is loaded first so a broken quiz is never packed; config and
+// question files are stored as they are, in their own format.
+func WriteBundle(w io.Writer, quizPath string) error {
+	if isMarkdownQuiz(quizPath) || isBundle(quizPath) {
+		return fmt.Errorf("%s is not a quiz directory", quizPath)
+	}
+	if _, err := LoadConfig(quizPath); err != nil {
+		return fmt.Errorf("error loading config: %v", err)
+	}
+	if _, err := loadQuestionBank(quizPath); err != nil {
+		return fmt.Errorf("error loading questions: %v", err)
+	}
+
+	entries, err := os.ReadDir(quizPath)
+	if err != nil {
+		return fmt.Errorf("error reading quiz directory: %v", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() && isQuizFile(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	archive := zip.NewWriter(w)
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(quizPath, name))
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", name, err)
+		}
+		f, err := archive.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(data); err != nil {
+			return err
+		}
+	}
+	return archive.Close()
+}
@@ -0,0 +1,172 @@
+package quiz_logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeZip creates a zip archive holding the given files
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("Failed to close archive: %v", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func TestWriteBundle(t *testing.T) {
+	quizPath := filepath.Join("../../quiz", "quiz01")
+	bundlePath := filepath.Join(t.TempDir(), "quiz01"+BundleExt)
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, quizPath); err != nil {
+		t.Fatalf("WriteBundle() error = %v", err)
+	}
+	if err := os.WriteFile(bundlePath, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("Failed to write bundle: %v", err)
+	}
+
+	want, err := LoadConfig(quizPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	got, err := LoadConfig(bundlePath)
+	if err != nil {
+		t.Fatalf("LoadConfig() bundle error = %v", err)
+	}
+	if got.Title != want.Title || len(got.Questions) != len(want.Questions) {
+		t.Errorf("Bundle config = %+v, want %+v", got, want)
+	}
+
+	_, wantQuestions, err := LoadQuizFiles(quizPath)
+	if err != nil {
+		t.Fatalf("LoadQuizFiles() error = %v", err)
+	}
+	_, gotQuestions, err := LoadQuizFiles(bundlePath)
+	if err != nil {
+		t.Fatalf("LoadQuizFiles() bundle error = %v", err)
+	}
+	sameQuestions(t, wantQuestions, gotQuestions)
+
+	// A bundle is imported as it is, not taken for a QTI package
+	imported, err := ImportFile(bundlePath, "")
+	if err != nil {
+		t.Fatalf("ImportFile() bundle error = %v", err)
+	}
+	if imported.Config.Title != want.Title || len(imported.Problems) != 0 {
+		t.Errorf("Imported bundle = %+v, want %q without problems", imported.Config, want.Title)
+	}
+	sameQuestions(t, wantQuestions, imported.Questions)
+
+	quiz := &Quiz{Config: got, Seed: 1}
+	if err := quiz.selectQuestions(bundlePath); err != nil {
+		t.Fatalf("selectQuestions() error = %v", err)
+	}
+	if len(quiz.Questions) != len(got.Questions) {
+		t.Errorf("Expected %d questions, got %d", len(got.Questions), len(quiz.Questions))
+	}
+}
+
+func TestWriteBundle_BrokenQuiz(t *testing.T) {
+	dir := writeQuizFiles(t, map[string]string{
+		"config.json": `{"title": "Broken", "questions": [["q1"]]}`,
+		"q1.json":     `{"question": "x", "type": "true_false", "answers": ["maybe"]}`,
+	})
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, dir); err == nil {
+		t.Error("Expected WriteBundle() to refuse a broken quiz")
+	}
+}
+
+func TestLoadConfig_Bundle(t *testing.T) {
+	dir := t.TempDir()
+	question := `{"question": "Go is compiled.", "type": "true_false", "answers": ["true"]}`
+
+	// A zipped folder keeps the folder name inside the archive
+	writeZip(t, filepath.Join(dir, "quiz01"+BundleExt), map[string]string{
+		"quiz01/config.yaml": "title: Zipped Folder\nquestions: [[q1]]\n",
+		"quiz01/q1.json":     question,
+	})
+	writeZip(t, filepath.Join(dir, "quiz02"+BundleExt), map[string]string{
+		"q1.json": question,
+	})
+
+	config, err := LoadConfig(filepath.Join(dir, "quiz01"+BundleExt))
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Title != "Zipped Folder" {
+		t.Errorf("Expected title Zipped Folder, got %q", config.Title)
+	}
+	if _, err := loadQuestionBank(filepath.Join(dir, "quiz01"+BundleExt)); err != nil {
+		t.Errorf("loadQuestionBank() error = %v", err)
+	}
+
+	_, err = LoadConfig(filepath.Join(dir, "quiz02"+BundleExt))
+	if err == nil || !strings.Contains(err.Error(), "no config.json") {
+		t.Errorf("Expected a missing config error, got %v", err)
+	}
+}
+
+func TestLoadConfig_RelativePath(t *testing.T) {
+	quizDir := filepath.Join(t.TempDir(), "quiz01")
+	if err := os.MkdirAll(filepath.Join(quizDir, "images"), 0755); err != nil {
+		t.Fatalf("Failed to create the quiz: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(quizDir, "config.json"), []byte(`{"title": "Relative", "questions": []}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Getwd() error = %v", err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+	if err := os.Chdir(filepath.Join(quizDir, "images")); err != nil {
+		t.Fatalf("Chdir() error = %v", err)
+	}
+	for _, path := range []string{"..", "../", filepath.Join("..", "..", "quiz01")} {
+		config, err := LoadConfig(path)
+		if err != nil || config.Title != "Relative" {
+			t.Errorf("LoadConfig(%q) = %v, %v", path, config.Title, err)
+		}
+	}
+	if err := os.Chdir(quizDir); err != nil {
+		t.Fatalf("Chdir() error = %v", err)
+	}
+	if config, err := LoadConfig("."); err != nil || config.Title != "Relative" {
+		t.Errorf("LoadConfig(.) = %v, %v", config.Title, err)
+	}
+}
+
+func TestGetAvailableQuizzes_Bundle(t *testing.T) {
+	dir := t.TempDir()
+	writeZip(t, filepath.Join(dir, "quiz01"+BundleExt), map[string]string{
+		"config.json": `{"title": "Bundled Quiz", "questions": [["q1"]]}`,
+		"q1.json":     `{"question": "Go is compiled.", "type": "true_false", "answers": ["true"]}`,
+	})
+
+	quizzes, _, err := GetAvailableQuizzes(dir)
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzes() error = %v", err)
+	}
+	if len(quizzes) != 1 || quizzes[0].Title != "Bundled Quiz" {
+		t.Errorf("Expected the bundle to be discovered, got %+v", quizzes)
+	}
+}
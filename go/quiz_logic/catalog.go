@@ -0,0 +1,146 @@
+package quiz_logic
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Catalog keeps the list of quizzes up to date while the program runs.
+// When an edit breaks a quiz that loaded before, for instance a file saved
+// halfway, the last version that loaded stays available until it is fixed.
+type Catalog struct {
+	discovery Discovery
+	fsys      fs.FS // searched instead of the roots when set
+
+	mu       sync.RWMutex
+	quizzes  []QuizInfo
+	problems []QuizProblem
+	stamp    uint64 // fingerprint of the files behind the list
+}
+
+// NewCatalog discovers the quizzes in the roots of discovery, or in fsys
+// when it is not nil. Embedded file systems never change, so a catalog of
+// fsys is never reloaded.
+func NewCatalog(discovery Discovery, fsys fs.FS) (*Catalog, error) {
+	c := &Catalog{discovery: discovery, fsys: fsys}
+	stamp, err := c.fingerprint()
+	if err != nil {
+		return nil, err
+	}
+	quizzes, problems, err := c.find()
+	if err != nil {
+		return nil, err
+	}
+
+	c.quizzes, c.problems, c.stamp = quizzes, problems, stamp
+	return c, nil
+}
+
+// Quizzes returns the current quiz list and the quizzes that cannot be taken
+func (c *Catalog) Quizzes() ([]QuizInfo, []QuizProblem) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.quizzes, c.problems
+}
+
+// Reload rediscovers the quizzes if any file below the roots was added,
+// removed or changed, and reports whether it did. On error the current
+// list is kept.
+func (c *Catalog) Reload() (bool, error) {
+	if c.fsys != nil {
+		return false, nil
+	}
+
+	stamp, err := c.fingerprint()
+	if err != nil {
+		return false, err
+	}
+	c.mu.RLock()
+	unchanged := stamp == c.stamp
+	c.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	quizzes, problems, err := c.find()
+	if err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Keep offering the last good version of quizzes that broke
+	previous := make(map[string]QuizInfo)
+	for _, quiz := range c.quizzes {
+		previous[quiz.Path] = quiz
+	}
+	for i, problem := range problems {
+		if last, ok := previous[problem.Path]; ok {
+			problems[i].Err = fmt.Errorf("%v (the last version that loaded is still offered)", problem.Err)
+			quizzes = append(quizzes, last)
+		}
+	}
+	quizzes, problems = claimIDs(quizzes, problems)
+
+	c.quizzes, c.problems, c.stamp = quizzes, problems, stamp
+	return true, nil
+}
+
+// Watch reloads the catalog every interval until ctx is done. onReload,
+// if not nil, is called after every reload that changed the list or failed.
+func (c *Catalog) Watch(ctx context.Context, interval time.Duration, onReload func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := c.Reload()
+			if (changed || err != nil) && onReload != nil {
+				onReload(err)
+			}
+		}
+	}
+}
+
+func (c *Catalog) find() ([]QuizInfo, []QuizProblem, error) {
+	if c.fsys != nil {
+		return c.discovery.FindFS(c.fsys)
+	}
+	return c.discovery.Find()
+}
+
+// fingerprint hashes the name, size and modification time of every file
+// below the roots, which is cheap enough to poll
+func (c *Catalog) fingerprint() (uint64, error) {
+	hash := fnv.New64a()
+	if c.fsys != nil {
+		return hash.Sum64(), nil
+	}
+
+	for _, root := range c.discovery.Roots {
+		err := filepath.WalkDir(root, func(name string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			info, err := entry.Info()
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00%d\x00%d\x00", name, info.Size(), info.ModTime().UnixNano())
+			return nil
+		})
+		if err != nil {
+			return 0, fmt.Errorf("quiz root %s: %v", root, err)
+		}
+	}
+	return hash.Sum64(), nil
+}
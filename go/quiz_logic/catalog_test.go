@@ -0,0 +1,95 @@
+package quiz_logic
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCatalog_Reload(t *testing.T) {
+	root := t.TempDir()
+	writeQuizTree(t, root, "quiz01")
+
+	catalog, err := NewCatalog(Discovery{Roots: []string{root}}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+
+	if changed, err := catalog.Reload(); changed || err != nil {
+		t.Errorf("Reload() without changes = %v, %v", changed, err)
+	}
+
+	// An added quiz shows up
+	writeQuizTree(t, root, "quiz02")
+	if changed, err := catalog.Reload(); !changed || err != nil {
+		t.Fatalf("Reload() after adding a quiz = %v, %v", changed, err)
+	}
+	quizzes, _ := catalog.Quizzes()
+	if got := quizTitles(quizzes); !reflect.DeepEqual(got, []string{"quiz01", "quiz02"}) {
+		t.Errorf("Quizzes() = %v, want quiz01 and quiz02", got)
+	}
+
+	// A half-saved config keeps the last good version on offer
+	if err := os.WriteFile(filepath.Join(root, "quiz01", "config.json"), []byte(`{"title": "quiz01", "quest`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if changed, err := catalog.Reload(); !changed || err != nil {
+		t.Fatalf("Reload() after breaking a quiz = %v, %v", changed, err)
+	}
+	quizzes, problems := catalog.Quizzes()
+	if got := quizTitles(quizzes); !reflect.DeepEqual(got, []string{"quiz02", "quiz01"}) {
+		t.Errorf("Quizzes() = %v, want quiz02 and the last good quiz01", got)
+	}
+	if len(problems) != 1 || !strings.Contains(problems[0].Error(), "still offered") {
+		t.Errorf("Expected a problem for the broken quiz01, got %v", problems)
+	}
+	if quizzes[1].bank == nil {
+		t.Error("Expected the last good quiz01 to keep its questions")
+	}
+
+	// A removed quiz disappears
+	if err := os.RemoveAll(filepath.Join(root, "quiz02")); err != nil {
+		t.Fatalf("Failed to remove quiz02: %v", err)
+	}
+	if changed, err := catalog.Reload(); !changed || err != nil {
+		t.Fatalf("Reload() after removing a quiz = %v, %v", changed, err)
+	}
+	quizzes, _ = catalog.Quizzes()
+	if got := quizTitles(quizzes); !reflect.DeepEqual(got, []string{"quiz01"}) {
+		t.Errorf("Quizzes() = %v, want only the last good quiz01", got)
+	}
+}
+
+func TestCatalog_Watch(t *testing.T) {
+	root := t.TempDir()
+	catalog, err := NewCatalog(Discovery{Roots: []string{root}}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var reloads int32
+	go catalog.Watch(ctx, 10*time.Millisecond, func(err error) {
+		if err == nil {
+			atomic.AddInt32(&reloads, 1)
+		}
+	})
+
+	writeQuizTree(t, root, "quiz01")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if quizzes, _ := catalog.Quizzes(); len(quizzes) == 1 && atomic.LoadInt32(&reloads) > 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Watch() did not notice the new quiz")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
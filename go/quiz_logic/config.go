@@ -2,14 +2,25 @@ package quiz_logic
 
 // Config represents the quiz configuration
 type Config struct {
+	ID             string     `json:"id,omitempty"` // stable identifier, default: the quiz's path below its root
 	Title          string     `json:"title"`
 	TimeLimit      int        `json:"timeLimit"`      // in minutes
 	RandomizeOrder bool       `json:"randomizeOrder"` // randomize question order
 	PassingScore   int        `json:"passingScore"`   // percentage needed to pass
 	Questions      [][]string `json:"questions"`      // list of question sets
-	Settings       struct {
+	Seed           int64      `json:"seed"`           // fixed seed for reproducible forms, 0 for random
+
+	// Catalog details shown in the menu
+	Description       string   `json:"description,omitempty"`
+	Category          string   `json:"category,omitempty"`          // such as "science/physics", default: the folder the quiz is in
+	Difficulty        string   `json:"difficulty,omitempty"`        // such as easy, medium or hard
+	EstimatedDuration int      `json:"estimatedDuration,omitempty"` // in minutes, default: the time limit
+	Tags              []string `json:"tags,omitempty"`
+
+	Settings struct {
 		ShowFeedbackAfterEach bool `json:"showFeedbackAfterEach"`
 		AllowSkipping         bool `json:"allowSkipping"`
 		ShowTimer             bool `json:"showTimer"`
+		ShuffleOptions        bool `json:"shuffleOptions"` // shuffle multiple choice options at display time
 	} `json:"settings"`
 }
@@ -0,0 +1,146 @@
+package quiz_logic
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvListSeparator separates options, answers and tags within a cell
+const csvListSeparator = "|"
+
+// CSVColumns is the column order written by ExportCSV. ImportCSV matches
+// columns by header name, so they may come in any order.
+var CSVColumns = []string{"id", "type", "question", "options", "answers", "tags", "points"}
+
+// ImportCSV converts a CSV with one row per question. The first row must
+// name the columns; type, question and answers are required. Every row is
+// checked with the same rules as question files.
+func ImportCSV(r io.Reader) (*ImportResult, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading CSV header: %v", err)
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"type", "question", "answers"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	result := &ImportResult{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading CSV: %v", err)
+		}
+		line, _ := reader.FieldPos(0)
+		source := fmt.Sprintf("row %d", line)
+
+		cell := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if strings.Join(record, "") == "" {
+			continue // blank rows are common at the end of spreadsheets
+		}
+
+		question := QuestionFile{
+			ID:       cell("id"),
+			Type:     cell("type"),
+			Question: cell("question"),
+			Options:  splitCSVList(cell("options")),
+			Answers:  splitCSVList(cell("answers")),
+			Tags:     splitCSVList(cell("tags")),
+		}
+		if points := cell("points"); points != "" {
+			value, err := strconv.Atoi(points)
+			if err != nil || value < 0 {
+				result.addProblem(source, "points must be a whole number of at least 0, got %q", points)
+				continue
+			}
+			question.Points = value
+		}
+		if question.ID != "" && !safeQuestionID.MatchString(question.ID) {
+			result.addProblem(source, "id %q may only contain letters, digits, - and _", question.ID)
+			continue
+		}
+
+		result.addQuestion(question, source)
+	}
+
+	seen := make(map[string]bool)
+	for _, question := range result.Questions {
+		if question.ID != "" && seen[question.ID] {
+			result.addProblem("id "+question.ID, "used by more than one row")
+		}
+		seen[question.ID] = true
+	}
+
+	return result, nil
+}
+
+func splitCSVList(cell string) []string {
+	var values []string
+	for _, value := range strings.Split(cell, csvListSeparator) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// ExportCSV writes one row per question. Question sets and quiz settings
+// stay in config.json and are not part of the CSV.
+func ExportCSV(w io.Writer, config Config, questions []QuestionFile) ([]string, error) {
+	var problems []string
+	writer := csv.NewWriter(w)
+	if err := writer.Write(CSVColumns); err != nil {
+		return nil, err
+	}
+
+	for _, question := range questions {
+		if len(question.PinnedOptions) > 0 || question.ShuffleOptions != nil {
+			problems = append(problems, question.ID+": shuffle settings are not part of the CSV")
+		}
+		for _, value := range append(append(append([]string{}, question.Options...), question.Answers...), question.Tags...) {
+			if strings.Contains(value, csvListSeparator) {
+				problems = append(problems, fmt.Sprintf("%s: %q contains %s and will be split on import", question.ID, value, csvListSeparator))
+			}
+		}
+
+		points := ""
+		if question.Points > 0 {
+			points = strconv.Itoa(question.Points)
+		}
+		record := []string{
+			question.ID,
+			question.Type,
+			question.Question,
+			strings.Join(question.Options, csvListSeparator),
+			strings.Join(question.Answers, csvListSeparator),
+			strings.Join(question.Tags, csvListSeparator),
+			points,
+		}
+		if err := writer.Write(record); err != nil {
+			return problems, err
+		}
+	}
+
+	writer.Flush()
+	return problems, writer.Error()
+}
@@ -0,0 +1,71 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCSV_RoundTrip(t *testing.T) {
+	config, questions, err := LoadQuizFiles(filepath.Join("../../quiz", "quiz01"))
+	if err != nil {
+		t.Fatalf("LoadQuizFiles() error = %v", err)
+	}
+	questions[0].Tags = []string{"geography", "europe"}
+	questions[0].Points = 3
+
+	var buf bytes.Buffer
+	if _, err := ExportCSV(&buf, config, questions); err != nil {
+		t.Fatalf("ExportCSV() error = %v", err)
+	}
+
+	result, err := ImportCSV(&buf)
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("Unexpected problems: %v", result.Problems)
+	}
+	sameQuestions(t, questions, result.Questions)
+
+	if got := result.Questions[0]; strings.Join(got.Tags, ",") != "geography,europe" || got.Points != 3 {
+		t.Errorf("Expected tags and points to survive, got %v and %d", got.Tags, got.Points)
+	}
+}
+
+func TestImportCSV_Validation(t *testing.T) {
+	csv := `Question,Type,Answers,Options,Points
+"What is 2 + 2?",multiple_choice,4,3|4|5,
+"Pick one",multiple_choice,7,3|4|5,
+"Is Go compiled?",true_false,yes,,
+"Fill me",fill_in_blank,,,
+"Worth a lot",fill_in_blank,lots,,many
+,,,,
+`
+
+	result, err := ImportCSV(strings.NewReader(csv))
+	if err != nil {
+		t.Fatalf("ImportCSV() error = %v", err)
+	}
+
+	if len(result.Questions) != 1 || result.Questions[0].Question != "What is 2 + 2?" {
+		t.Errorf("Expected only the first row to be valid, got %+v", result.Questions)
+	}
+
+	wantProblems := []string{"row 3: answer \"7\"", "row 4: true/false", "row 5: question has no answers", "row 6: points"}
+	if len(result.Problems) != len(wantProblems) {
+		t.Fatalf("Expected %d problems, got %v", len(wantProblems), result.Problems)
+	}
+	for i, want := range wantProblems {
+		if !strings.HasPrefix(result.Problems[i], want) {
+			t.Errorf("Problem %d = %q, want prefix %q", i+1, result.Problems[i], want)
+		}
+	}
+}
+
+func TestImportCSV_MissingColumn(t *testing.T) {
+	if _, err := ImportCSV(strings.NewReader("id,question\nq1,What?\n")); err == nil {
+		t.Error("Expected error for missing columns, got nil")
+	}
+}
@@ -0,0 +1,91 @@
+package quiz_logic
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Standing is how one learner has done at one quiz over all their attempts
+type Standing struct {
+	LearnerID   string    `json:"learnerId,omitempty"` // empty for learners without a profile
+	Learner     string    `json:"learner"`
+	Group       string    `json:"group,omitempty"`
+	QuizID      string    `json:"quizId"`
+	Title       string    `json:"title"`
+	Attempts    int       `json:"attempts"`
+	BestScore   int       `json:"bestScore"` // percentage
+	LastScore   int       `json:"lastScore"`
+	Passed      bool      `json:"passed"` // in any attempt
+	LastAttempt time.Time `json:"lastAttempt"`
+}
+
+// Standings sums results up for a dashboard: one standing for each learner
+// and quiz, sorted by learner, then by quiz. Learners are told apart by
+// profile ID, or by name for results recorded without a profile; names and
+// groups are those of their latest result.
+func Standings(results []Result) []Standing {
+	type key struct{ learner, quiz string }
+	byKey := make(map[key]*Standing)
+	var standings []*Standing
+	for _, result := range results {
+		learner := learnerKey(result)
+		quiz := strings.ToLower(result.QuizID)
+		if quiz == "" {
+			quiz = "title:" + result.Title
+		}
+		standing := byKey[key{learner, quiz}]
+		if standing == nil {
+			standing = &Standing{LearnerID: result.LearnerID, QuizID: result.QuizID}
+			byKey[key{learner, quiz}] = standing
+			standings = append(standings, standing)
+		}
+
+		standing.Attempts++
+		standing.BestScore = max(standing.BestScore, result.Score)
+		standing.Passed = standing.Passed || result.Passed
+		if !result.Finished.Before(standing.LastAttempt) {
+			standing.Learner, standing.Group, standing.Title = result.Learner, result.Group, result.Title
+			standing.LastScore, standing.LastAttempt = result.Score, result.Finished
+		}
+	}
+
+	sorted := make([]Standing, len(standings))
+	for i, standing := range standings {
+		sorted[i] = *standing
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if learnerA, learnerB := strings.ToLower(a.Learner), strings.ToLower(b.Learner); learnerA != learnerB {
+			return learnerA < learnerB
+		}
+		if a.LearnerID != b.LearnerID {
+			return a.LearnerID < b.LearnerID
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+	return sorted
+}
+
+// learnerKey tells learners apart: by profile ID, or by name for results
+// recorded without a profile
+func learnerKey(result Result) string {
+	if result.LearnerID == "" {
+		return "name:" + strings.ToLower(result.Learner)
+	}
+	return "id:" + strings.ToLower(result.LearnerID)
+}
+
+// FilterStandings keeps the standings of one learner, given by profile ID
+// or name, and of one group; empty matches every standing. Both ignore
+// case.
+func FilterStandings(standings []Standing, learner, group string) []Standing {
+	var matches []Standing
+	for _, standing := range standings {
+		if (learner == "" || strings.EqualFold(standing.LearnerID, learner) || strings.EqualFold(standing.Learner, learner)) &&
+			(group == "" || strings.EqualFold(standing.Group, group)) {
+			matches = append(matches, standing)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,42 @@
+package quiz_logic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStandings(t *testing.T) {
+	start := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	results := []Result{
+		{QuizID: "capitals", Title: "Capitals", Learner: "Ada", LearnerID: "ada", Finished: start, Score: 90, Passed: true},
+		{QuizID: "capitals", Title: "Capitals", Learner: "Ada Lovelace", LearnerID: "ada", Group: "7b", Finished: start.Add(time.Hour), Score: 40},
+		{QuizID: "algebra", Title: "Algebra", Learner: "Ada Lovelace", LearnerID: "ada", Group: "7b", Finished: start.Add(2 * time.Hour), Score: 50},
+		{QuizID: "capitals", Title: "Capitals", Learner: "bob", Finished: start, Score: 100, Passed: true},
+		{QuizID: "capitals", Title: "Capitals", Learner: "Bob", Finished: start.Add(time.Minute), Score: 70, Passed: true},
+	}
+
+	standings := Standings(results)
+	want := []Standing{
+		{LearnerID: "ada", Learner: "Ada Lovelace", Group: "7b", QuizID: "algebra", Title: "Algebra", Attempts: 1, BestScore: 50, LastScore: 50, LastAttempt: start.Add(2 * time.Hour)},
+		{LearnerID: "ada", Learner: "Ada Lovelace", Group: "7b", QuizID: "capitals", Title: "Capitals", Attempts: 2, BestScore: 90, LastScore: 40, Passed: true, LastAttempt: start.Add(time.Hour)},
+		{Learner: "Bob", QuizID: "capitals", Title: "Capitals", Attempts: 2, BestScore: 100, LastScore: 70, Passed: true, LastAttempt: start.Add(time.Minute)},
+	}
+	if len(standings) != len(want) {
+		t.Fatalf("Standings() = %+v, want %d standings", standings, len(want))
+	}
+	for i := range want {
+		if standings[i] != want[i] {
+			t.Errorf("Standings()[%d] = %+v, want %+v", i, standings[i], want[i])
+		}
+	}
+
+	if got := FilterStandings(standings, "ADA", ""); len(got) != 2 {
+		t.Errorf("FilterStandings(ID) = %+v, want Ada's", got)
+	}
+	if got := FilterStandings(standings, "bob", ""); len(got) != 1 {
+		t.Errorf("FilterStandings(name) = %+v, want Bob's", got)
+	}
+	if got := FilterStandings(standings, "", "7B"); len(got) != 2 {
+		t.Errorf("FilterStandings(group) = %+v, want group 7b's", got)
+	}
+}
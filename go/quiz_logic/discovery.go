@@ -0,0 +1,343 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// RootsEnv lists quiz roots, separated like PATH, when no -root flag is given
+const RootsEnv = "QUIZ_ROOTS"
+
+// DefaultRoot is where quizzes are found when no roots are configured
+var DefaultRoot = filepath.Join("..", "quiz")
+
+// Discovery says where quizzes are found and which of them are listed.
+// Patterns use path.Match syntax and are matched against a quiz's path
+// below its root, such as "science/quiz01"; a pattern without a slash is
+// also matched against the last element alone.
+type Discovery struct {
+	Roots   []string `json:"roots"`
+	Include []string `json:"include"` // only list quizzes matching one of these
+	Exclude []string `json:"exclude"` // skip quizzes and folders matching any of these
+}
+
+// UserSettingsPath returns where the user's quiz settings file is looked
+// for, without an extension: settings.json, .yaml, .yml or .toml may be used
+func UserSettingsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "quiz", "settings"), nil
+}
+
+// LoadDiscovery reads the discovery rules from the user's settings file,
+// then lets QUIZ_ROOTS replace its roots. Without either, DefaultRoot is
+// used. Relative roots in the settings file are relative to the file.
+func LoadDiscovery() (Discovery, error) {
+	var discovery Discovery
+
+	if settingsPath, err := UserSettingsPath(); err == nil {
+		for _, ext := range QuizFileExts {
+			data, err := os.ReadFile(settingsPath + ext)
+			if err != nil {
+				continue
+			}
+			if err := decodeQuizFile(settingsPath+ext, data, &discovery); err != nil {
+				return discovery, fmt.Errorf("error parsing %s: %v", settingsPath+ext, err)
+			}
+			for i, root := range discovery.Roots {
+				if !filepath.IsAbs(root) {
+					discovery.Roots[i] = filepath.Join(filepath.Dir(settingsPath), root)
+				}
+			}
+			break
+		}
+	}
+
+	if env := os.Getenv(RootsEnv); env != "" {
+		discovery.Roots = nil
+		for _, root := range filepath.SplitList(env) {
+			if root != "" {
+				discovery.Roots = append(discovery.Roots, root)
+			}
+		}
+	}
+
+	if len(discovery.Roots) == 0 {
+		discovery.Roots = []string{DefaultRoot}
+	}
+	return discovery, nil
+}
+
+// QuizProblem is a quiz that was found but cannot be taken
+type QuizProblem struct {
+	Path string
+	Err  error
+}
+
+func (p QuizProblem) Error() string {
+	return fmt.Sprintf("%s: %v", p.Path, p.Err)
+}
+
+// MarshalJSON writes the problem as its path and error message
+func (p QuizProblem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Path  string `json:"path"`
+		Error string `json:"error"`
+	}{p.Path, p.Err.Error()})
+}
+
+// Find lists the quizzes below every root, numbered in root order. Quizzes
+// that fail to load are returned as problems instead of failing the rest;
+// the error is only for bad patterns and unreadable roots.
+func (d Discovery) Find() ([]QuizInfo, []QuizProblem, error) {
+	if err := d.checkPatterns(); err != nil {
+		return nil, nil, err
+	}
+
+	var quizzes []QuizInfo
+	var problems []QuizProblem
+	seen := make(map[string]bool)
+	for _, root := range d.Roots {
+		root = filepath.Clean(root)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		found, broken, err := d.find(os.DirFS(root))
+		if err != nil {
+			return nil, nil, fmt.Errorf("quiz root %s: %v", root, err)
+		}
+		for _, quiz := range found {
+			quiz.Path = filepath.Join(root, filepath.FromSlash(quiz.Path))
+			quiz.FS = nil
+			quizzes = append(quizzes, quiz)
+		}
+		for _, problem := range broken {
+			problem.Path = filepath.Join(root, filepath.FromSlash(problem.Path))
+			problems = append(problems, problem)
+		}
+	}
+
+	quizzes, problems = claimIDs(quizzes, problems)
+	return quizzes, problems, nil
+}
+
+// FindFS lists the quizzes in fsys with the discovery's patterns; its
+// roots are not used
+func (d Discovery) FindFS(fsys fs.FS) ([]QuizInfo, []QuizProblem, error) {
+	if err := d.checkPatterns(); err != nil {
+		return nil, nil, err
+	}
+	quizzes, problems, err := d.find(fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quizzes, problems = claimIDs(quizzes, problems)
+	return quizzes, problems, nil
+}
+
+func (d Discovery) checkPatterns() error {
+	for _, pattern := range append(append([]string{}, d.Include...), d.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %v", pattern, err)
+		}
+	}
+	return nil
+}
+
+// find walks fsys for quizzes. A directory with a config file is a quiz;
+// any other directory is a category folder that is searched in turn.
+func (d Discovery) find(fsys fs.FS) ([]QuizInfo, []QuizProblem, error) {
+	var quizzes []QuizInfo
+	var problems []QuizProblem
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && (strings.HasPrefix(entry.Name(), ".") || matchesAny(d.Exclude, name)) {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		isQuiz := false
+		switch {
+		case entry.IsDir():
+			isQuiz = hasConfigFile(fsys, name)
+		case isBundle(name):
+			isQuiz = true
+		case isMarkdownQuiz(name):
+			isQuiz = hasFrontMatter(fsys, name)
+		}
+		if !isQuiz || (len(d.Include) > 0 && !matchesAny(d.Include, name)) {
+			return nil
+		}
+
+		config, bank, err := checkQuiz(fsys, name)
+		id := quizID(config, name)
+		if err != nil {
+			problems = append(problems, QuizProblem{Path: name, Err: err})
+		} else {
+			category := config.Category
+			if category == "" && path.Dir(name) != "." {
+				category = path.Dir(name)
+			}
+			duration := config.EstimatedDuration
+			if duration == 0 {
+				duration = config.TimeLimit
+			}
+			quizzes = append(quizzes, QuizInfo{
+				ID:          id,
+				Title:       config.Title,
+				Path:        name,
+				FS:          fsys,
+				Category:    strings.Trim(category, "/"),
+				Description: config.Description,
+				Difficulty:  config.Difficulty,
+				Duration:    duration,
+				Tags:        config.Tags,
+				config:      config,
+				bank:        bank,
+			})
+		}
+
+		if entry.IsDir() {
+			return fs.SkipDir // question files are not quizzes of their own
+		}
+		return nil
+	})
+	return quizzes, problems, err
+}
+
+// quizID returns the ID of the quiz called name: the one its config sets,
+// or else its slug
+func quizID(config Config, name string) string {
+	if config.ID != "" {
+		return config.ID
+	}
+	return quizSlug(name)
+}
+
+// quizIDPattern is what a quiz ID may look like: path-like, without spaces
+var quizIDPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+(/[A-Za-z0-9_.-]+)*$`)
+
+// quizSlug makes the default ID of a quiz from its path below the root,
+// such as "science/forces" for "Science/Forces.quiz.zip"
+func quizSlug(name string) string {
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if strings.HasSuffix(strings.ToLower(name), ".quiz") {
+		name = name[:len(name)-len(".quiz")]
+	}
+
+	var parts []string
+	for _, part := range strings.Split(strings.ToLower(name), "/") {
+		part = strings.Trim(nonSlugChars.ReplaceAllString(part, "-"), "-")
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "quiz"
+	}
+	return strings.Join(parts, "/")
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9_.]+`)
+
+// claimIDs numbers the quizzes for display in order. A quiz whose ID is
+// already taken, ignoring case, becomes a problem.
+func claimIDs(quizzes []QuizInfo, problems []QuizProblem) ([]QuizInfo, []QuizProblem) {
+	taken := make(map[string]string)
+	var kept []QuizInfo
+	for _, quiz := range quizzes {
+		key := strings.ToLower(quiz.ID)
+		if other, ok := taken[key]; ok {
+			err := fmt.Errorf("quiz ID %q is already used by %s", quiz.ID, other)
+			problems = append(problems, QuizProblem{Path: quiz.Path, Err: err})
+			continue
+		}
+		taken[key] = quiz.Path
+		quiz.Index = len(kept) + 1
+		kept = append(kept, quiz)
+	}
+	return kept, problems
+}
+
+// checkQuiz loads everything a quiz needs to be taken: its config, every
+// question, and the questions its sets name
+func checkQuiz(fsys fs.FS, name string) (Config, map[string]Question, error) {
+	fsys, name, err := openQuiz(fsys, name)
+	if err != nil {
+		return Config{}, nil, err
+	}
+	config, err := loadConfig(fsys, name)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading config: %v", err)
+	}
+	bank, err := loadQuestions(fsys, name)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading questions: %v", err)
+	}
+
+	for _, set := range config.Questions {
+		for _, id := range set {
+			if _, ok := bank[id]; !ok {
+				return config, nil, fmt.Errorf("question file not found: %s", id)
+			}
+		}
+	}
+	if config.ID != "" && !quizIDPattern.MatchString(config.ID) {
+		return config, nil, fmt.Errorf("id %q may only contain letters, digits, -, _ and . separated by /", config.ID)
+	}
+	return config, bank, nil
+}
+
+// LintQuiz checks the quiz directory, Markdown quiz file or quiz bundle at
+// quizPath the way discovery does, without taking it
+func LintQuiz(quizPath string) error {
+	_, _, err := checkQuiz(osQuiz(quizPath))
+	return err
+}
+
+// hasConfigFile reports whether the directory dir holds any config file
+func hasConfigFile(fsys fs.FS, dir string) bool {
+	for _, ext := range QuizFileExts {
+		if _, err := fs.Stat(fsys, path.Join(dir, "config"+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasFrontMatter tells Markdown quizzes from other Markdown files, such as
+// a README next to the quizzes
+func hasFrontMatter(fsys fs.FS, name string) bool {
+	data, err := fs.ReadFile(fsys, name)
+	return err == nil && bytes.HasPrefix(data, []byte("---"))
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(name)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,237 @@
+package quiz_logic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeQuizTree creates a quiz for every directory name, titled by it
+func writeQuizTree(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		quizDir := filepath.Join(root, filepath.FromSlash(dir))
+		if err := os.MkdirAll(quizDir, 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		config := []byte(`{"title": "` + dir + `", "questions": []}`)
+		if err := os.WriteFile(filepath.Join(quizDir, "config.json"), config, 0644); err != nil {
+			t.Fatalf("Failed to write config for %s: %v", dir, err)
+		}
+	}
+}
+
+func quizTitles(quizzes []QuizInfo) []string {
+	var titles []string
+	for i, quiz := range quizzes {
+		if quiz.Index != i+1 {
+			return nil
+		}
+		titles = append(titles, quiz.Title)
+	}
+	return titles
+}
+
+func TestDiscovery_Find(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	writeQuizTree(t, first, "basics", "science/physics", "science/chemistry", "drafts/new", ".hidden")
+	writeQuizTree(t, second, "history")
+	if err := os.WriteFile(filepath.Join(first, "README.md"), []byte("# Quizzes\n"), 0644); err != nil {
+		t.Fatalf("Failed to write README: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		discovery Discovery
+		want      []string
+	}{
+		{
+			name:      "Recursive",
+			discovery: Discovery{Roots: []string{first}},
+			want:      []string{"basics", "drafts/new", "science/chemistry", "science/physics"},
+		},
+		{
+			name:      "Several roots",
+			discovery: Discovery{Roots: []string{first, second, first}},
+			want:      []string{"basics", "drafts/new", "science/chemistry", "science/physics", "history"},
+		},
+		{
+			name:      "Exclude folder",
+			discovery: Discovery{Roots: []string{first}, Exclude: []string{"drafts"}},
+			want:      []string{"basics", "science/chemistry", "science/physics"},
+		},
+		{
+			name:      "Include path",
+			discovery: Discovery{Roots: []string{first}, Include: []string{"science/*"}, Exclude: []string{"chem*"}},
+			want:      []string{"science/physics"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quizzes, _, err := tt.discovery.Find()
+			if err != nil {
+				t.Fatalf("Find() error = %v", err)
+			}
+			if got := quizTitles(quizzes); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Find() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	quizzes, _, _ := Discovery{Roots: []string{first}}.Find()
+	if quizzes[3].Category != "science" || quizzes[3].Path != filepath.Join(first, "science", "physics") {
+		t.Errorf("Unexpected nested quiz %+v", quizzes[3])
+	}
+}
+
+func TestDiscovery_FindErrors(t *testing.T) {
+	if _, _, err := (Discovery{Roots: []string{t.TempDir()}, Include: []string{"["}}).Find(); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	if _, _, err := (Discovery{Roots: []string{"/nonexistent/directory"}}).Find(); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+}
+
+func TestLoadDiscovery(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("AppData", filepath.Join(home, "AppData"))
+	t.Setenv(RootsEnv, "")
+
+	discovery, err := LoadDiscovery()
+	if err != nil {
+		t.Fatalf("LoadDiscovery() error = %v", err)
+	}
+	if !reflect.DeepEqual(discovery.Roots, []string{DefaultRoot}) {
+		t.Errorf("Expected the default root, got %v", discovery.Roots)
+	}
+
+	settingsPath, err := UserSettingsPath()
+	if err != nil {
+		t.Fatalf("UserSettingsPath() error = %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(settingsPath), 0755); err != nil {
+		t.Fatalf("Failed to create settings directory: %v", err)
+	}
+	settings := "roots: [banks, /srv/quizzes]\nexclude: [drafts]\n"
+	if err := os.WriteFile(settingsPath+".yaml", []byte(settings), 0644); err != nil {
+		t.Fatalf("Failed to write settings: %v", err)
+	}
+
+	discovery, err = LoadDiscovery()
+	if err != nil {
+		t.Fatalf("LoadDiscovery() error = %v", err)
+	}
+	wantRoots := []string{filepath.Join(filepath.Dir(settingsPath), "banks"), "/srv/quizzes"}
+	if !reflect.DeepEqual(discovery.Roots, wantRoots) || !reflect.DeepEqual(discovery.Exclude, []string{"drafts"}) {
+		t.Errorf("LoadDiscovery() = %+v, want roots %v", discovery, wantRoots)
+	}
+
+	t.Setenv(RootsEnv, "one"+string(os.PathListSeparator)+"two")
+	discovery, err = LoadDiscovery()
+	if err != nil {
+		t.Fatalf("LoadDiscovery() error = %v", err)
+	}
+	if !reflect.DeepEqual(discovery.Roots, []string{"one", "two"}) || len(discovery.Exclude) != 1 {
+		t.Errorf("Expected %s to replace the roots only, got %+v", RootsEnv, discovery)
+	}
+}
+
+func TestDiscovery_CatalogDetails(t *testing.T) {
+	root := t.TempDir()
+	writeQuizFile := func(dir, config string) {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("Failed to create %s: %v", dir, err)
+		}
+		if err := os.WriteFile(filepath.Join(root, dir, "config.yaml"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config for %s: %v", dir, err)
+		}
+	}
+	writeQuizFile("folder/detailed", `title: Detailed
+description: All the details
+category: science/physics
+difficulty: hard
+estimatedDuration: 20
+timeLimit: 30
+tags: [forces, motion]
+`)
+	writeQuizFile("folder/plain", "title: Plain\ntimeLimit: 15\n")
+
+	quizzes, problems, err := Discovery{Roots: []string{root}}.Find()
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Find() = %v, %v", problems, err)
+	}
+
+	detailed, plain := quizzes[0], quizzes[1]
+	if detailed.Category != "science/physics" || detailed.Description != "All the details" || detailed.Difficulty != "hard" ||
+		detailed.Duration != 20 || !reflect.DeepEqual(detailed.Tags, []string{"forces", "motion"}) {
+		t.Errorf("Unexpected details %+v", detailed)
+	}
+	if plain.Category != "folder" || plain.Duration != 15 {
+		t.Errorf("Expected the folder as category and the time limit as duration, got %+v", plain)
+	}
+}
+
+func TestQuizSlug(t *testing.T) {
+	tests := map[string]string{
+		"quiz01":                  "quiz01",
+		"Science/Forces.quiz.zip": "science/forces",
+		"quiz02.md":               "quiz02",
+		"My Quizzes/Week 1!":      "my-quizzes/week-1",
+		".":                       "quiz",
+	}
+	for name, want := range tests {
+		if got := quizSlug(name); got != want {
+			t.Errorf("quizSlug(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestDiscovery_QuizIDs(t *testing.T) {
+	root := t.TempDir()
+	writeQuizTree(t, root, "a", "b", "c", "d")
+	writeConfig := func(dir, config string) {
+		if err := os.WriteFile(filepath.Join(root, dir, "config.json"), []byte(config), 0644); err != nil {
+			t.Fatalf("Failed to write config for %s: %v", dir, err)
+		}
+	}
+	writeConfig("b", `{"title": "b", "id": "intro/basics"}`)
+	writeConfig("c", `{"title": "c", "id": "Intro/Basics"}`)
+	writeConfig("d", `{"title": "d", "id": "has spaces"}`)
+
+	quizzes, problems, err := Discovery{Roots: []string{root}}.Find()
+	if err != nil {
+		t.Fatalf("Find() error = %v", err)
+	}
+
+	var ids []string
+	for _, quiz := range quizzes {
+		ids = append(ids, quiz.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "intro/basics"}) || quizzes[1].Index != 2 {
+		t.Errorf("Unexpected quizzes %+v", quizzes)
+	}
+
+	if len(problems) != 2 {
+		t.Fatalf("Expected problems for c and d, got %v", problems)
+	}
+	for _, problem := range problems {
+		switch filepath.Base(problem.Path) {
+		case "c":
+			if !strings.Contains(problem.Error(), "already used by") {
+				t.Errorf("Expected a duplicate ID problem, got %v", problem)
+			}
+		case "d":
+			if !strings.Contains(problem.Error(), "may only contain") {
+				t.Errorf("Expected an invalid ID problem, got %v", problem)
+			}
+		default:
+			t.Errorf("Unexpected problem %v", problem)
+		}
+	}
+}
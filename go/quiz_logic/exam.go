@@ -0,0 +1,350 @@
+package quiz_logic
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+)
+
+// maxFormAttempts bounds how many seeds are tried to find a form that no
+// earlier version already uses. Small banks may not have enough variety, and
+// then GenerateExams fails rather than print the same form twice.
+const maxFormAttempts = 20
+
+// ExamVersion is one printable form of a quiz
+type ExamVersion struct {
+	Number    int
+	Seed      int64 // regenerates this version with the same quiz files
+	Questions []Question
+}
+
+// examItem is a question as it appears on paper
+type examItem struct {
+	Number  int
+	ID      string
+	Text    string
+	Type    string
+	Options []examOption
+	Answer  string // answer key entry
+}
+
+type examOption struct {
+	Label string
+	Text  string
+}
+
+// examSheet holds everything the exam and answer key templates need
+type examSheet struct {
+	Title        string
+	Version      int
+	Seed         int64
+	TimeLimit    int
+	PassingScore int
+	Items        []examItem
+}
+
+// ExamFormats maps the supported exam formats to their file extensions
+var ExamFormats = map[string]string{
+	"markdown": ".md",
+	"html":     ".html",
+	"text":     ".txt",
+}
+
+// GenerateExams builds distinct exam versions of the quiz at quizPath using
+// the same selection as StartQuiz. A non-zero seed overrides the config seed.
+func GenerateExams(quizPath string, versions int, seed int64) (Config, []ExamVersion, error) {
+	fsys, name := osQuiz(quizPath)
+	return GenerateExamsFS(fsys, name, versions, seed)
+}
+
+// GenerateExamsFS is GenerateExams for the quiz called name in fsys
+func GenerateExamsFS(fsys fs.FS, name string, versions int, seed int64) (Config, []ExamVersion, error) {
+	config, err := LoadConfigFS(fsys, name)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading config: %v", err)
+	}
+
+	bank, err := loadQuestionBankFS(fsys, name)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading questions: %v", err)
+	}
+
+	// Each version gets its own seed drawn from the master seed, so one
+	// seed reproduces the whole set of versions
+	master := rand.New(rand.NewSource(resolveSeed(seed, config.Seed)))
+	seen := make(map[string]bool)
+	var exams []ExamVersion
+	for number := 1; number <= versions; number++ {
+		var quiz *Quiz
+		for attempt := 0; ; attempt++ {
+			if attempt == maxFormAttempts {
+				return config, nil, fmt.Errorf("found only %d distinct versions in %d tries; ask for fewer versions or add questions", number-1, maxFormAttempts)
+			}
+			quiz = &Quiz{Config: config, Seed: master.Int63()}
+			if err := quiz.pickQuestions(bank); err != nil {
+				return config, nil, fmt.Errorf("error selecting questions: %v", err)
+			}
+			if !seen[formSignature(quiz.Questions)] {
+				break
+			}
+		}
+		seen[formSignature(quiz.Questions)] = true
+
+		exams = append(exams, ExamVersion{
+			Number:    number,
+			Seed:      quiz.Seed,
+			Questions: quiz.Questions,
+		})
+	}
+
+	return config, exams, nil
+}
+
+// formSignature identifies a form by its questions and their option order
+func formSignature(questions []Question) string {
+	var parts []string
+	for _, question := range questions {
+		parts = append(parts, question.getID()+":"+strings.Join(question.getOptions(), "|"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// WriteExams renders every exam version and its answer key in each format
+// to outDir and returns the paths of the written files. Unknown formats
+// are rejected before anything is written.
+func WriteExams(outDir string, config Config, exams []ExamVersion, formats []string) ([]string, error) {
+	for _, format := range formats {
+		if _, ok := ExamFormats[format]; !ok {
+			return nil, fmt.Errorf("unknown exam format: %s", format)
+		}
+	}
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating output directory: %v", err)
+	}
+
+	var written []string
+	for _, exam := range exams {
+		for _, format := range formats {
+			ext := ExamFormats[format]
+			base := filepath.Join(outDir, fmt.Sprintf("version%02d", exam.Number))
+			files := []struct {
+				path   string
+				render func(io.Writer, Config, ExamVersion, string) error
+			}{
+				{base + ext, RenderExam},
+				{base + "-key" + ext, RenderAnswerKey},
+			}
+			for _, file := range files {
+				if err := writeRendered(file.path, config, exam, format, file.render); err != nil {
+					return written, err
+				}
+				written = append(written, file.path)
+			}
+		}
+	}
+
+	return written, nil
+}
+
+func writeRendered(path string, config Config, exam ExamVersion, format string, render func(io.Writer, Config, ExamVersion, string) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", path, err)
+	}
+	defer f.Close()
+
+	if err := render(f, config, exam, format); err != nil {
+		return fmt.Errorf("error writing %s: %v", path, err)
+	}
+	return nil
+}
+
+// RenderExam writes the learner's copy of an exam version
+func RenderExam(w io.Writer, config Config, exam ExamVersion, format string) error {
+	return renderSheet(w, newExamSheet(config, exam), format, examTemplates)
+}
+
+// RenderAnswerKey writes the answer key for an exam version
+func RenderAnswerKey(w io.Writer, config Config, exam ExamVersion, format string) error {
+	return renderSheet(w, newExamSheet(config, exam), format, keyTemplates)
+}
+
+func renderSheet(w io.Writer, sheet examSheet, format string, templates map[string]string) error {
+	source, ok := templates[format]
+	if !ok {
+		return fmt.Errorf("unknown exam format: %s", format)
+	}
+
+	// html/template escapes question text, the other formats are verbatim
+	if format == "html" {
+		tmpl, err := template.New(format).Parse(source)
+		if err != nil {
+			return err
+		}
+		return tmpl.Execute(w, sheet)
+	}
+	tmpl, err := texttemplate.New(format).Parse(source)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, sheet)
+}
+
+func newExamSheet(config Config, exam ExamVersion) examSheet {
+	sheet := examSheet{
+		Title:        config.Title,
+		Version:      exam.Number,
+		Seed:         exam.Seed,
+		TimeLimit:    config.TimeLimit,
+		PassingScore: config.PassingScore,
+	}
+
+	for i, question := range exam.Questions {
+		item := examItem{
+			Number: i + 1,
+			ID:     question.getID(),
+			Text:   question.getQuestion(),
+			Type:   question.getType(),
+		}
+
+		var correct []string
+		for j, option := range question.getOptions() {
+			label := optionLabel(j)
+			item.Options = append(item.Options, examOption{Label: label, Text: option})
+			if isAnswerOption(question, option) {
+				correct = append(correct, fmt.Sprintf("%s. %s", label, option))
+			}
+		}
+
+		if len(correct) > 0 {
+			item.Answer = strings.Join(correct, " or ")
+		} else {
+			item.Answer = strings.Join(question.getAnswers(), " / ")
+		}
+		sheet.Items = append(sheet.Items, item)
+	}
+
+	return sheet
+}
+
+// isAnswerOption reports whether option is one of the question's answers.
+// It compares the text, as checkAnswer would take an option that is itself
+// a number for the option at that position.
+func isAnswerOption(question Question, option string) bool {
+	for _, answer := range question.getAnswers() {
+		if strings.EqualFold(strings.TrimSpace(answer), strings.TrimSpace(option)) {
+			return true
+		}
+	}
+	return false
+}
+
+// optionLabel turns a zero-based option index into A, B, C, ...
+func optionLabel(index int) string {
+	if index < 26 {
+		return string(rune('A' + index))
+	}
+	return fmt.Sprintf("%d", index+1)
+}
+
+var examTemplates = map[string]string{
+	"markdown": `# {{.Title}}
+
+**Version {{.Version}}**
+
+Name: ______________________ Date: ______________
+{{if .TimeLimit}}
+Time limit: {{.TimeLimit}} minutes
+{{end}}{{if .PassingScore}}
+Passing score: {{.PassingScore}}%
+{{end}}{{range .Items}}
+{{.Number}}. {{.Text}}
+{{if .Options}}{{range .Options}}
+    - [ ] {{.Label}}. {{.Text}}{{end}}
+{{else}}
+    Answer: ______________________
+{{end}}{{end}}`,
+
+	"html": `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Version {{.Version}}</title>
+<style>
+body { font-family: serif; max-width: 48em; margin: 2em auto; }
+ol.questions > li { margin-bottom: 1.5em; }
+ol.options { list-style: upper-alpha; }
+.blank { display: inline-block; border-bottom: 1px solid #000; width: 20em; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p><strong>Version {{.Version}}</strong></p>
+<p>Name: <span class="blank"></span> Date: <span class="blank"></span></p>
+{{if .TimeLimit}}<p>Time limit: {{.TimeLimit}} minutes</p>
+{{end}}{{if .PassingScore}}<p>Passing score: {{.PassingScore}}%</p>
+{{end}}<ol class="questions">
+{{range .Items}}<li>
+<p>{{.Text}}</p>
+{{if .Options}}<ol class="options">
+{{range .Options}}<li>{{.Text}}</li>
+{{end}}</ol>
+{{else}}<p>Answer: <span class="blank"></span></p>
+{{end}}</li>
+{{end}}</ol>
+</body>
+</html>
+`,
+
+	"text": `{{.Title}}
+Version {{.Version}}
+
+Name: ______________________ Date: ______________
+{{if .TimeLimit}}Time limit: {{.TimeLimit}} minutes
+{{end}}{{if .PassingScore}}Passing score: {{.PassingScore}}%
+{{end}}{{range .Items}}
+{{.Number}}. {{.Text}}
+{{if .Options}}{{range .Options}}   {{.Label}}. {{.Text}}
+{{end}}{{else}}   Answer: ______________________
+{{end}}{{end}}`,
+}
+
+var keyTemplates = map[string]string{
+	"markdown": `# {{.Title}} - Answer Key
+
+**Version {{.Version}}** (seed {{.Seed}})
+
+| # | Answer | Question ID |
+|---|--------|-------------|
+{{range .Items}}| {{.Number}} | {{.Answer}} | {{.ID}} |
+{{end}}`,
+
+	"html": `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} - Version {{.Version}} Answer Key</title>
+</head>
+<body>
+<h1>{{.Title}} - Answer Key</h1>
+<p><strong>Version {{.Version}}</strong> (seed {{.Seed}})</p>
+<table>
+<tr><th>#</th><th>Answer</th><th>Question ID</th></tr>
+{{range .Items}}<tr><td>{{.Number}}</td><td>{{.Answer}}</td><td>{{.ID}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`,
+
+	"text": `{{.Title}} - Answer Key
+Version {{.Version}} (seed {{.Seed}})
+
+{{range .Items}}{{.Number}}. {{.Answer}} [{{.ID}}]
+{{end}}`,
+}
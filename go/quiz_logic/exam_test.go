@@ -0,0 +1,142 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateExams(t *testing.T) {
+	quizPath := filepath.Join("../../quiz", "test02")
+
+	_, exams, err := GenerateExams(quizPath, 3, 42)
+	if err != nil {
+		t.Fatalf("GenerateExams() error = %v", err)
+	}
+	if len(exams) != 3 {
+		t.Fatalf("Expected 3 versions, got %d", len(exams))
+	}
+
+	seen := make(map[string]bool)
+	for _, exam := range exams {
+		signature := formSignature(exam.Questions)
+		if seen[signature] {
+			t.Errorf("Version %d duplicates an earlier version", exam.Number)
+		}
+		seen[signature] = true
+	}
+
+	// The same seed regenerates the same versions
+	_, again, err := GenerateExams(quizPath, 3, 42)
+	if err != nil {
+		t.Fatalf("GenerateExams() error = %v", err)
+	}
+	for i := range exams {
+		if exams[i].Seed != again[i].Seed || formSignature(exams[i].Questions) != formSignature(again[i].Questions) {
+			t.Errorf("Version %d differs between runs with the same seed", i+1)
+		}
+	}
+}
+
+func TestGenerateExams_TooFewForms(t *testing.T) {
+	quizPath := t.TempDir() // every form of a quiz without questions is the same
+	if err := os.WriteFile(filepath.Join(quizPath, "config.json"), []byte(`{"title": "Empty", "questions": []}`), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, exams, err := GenerateExams(quizPath, 2, 42); err == nil {
+		t.Errorf("GenerateExams() = %d versions, want an error for the duplicate", len(exams))
+	}
+}
+
+func TestRenderAnswerKey(t *testing.T) {
+	mcq := &MultipleChoiceQuestion{
+		BaseQuestion: BaseQuestion{
+			ID:           "question001",
+			QuestionText: "What is the capital of France?",
+			Type:         "multiple_choice",
+			Answers:      []string{"Paris"},
+		},
+		Options: []string{"London", "Paris", "Berlin", "Madrid"},
+	}
+	fib := &FillInBlankQuestion{
+		BaseQuestion: BaseQuestion{
+			ID:           "question002",
+			QuestionText: "Water turning to gas is called _______.",
+			Type:         "fill_in_blank",
+			Answers:      []string{"evaporation", "vaporization"},
+		},
+	}
+	exam := ExamVersion{Number: 2, Seed: 7, Questions: []Question{mcq, fib}}
+
+	for format := range ExamFormats {
+		t.Run(format, func(t *testing.T) {
+			var key bytes.Buffer
+			if err := RenderAnswerKey(&key, Config{Title: "Capitals"}, exam, format); err != nil {
+				t.Fatalf("RenderAnswerKey() error = %v", err)
+			}
+			for _, want := range []string{"B. Paris", "evaporation / vaporization", "question002"} {
+				if !strings.Contains(key.String(), want) {
+					t.Errorf("Answer key missing %q:\n%s", want, key.String())
+				}
+			}
+
+			var sheet bytes.Buffer
+			if err := RenderExam(&sheet, Config{Title: "Capitals"}, exam, format); err != nil {
+				t.Fatalf("RenderExam() error = %v", err)
+			}
+			if !strings.Contains(sheet.String(), "Madrid") {
+				t.Errorf("Exam missing options:\n%s", sheet.String())
+			}
+		})
+	}
+
+	if err := RenderExam(&bytes.Buffer{}, Config{}, exam, "pdf"); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
+
+func TestRenderAnswerKey_NumericOptions(t *testing.T) {
+	mcq := &MultipleChoiceQuestion{
+		BaseQuestion: BaseQuestion{ID: "q1", QuestionText: "What is 2 + 2?", Type: "multiple_choice", Answers: []string{"4"}},
+		Options:      []string{"4", "3", "5", "1"},
+	}
+	exam := ExamVersion{Number: 1, Questions: []Question{mcq}}
+
+	var key bytes.Buffer
+	if err := RenderAnswerKey(&key, Config{Title: "Sums"}, exam, "text"); err != nil {
+		t.Fatalf("RenderAnswerKey() error = %v", err)
+	}
+	if !strings.Contains(key.String(), "A. 4") || strings.Contains(key.String(), "D. 1") {
+		t.Errorf("Expected the key to give option A, got:\n%s", key.String())
+	}
+}
+
+func TestWriteExams(t *testing.T) {
+	outDir := t.TempDir()
+	exam := ExamVersion{Number: 1, Questions: []Question{&TrueFalseQuestion{
+		BaseQuestion: BaseQuestion{ID: "q1", QuestionText: "Go is compiled.", Type: "true_false", Answers: []string{"true"}},
+	}}}
+
+	written, err := WriteExams(outDir, Config{Title: "Go"}, []ExamVersion{exam}, []string{"markdown", "text"})
+	if err != nil {
+		t.Fatalf("WriteExams() error = %v", err)
+	}
+	if len(written) != 4 {
+		t.Errorf("Expected 4 files, got %v", written)
+	}
+	if _, err := os.Stat(filepath.Join(outDir, "version01-key.txt")); err != nil {
+		t.Errorf("Answer key not written: %v", err)
+	}
+
+	// An unknown format is found before any file is written
+	otherDir := filepath.Join(t.TempDir(), "exams")
+	if written, err := WriteExams(otherDir, Config{Title: "Go"}, []ExamVersion{exam}, []string{"markdown", "pdf"}); err == nil || len(written) != 0 {
+		t.Errorf("WriteExams() with an unknown format = %v, %v, want an error and no files", written, err)
+	}
+	if _, err := os.Stat(otherDir); err == nil {
+		t.Error("Expected nothing to be written for an unknown format")
+	}
+}
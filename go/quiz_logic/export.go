@@ -0,0 +1,28 @@
+package quiz_logic
+
+import (
+	"fmt"
+	"io"
+)
+
+// Exporters maps export format names to their writers. Each returns the
+// parts of the quiz that the format cannot represent.
+var Exporters = map[string]func(io.Writer, Config, []QuestionFile) ([]string, error){
+	"moodle": ExportMoodleXML,
+	"qti":    ExportQTI,
+	"csv":    ExportCSV,
+}
+
+// ExportQuiz writes the quiz directory at quizPath in the given format
+func ExportQuiz(w io.Writer, quizPath, format string) ([]string, error) {
+	exporter, ok := Exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown export format: %s", format)
+	}
+
+	config, questions, err := LoadQuizFiles(quizPath)
+	if err != nil {
+		return nil, err
+	}
+	return exporter(w, config, questions)
+}
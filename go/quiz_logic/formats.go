@@ -0,0 +1,159 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// QuizFileExts are the extensions config and question files may use, in
+// the order config files are looked for
+var QuizFileExts = []string{".json", ".yaml", ".yml", ".toml"}
+
+// isQuizFile reports whether name has one of QuizFileExts
+func isQuizFile(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, quizExt := range QuizFileExts {
+		if ext == quizExt {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigFile reports whether name is config.json or one of its YAML or
+// TOML equivalents
+func isConfigFile(name string) bool {
+	return isQuizFile(name) && strings.TrimSuffix(name, filepath.Ext(name)) == "config"
+}
+
+// findConfigFile returns the name of the config file in the quiz directory
+// dir of fsys. Having more than one is an error rather than a silent choice.
+func findConfigFile(fsys fs.FS, dir string) (string, error) {
+	var found []string
+	for _, ext := range QuizFileExts {
+		if _, err := fs.Stat(fsys, path.Join(dir, "config"+ext)); err == nil {
+			found = append(found, "config"+ext)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", fmt.Errorf("no config.json, config.yaml, config.yml or config.toml found")
+	case 1:
+		return found[0], nil
+	}
+	return "", fmt.Errorf("more than one config file: %s", strings.Join(found, ", "))
+}
+
+// decodeQuizFile decodes a config or question file into v. YAML and TOML
+// are converted to JSON first so every format follows the same field
+// names and rules as the JSON files. Errors start with the line number.
+func decodeQuizFile(name string, data []byte, v interface{}) error {
+	ext := strings.ToLower(filepath.Ext(name))
+	jsonData, err := toJSON(ext, data)
+	if err != nil {
+		return err
+	}
+	return unmarshalQuizJSON(ext, data, jsonData, v)
+}
+
+// toJSON converts YAML or TOML source to JSON; JSON is returned as is
+func toJSON(ext string, data []byte) ([]byte, error) {
+	var value interface{}
+	switch ext {
+	case ".json":
+		return data, nil
+	case ".yaml", ".yml":
+		err := yaml.Unmarshal(data, &value)
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			// Such as keys given twice, one "line N: ..." per problem
+			return nil, errors.New(strings.Join(typeErr.Errors, "; "))
+		} else if err != nil {
+			return nil, errors.New(strings.TrimPrefix(err.Error(), "yaml: "))
+		}
+	case ".toml":
+		var table map[string]interface{}
+		if _, err := toml.Decode(string(data), &table); err != nil {
+			return nil, errors.New(strings.TrimPrefix(err.Error(), "toml: "))
+		}
+		value = table
+	default:
+		return nil, fmt.Errorf("unsupported file type %q", ext)
+	}
+
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		// yaml.v3 decodes mappings with non-string keys into types
+		// that JSON cannot hold
+		return nil, fmt.Errorf("keys must be text: %v", err)
+	}
+	return jsonData, nil
+}
+
+// unmarshalQuizJSON decodes jsonData, converted from source, into v and
+// reports problems at their line in source
+func unmarshalQuizJSON(ext string, source, jsonData []byte, v interface{}) error {
+	err := json.Unmarshal(jsonData, v)
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &syntaxErr):
+		// Only JSON source can have JSON syntax errors
+		return fmt.Errorf("line %d: %v", offsetLine(source, syntaxErr.Offset), err)
+	case errors.As(err, &typeErr):
+		line := 0
+		if ext == ".json" {
+			line = offsetLine(source, typeErr.Offset)
+		} else {
+			line = keyLine(source, typeErr.Field)
+		}
+		field := typeErr.Field
+		if field == "" {
+			field = "file"
+		}
+		message := fmt.Sprintf("%s: expected %s, got %s", field, typeErr.Type, typeErr.Value)
+		if line == 0 {
+			return errors.New(message)
+		}
+		return fmt.Errorf("line %d: %s", line, message)
+	}
+	return err
+}
+
+// offsetLine turns a byte offset into a 1-based line number
+func offsetLine(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+// keyLine finds the line where the last part of a dotted field path is set
+// in YAML or TOML source, or 0 if it cannot be found
+func keyLine(data []byte, field string) int {
+	if field == "" {
+		return 0
+	}
+	key := regexp.QuoteMeta(field[strings.LastIndex(field, ".")+1:])
+	pattern := regexp.MustCompile(`(?i)^\s*(?:-\s+)?["']?` + key + `["']?\s*[:=]`)
+	for i, line := range strings.Split(string(data), "\n") {
+		if pattern.MatchString(line) {
+			return i + 1
+		}
+	}
+	return 0
+}
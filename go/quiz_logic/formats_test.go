@@ -0,0 +1,155 @@
+package quiz_logic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeQuizFiles creates a quiz directory holding the given files
+func writeQuizFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoadConfig_Formats(t *testing.T) {
+	jsonConfig := `{
+    "title": "Formats",
+    "timeLimit": 10,
+    "passingScore": 70,
+    "questions": [["q1", "q2"], ["q3"]],
+    "settings": {"showTimer": true, "shuffleOptions": true}
+}`
+	yamlConfig := `# Comments are allowed
+title: Formats
+timeLimit: 10
+passingScore: 70
+questions:
+  - [q1, q2]
+  - [q3]
+settings:
+  showTimer: true
+  shuffleOptions: true
+`
+	tomlConfig := `# Comments are allowed
+title = "Formats"
+timeLimit = 10
+passingScore = 70
+questions = [["q1", "q2"], ["q3"]]
+
+[settings]
+showTimer = true
+shuffleOptions = true
+`
+
+	want, err := LoadConfig(writeQuizFiles(t, map[string]string{"config.json": jsonConfig}))
+	if err != nil {
+		t.Fatalf("LoadConfig() JSON error = %v", err)
+	}
+
+	for name, content := range map[string]string{"config.yaml": yamlConfig, "config.yml": yamlConfig, "config.toml": tomlConfig} {
+		t.Run(name, func(t *testing.T) {
+			got, err := LoadConfig(writeQuizFiles(t, map[string]string{name: content}))
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("LoadConfig() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"Missing", map[string]string{}, "no config.json"},
+		{"Two configs", map[string]string{"config.json": "{}", "config.yaml": ""}, "more than one config file"},
+		{"JSON syntax", map[string]string{"config.json": "{\n  \"title\": \"x\",\n}"}, "config.json: line 3"},
+		{"JSON type", map[string]string{"config.json": "{\n  \"title\": \"x\",\n  \"timeLimit\": \"soon\"\n}"}, "line 3: timeLimit: expected int, got string"},
+		{"YAML syntax", map[string]string{"config.yaml": "title: x\ntimeLimit: 5\n  settings: x\n"}, "config.yaml: line 3"},
+		{"YAML duplicate key", map[string]string{"config.yaml": "title: x\ntitle: y\n"}, "config.yaml: line 2: mapping key \"title\" already defined"},
+		{"YAML type", map[string]string{"config.yaml": "title: x\nsettings:\n  showTimer: sometimes\n"}, "line 3: settings.showTimer: expected bool, got string"},
+		{"TOML syntax", map[string]string{"config.toml": "title = \"x\"\ntimeLimit = five\n"}, "config.toml: line 2"},
+		{"TOML type", map[string]string{"config.toml": "title = \"x\"\n\npassingScore = \"high\"\n"}, "line 3: passingScore: expected int, got string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(writeQuizFiles(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadConfig() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadQuestionBank_Formats(t *testing.T) {
+	dir := writeQuizFiles(t, map[string]string{
+		"config.yaml": "title: Formats\nquestions: [[q1], [q2], [q3]]\n",
+		"q1.json":     `{"question": "Pick one", "type": "multiple_choice", "options": ["a", "b"], "answers": ["b"], "points": 2}`,
+		"q2.yaml": `question: |
+  A long question
+  over two lines
+type: fill_in_blank
+answers: [Go]
+tags: [languages]
+`,
+		"q3.toml":   "question = \"\"\"\nTrue or false?\"\"\"\ntype = \"true_false\"\nanswers = [\"true\"]\n",
+		"README.md": "Notes for authors are ignored",
+	})
+
+	bank, err := loadQuestionBank(dir)
+	if err != nil {
+		t.Fatalf("loadQuestionBank() error = %v", err)
+	}
+	if len(bank) != 3 {
+		t.Fatalf("Expected 3 questions, got %d", len(bank))
+	}
+
+	if bank["q1"].getPoints() != 2 || !bank["q1"].checkAnswer("b") {
+		t.Errorf("Unexpected JSON question %+v", bank["q1"])
+	}
+	if bank["q2"].getQuestion() != "A long question\nover two lines\n" || !bank["q2"].checkAnswer("go") {
+		t.Errorf("Unexpected YAML question %+v", bank["q2"])
+	}
+	if !reflect.DeepEqual(bank["q2"].getTags(), []string{"languages"}) {
+		t.Errorf("Expected YAML tags, got %v", bank["q2"].getTags())
+	}
+	if bank["q3"].getType() != "true_false" || !bank["q3"].checkAnswer("true") {
+		t.Errorf("Unexpected TOML question %+v", bank["q3"])
+	}
+}
+
+func TestLoadQuestionBank_FormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{"Same ID twice", map[string]string{"q1.json": `{"question": "x", "type": "true_false", "answers": ["true"]}`, "q1.yaml": "question: x\ntype: true_false\nanswers: [true]\n"}, "q1 is defined by more than one file"},
+		{"YAML syntax", map[string]string{"q1.yaml": "question: x\n  type: y\n"}, "q1.yaml: line 2"},
+		{"YAML not a mapping", map[string]string{"q1.yml": "- a\n- b\n"}, "q1.yml: file: expected map"},
+		{"Invalid question", map[string]string{"q1.toml": "question = \"x\"\ntype = \"true_false\"\nanswers = [\"maybe\"]\n"}, "q1.toml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadQuestionBank(writeQuizFiles(t, tt.files))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("loadQuestionBank() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,334 @@
+package quiz_logic
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"quiz/quizpb"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// GRPCServer serves quizpb.QuizService, the gRPC form of the routes of
+// NewWebHandler, for services that list quizzes and take them on a
+// learner's behalf. Finished attempts are recorded at resultsPath, under
+// the learner's profile from those kept at profilesPath when they have one.
+// Serve it with GRPCInstructorOnly, which keeps results and profiles to
+// instructors.
+type GRPCServer struct {
+	quizpb.UnimplementedQuizServiceServer
+
+	catalog      *Catalog
+	resultsPath  string
+	profilesPath string
+	sessions     *sessionStore
+}
+
+// NewGRPCServer serves the quizzes in catalog; register it with
+// quizpb.RegisterQuizServiceServer
+func NewGRPCServer(catalog *Catalog, resultsPath, profilesPath string) *GRPCServer {
+	return &GRPCServer{
+		catalog:      catalog,
+		resultsPath:  resultsPath,
+		profilesPath: profilesPath,
+		sessions:     newSessionStore(catalog, resultsPath, profilesPath),
+	}
+}
+
+// GRPCInstructorOnly is the gRPC form of the instructor token of
+// NewWebHandler: a unary interceptor that answers ListResults, and
+// StartSession for a learner_id, only for calls with the metadata
+// "authorization: Bearer <token>" for token. With an empty token it answers
+// none of them.
+func GRPCInstructorOnly(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		needsToken := false
+		switch request := request.(type) {
+		case *quizpb.ListResultsRequest:
+			needsToken = true
+		case *quizpb.StartSessionRequest:
+			needsToken = request.LearnerId != ""
+		}
+		if !needsToken {
+			return handler(ctx, request)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, value := range md.Get("authorization") {
+			given, ok := strings.CutPrefix(value, "Bearer ")
+			if token != "" && ok && subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1 {
+				return handler(ctx, request)
+			}
+		}
+		return nil, status.Error(codes.Unauthenticated, "this needs the instructor token")
+	}
+}
+
+func (s *GRPCServer) ListQuizzes(ctx context.Context, request *quizpb.ListQuizzesRequest) (*quizpb.ListQuizzesResponse, error) {
+	if request.MaxMinutes < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_minutes must not be negative")
+	}
+	quizzes, problems := s.catalog.Quizzes()
+	filter := QuizFilter{Category: request.Category, Keyword: request.Query, MaxDuration: int(request.MaxMinutes)}
+
+	response := &quizpb.ListQuizzesResponse{}
+	for _, quiz := range FilterQuizzes(quizzes, filter) {
+		response.Quizzes = append(response.Quizzes, quizProto(quiz))
+	}
+	for _, problem := range problems {
+		response.Problems = append(response.Problems, &quizpb.QuizProblem{Path: problem.Path, Error: problem.Err.Error()})
+	}
+	return response, nil
+}
+
+func (s *GRPCServer) GetQuiz(ctx context.Context, request *quizpb.GetQuizRequest) (*quizpb.Quiz, error) {
+	quizzes, _ := s.catalog.Quizzes()
+	for _, quiz := range quizzes {
+		if strings.EqualFold(quiz.ID, request.Id) {
+			return quizProto(quiz), nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no quiz with ID %s", request.Id)
+}
+
+func (s *GRPCServer) StartSession(ctx context.Context, request *quizpb.StartSessionRequest) (*quizpb.Session, error) {
+	learner, err := s.learner(request)
+	if err != nil {
+		return nil, err
+	}
+	id, attempt, err := s.sessions.open(request.QuizId, learner, request.Seed)
+	if errors.Is(err, errNoQuiz) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+	return sessionProto(s.sessions.state(id, attempt)), nil
+}
+
+// learner finds who a session is for: the profile with learner_id, which
+// must exist, or else the profile learner names, if any. Services act for
+// learners already, so nothing else is asked for.
+func (s *GRPCServer) learner(request *quizpb.StartSessionRequest) (Profile, error) {
+	if request.LearnerId == "" {
+		profile, err := LookupProfile(s.profilesPath, request.Learner)
+		if err != nil {
+			return Profile{}, status.Error(codes.Internal, err.Error())
+		}
+		return profile, nil
+	}
+	profile, ok, err := profileByID(s.profilesPath, request.LearnerId)
+	if err != nil {
+		return Profile{}, status.Error(codes.Internal, err.Error())
+	} else if !ok {
+		return Profile{}, status.Errorf(codes.NotFound, "no profile with ID %s", request.LearnerId)
+	}
+	return profile, nil
+}
+
+func (s *GRPCServer) GetSession(ctx context.Context, request *quizpb.GetSessionRequest) (*quizpb.Session, error) {
+	attempt, err := s.session(request.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+	if err := s.sessions.endIfOver(attempt); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return sessionProto(s.sessions.state(request.SessionId, attempt)), nil
+}
+
+func (s *GRPCServer) SubmitAnswer(ctx context.Context, request *quizpb.SubmitAnswerRequest) (*quizpb.Session, error) {
+	attempt, err := s.session(request.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+
+	feedback, err := s.sessions.submit(attempt, request.Answer)
+	if errors.Is(err, errCannotSkip) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	state := s.sessions.state(request.SessionId, attempt)
+	state.Feedback = feedback
+	return sessionProto(state), nil
+}
+
+func (s *GRPCServer) FinishSession(ctx context.Context, request *quizpb.FinishSessionRequest) (*quizpb.Session, error) {
+	attempt, err := s.session(request.SessionId)
+	if err != nil {
+		return nil, err
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+	if err := s.sessions.end(attempt); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return sessionProto(s.sessions.state(request.SessionId, attempt)), nil
+}
+
+func (s *GRPCServer) WatchTimer(request *quizpb.WatchTimerRequest, stream quizpb.QuizService_WatchTimerServer) error {
+	attempt, err := s.session(request.SessionId)
+	if err != nil {
+		return err
+	}
+	interval := time.Second
+	if request.Interval != nil {
+		interval = request.Interval.AsDuration()
+		if err := request.Interval.CheckValid(); err != nil || interval <= 0 {
+			return status.Error(codes.InvalidArgument, "interval must be positive")
+		}
+	}
+
+	for {
+		event, left, err := s.timerEvent(attempt)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(event); err != nil {
+			return err
+		}
+		if event.Result != nil {
+			return nil
+		}
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-time.After(min(interval, left)):
+		}
+	}
+}
+
+// timerEvent reports the time left in the attempt, ending it when the time
+// is up
+func (s *GRPCServer) timerEvent(attempt *storedSession) (*quizpb.TimerEvent, time.Duration, error) {
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+	left, limit, ok := attempt.session.TimeLeft()
+	if !ok {
+		return nil, 0, status.Error(codes.FailedPrecondition, "this quiz shows no timer")
+	}
+	timeUp := attempt.result == nil && attempt.session.TimeUp()
+	if err := s.sessions.endIfOver(attempt); err != nil {
+		return nil, 0, status.Error(codes.Internal, err.Error())
+	}
+
+	event := &quizpb.TimerEvent{
+		TimeLeft:  durationpb.New(left),
+		TimeLimit: durationpb.New(limit),
+		TimeUp:    timeUp,
+	}
+	if attempt.result != nil {
+		event.Result = resultProto(*attempt.result)
+	}
+	return event, left, nil
+}
+
+func (s *GRPCServer) ListResults(ctx context.Context, request *quizpb.ListResultsRequest) (*quizpb.ListResultsResponse, error) {
+	results, err := LoadResults(s.resultsPath)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	response := &quizpb.ListResultsResponse{}
+	for _, result := range FilterResults(results, request.QuizId, request.Learner) {
+		response.Results = append(response.Results, resultProto(result))
+	}
+	return response, nil
+}
+
+func (s *GRPCServer) session(id string) (*storedSession, error) {
+	attempt, ok := s.sessions.find(id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "no quiz session %s", id)
+	}
+	return attempt, nil
+}
+
+func quizProto(quiz QuizInfo) *quizpb.Quiz {
+	return &quizpb.Quiz{
+		Id:               quiz.ID,
+		Title:            quiz.Title,
+		Category:         quiz.Category,
+		Description:      quiz.Description,
+		Difficulty:       quiz.Difficulty,
+		EstimatedMinutes: int32(quiz.Duration),
+		Tags:             quiz.Tags,
+	}
+}
+
+func sessionProto(state webState) *quizpb.Session {
+	session := &quizpb.Session{
+		Id:            state.ID,
+		Title:         state.Title,
+		AllowSkipping: state.AllowSkipping,
+		ShowFeedback:  state.ShowFeedback,
+		Done:          int32(state.Done),
+		Total:         int32(state.Total),
+	}
+	if state.TimeLeft != nil {
+		session.TimeLeft = durationpb.New(seconds(*state.TimeLeft))
+		session.TimeLimit = durationpb.New(seconds(*state.TimeLimit))
+	}
+	if question := state.Question; question != nil {
+		session.Question = &quizpb.Question{
+			Id:      question.ID,
+			Number:  int32(question.Number),
+			Text:    question.Text,
+			Type:    question.Type,
+			Options: question.Options,
+		}
+	}
+	if state.Result != nil {
+		session.Result = resultProto(*state.Result)
+	}
+	if state.Feedback != nil {
+		session.Feedback = &quizpb.Feedback{Correct: state.Feedback.Correct, Skipped: state.Feedback.Skipped}
+	}
+	return session
+}
+
+func resultProto(result Result) *quizpb.Result {
+	message := &quizpb.Result{
+		QuizId:    result.QuizID,
+		Title:     result.Title,
+		Learner:   result.Learner,
+		LearnerId: result.LearnerID,
+		Group:     result.Group,
+		Finished:  timestamppb.New(result.Finished),
+		Seed:      result.Seed,
+		Correct:   int32(result.Correct),
+		Total:     int32(result.Total),
+		Score:     int32(result.Score),
+		Passed:    result.Passed,
+		TimeTaken: durationpb.New(seconds(result.Seconds)),
+	}
+	for _, question := range result.Questions {
+		message.Questions = append(message.Questions, &quizpb.QuestionResult{
+			Id:        question.ID,
+			Question:  question.Question,
+			Answer:    question.Answer,
+			Expected:  question.Expected,
+			Correct:   question.Correct,
+			Skipped:   question.Skipped,
+			Points:    int32(question.Points),
+			MaxPoints: int32(question.MaxPoints),
+			TimeTaken: durationpb.New(seconds(question.Seconds)),
+		})
+	}
+	return message
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,191 @@
+package quiz_logic
+
+import (
+	"context"
+	"io"
+	"net"
+	"path/filepath"
+	"quiz/quizpb"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newTestGRPCClient(t *testing.T, settings string) (quizpb.QuizServiceClient, *GRPCServer) {
+	t.Helper()
+	resultsPath := filepath.Join(t.TempDir(), "results.jsonl")
+	service := NewGRPCServer(newTestCatalog(t, settings), resultsPath, newTestProfiles(t))
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(GRPCInstructorOnly(testInstructorToken)))
+	quizpb.RegisterQuizServiceServer(server, service)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return quizpb.NewQuizServiceClient(conn), service
+}
+
+// asInstructor adds the instructor token to the calls made with ctx
+func asInstructor(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+func wantCode(t *testing.T, err error, code codes.Code) {
+	t.Helper()
+	if status.Code(err) != code {
+		t.Errorf("error = %v, want %v", err, code)
+	}
+}
+
+func TestGRPCServer_TakeQuiz(t *testing.T) {
+	client, _ := newTestGRPCClient(t, `{"showFeedbackAfterEach": true}`)
+	ctx := context.Background()
+
+	list, err := client.ListQuizzes(ctx, &quizpb.ListQuizzesRequest{})
+	if err != nil || len(list.Quizzes) != 1 || list.Quizzes[0].Id != "capitals" {
+		t.Fatalf("ListQuizzes() = %v, %v", list, err)
+	}
+	if list, _ := client.ListQuizzes(ctx, &quizpb.ListQuizzesRequest{Query: "physics"}); len(list.Quizzes) != 0 {
+		t.Errorf("ListQuizzes(physics) = %v, want none", list.Quizzes)
+	}
+	if quiz, err := client.GetQuiz(ctx, &quizpb.GetQuizRequest{Id: "CAPITALS"}); err != nil || quiz.Title != "Capitals" {
+		t.Errorf("GetQuiz() = %v, %v", quiz, err)
+	}
+	_, err = client.GetQuiz(ctx, &quizpb.GetQuizRequest{Id: "nope"})
+	wantCode(t, err, codes.NotFound)
+	_, err = client.StartSession(ctx, &quizpb.StartSessionRequest{QuizId: "nope"})
+	wantCode(t, err, codes.NotFound)
+
+	session, err := client.StartSession(ctx, &quizpb.StartSessionRequest{QuizId: "capitals", Learner: "ada", Seed: 7})
+	if err != nil || session.Question == nil || session.Total != 2 || session.TimeLeft != nil {
+		t.Fatalf("StartSession() = %v, %v", session, err)
+	}
+	_, err = client.SubmitAnswer(ctx, &quizpb.SubmitAnswerRequest{SessionId: session.Id})
+	wantCode(t, err, codes.InvalidArgument)
+	stream, err := client.WatchTimer(ctx, &quizpb.WatchTimerRequest{SessionId: session.Id})
+	if err == nil {
+		_, err = stream.Recv() // errors of a stream come with its first message
+	}
+	wantCode(t, err, codes.FailedPrecondition)
+
+	answers := map[string]string{"multiple_choice": "Paris", "fill_in_blank": "Rome"}
+	for session.Question != nil {
+		if got, err := client.GetSession(ctx, &quizpb.GetSessionRequest{SessionId: session.Id}); err != nil || got.Question.Id != session.Question.Id {
+			t.Fatalf("GetSession() = %v, %v", got, err)
+		}
+		session, err = client.SubmitAnswer(ctx, &quizpb.SubmitAnswerRequest{SessionId: session.Id, Answer: answers[session.Question.Type]})
+		if err != nil || session.Feedback == nil || !session.Feedback.Correct {
+			t.Fatalf("SubmitAnswer() = %v, %v", session, err)
+		}
+	}
+	result := session.Result
+	if result == nil || result.Learner != "ada" || result.Seed != 7 || result.Correct != 2 || result.Score != 100 || len(result.Questions) != 2 {
+		t.Fatalf("Result = %v", result)
+	}
+
+	_, err = client.ListResults(ctx, &quizpb.ListResultsRequest{})
+	wantCode(t, err, codes.Unauthenticated)
+	_, err = client.ListResults(asInstructor(ctx, "student"), &quizpb.ListResultsRequest{})
+	wantCode(t, err, codes.Unauthenticated)
+	instructor := asInstructor(ctx, testInstructorToken)
+	results, err := client.ListResults(instructor, &quizpb.ListResultsRequest{Learner: "ADA"})
+	if err != nil || len(results.Results) != 1 || results.Results[0].QuizId != "capitals" {
+		t.Errorf("ListResults() = %v, %v", results, err)
+	}
+	if results, _ := client.ListResults(instructor, &quizpb.ListResultsRequest{QuizId: "other"}); len(results.Results) != 0 {
+		t.Errorf("ListResults(other quiz) = %v, want none", results.Results)
+	}
+}
+
+func TestGRPCServer_Profiles(t *testing.T) {
+	client, _ := newTestGRPCClient(t, `{}`)
+	ctx := context.Background()
+
+	_, err := client.StartSession(ctx, &quizpb.StartSessionRequest{QuizId: "capitals", LearnerId: "grace"})
+	wantCode(t, err, codes.Unauthenticated)
+	ctx = asInstructor(ctx, testInstructorToken)
+	_, err = client.StartSession(ctx, &quizpb.StartSessionRequest{QuizId: "capitals", LearnerId: "ada"})
+	wantCode(t, err, codes.NotFound)
+
+	requests := []*quizpb.StartSessionRequest{
+		{QuizId: "capitals", LearnerId: "GRACE"},
+		{QuizId: "capitals", Learner: "Grace Hopper"},
+	}
+	for _, request := range requests {
+		session, err := client.StartSession(ctx, request)
+		if err != nil {
+			t.Fatalf("StartSession(%v) error = %v", request, err)
+		}
+		session, err = client.FinishSession(ctx, &quizpb.FinishSessionRequest{SessionId: session.Id})
+		if err != nil || session.Result == nil {
+			t.Fatalf("FinishSession() = %v, %v", session, err)
+		}
+		if result := session.Result; result.Learner != "Grace Hopper" || result.LearnerId != "grace" || result.Group != "navy" {
+			t.Errorf("Result of %v = %v, want it under grace's profile", request, result)
+		}
+	}
+}
+
+func TestGRPCServer_WatchTimer(t *testing.T) {
+	client, service := newTestGRPCClient(t, `{"showTimer": true}`)
+	ctx := context.Background()
+
+	session, err := client.StartSession(ctx, &quizpb.StartSessionRequest{QuizId: "capitals"})
+	if err != nil {
+		t.Fatalf("StartSession() error = %v", err)
+	}
+	_, err = client.FinishSession(ctx, &quizpb.FinishSessionRequest{SessionId: "nope"})
+	wantCode(t, err, codes.NotFound)
+
+	// The test quiz has no time limit, so give this attempt one that has
+	// all but run out
+	attempt, _ := service.sessions.find(session.Id)
+	attempt.mu.Lock()
+	attempt.quiz.Config.TimeLimit = 1
+	attempt.quiz.startTime = time.Now().Add(-time.Minute + 50*time.Millisecond)
+	attempt.mu.Unlock()
+
+	stream, err := client.WatchTimer(ctx, &quizpb.WatchTimerRequest{SessionId: session.Id, Interval: durationpb.New(10 * time.Millisecond)})
+	if err != nil {
+		t.Fatalf("WatchTimer() error = %v", err)
+	}
+	var events []*quizpb.TimerEvent
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		events = append(events, event)
+	}
+
+	first, last := events[0], events[len(events)-1]
+	if first.TimeUp || first.Result != nil || first.TimeLimit.AsDuration() != time.Minute || first.TimeLeft.AsDuration() > 50*time.Millisecond {
+		t.Errorf("First event = %v", first)
+	}
+	if !last.TimeUp || last.Result == nil || last.Result.Total != 2 || !last.Result.Questions[0].Skipped {
+		t.Errorf("Last event = %v, want the time up and the result", last)
+	}
+	if len(events) < 3 {
+		t.Errorf("Got %d events, want one every 10ms", len(events))
+	}
+
+	// The time ran out, so the session is over
+	if session, err := client.GetSession(ctx, &quizpb.GetSessionRequest{SessionId: session.Id}); err != nil || session.Question != nil || session.Result == nil {
+		t.Errorf("GetSession() after the time ran out = %v, %v", session, err)
+	}
+}
@@ -1,40 +1,93 @@
 package quiz_logic
 
 import (
-	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io/fs"
+	"path"
 )
 
+// LoadConfig reads the configuration of the quiz directory, Markdown quiz
+// file or quiz bundle at quizPath. A directory's config may be JSON, YAML
+// or TOML.
 func LoadConfig(quizPath string) (Config, error) {
+	return LoadConfigFS(osQuiz(quizPath))
+}
+
+// LoadConfigFS reads the configuration of the quiz called name in fsys, for
+// quiz banks that are embedded in the binary or not on disk at all
+func LoadConfigFS(fsys fs.FS, name string) (Config, error) {
+	fsys, name, err := openQuiz(fsys, name)
+	if err != nil {
+		return Config{}, err
+	}
+	return loadConfig(fsys, name)
+}
+
+// loadConfig reads the configuration of the quiz called name in fsys
+func loadConfig(fsys fs.FS, name string) (Config, error) {
+	if isMarkdownQuiz(name) {
+		config, _, err := loadMarkdownQuiz(fsys, name)
+		return config, err
+	}
+
 	var config Config
-	configPath := filepath.Join(quizPath, "config.json")
-	data, err := os.ReadFile(configPath)
+	configName, err := findConfigFile(fsys, name)
+	if err != nil {
+		return config, fmt.Errorf("error reading config: %v", err)
+	}
+	data, err := fs.ReadFile(fsys, path.Join(name, configName))
 	if err != nil {
 		return config, fmt.Errorf("error reading config: %v", err)
 	}
 
-	err = json.Unmarshal(data, &config)
+	err = decodeQuizFile(configName, data, &config)
 	if err != nil {
-		return config, fmt.Errorf("error parsing config: %v", err)
+		return config, fmt.Errorf("error parsing config: %s: %v", configName, err)
 	}
 
 	return config, nil
 }
 
-func StartQuiz(quizPath string) error {
-	config, err := LoadConfig(quizPath)
+// StartQuiz loads and runs the quiz at quizPath. A non-zero seed overrides
+// the seed from the quiz config.
+func StartQuiz(quizPath string, seed int64) error {
+	fsys, name := osQuiz(quizPath)
+	return StartQuizFS(fsys, name, seed)
+}
+
+// StartQuizFS loads and runs the quiz called name in fsys
+func StartQuizFS(fsys fs.FS, name string, seed int64) error {
+	quiz, err := LoadQuizFS(fsys, name, seed)
 	if err != nil {
-		return fmt.Errorf("error loading config: %v", err)
+		return err
 	}
+	quiz.Run()
+	return nil
+}
 
-	quiz := Quiz{Config: config}
-	err = quiz.selectQuestions(quizPath)
+// LoadQuizFS loads the quiz called name in fsys and picks its form without
+// running it, so the caller can set up the attempt first
+func LoadQuizFS(fsys fs.FS, name string, seed int64) (*Quiz, error) {
+	config, err := LoadConfigFS(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("error loading config: %v", err)
+	}
+	bank, err := loadQuestionBankFS(fsys, name)
 	if err != nil {
-		return fmt.Errorf("error loading questions: %v", err)
+		return nil, fmt.Errorf("error loading questions: %v", err)
 	}
+	id := config.ID
+	if id == "" {
+		id = quizSlug(name)
+	}
+	return newQuiz(id, config, bank, seed)
+}
 
-	quiz.Run()
-	return nil
+// newQuiz picks a form from a loaded question bank
+func newQuiz(id string, config Config, bank map[string]Question, seed int64) (*Quiz, error) {
+	quiz := &Quiz{ID: id, Config: config, Seed: seed}
+	if err := quiz.pickQuestions(bank); err != nil {
+		return nil, fmt.Errorf("error loading questions: %v", err)
+	}
+	return quiz, nil
 }
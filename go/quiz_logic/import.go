@@ -0,0 +1,240 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Importers maps import format names to their converters
+var Importers = map[string]func(io.Reader) (*ImportResult, error){
+	"gift":   ImportGIFT,
+	"aiken":  ImportAiken,
+	"moodle": ImportMoodleXML,
+	"qti":    ImportQTI,
+	"csv":    ImportCSV,
+}
+
+// importExtensions guesses the import format from a file extension
+var importExtensions = map[string]string{
+	".gift":  "gift",
+	".aiken": "aiken",
+	".txt":   "aiken",
+	".xml":   "moodle",
+	".zip":   "qti",
+	".csv":   "csv",
+}
+
+// QuestionFile is the on-disk form of a question, as read by createQuestion
+type QuestionFile struct {
+	ID             string   `json:"-"` // file name without extension, if known
+	Question       string   `json:"question"`
+	Type           string   `json:"type"`
+	Options        []string `json:"options,omitempty"`
+	Answers        []string `json:"answers"`
+	ShuffleOptions *bool    `json:"shuffleOptions,omitempty"`
+	PinnedOptions  []string `json:"pinnedOptions,omitempty"`
+	Tags           []string `json:"tags,omitempty"`
+	Points         int      `json:"points,omitempty"`
+}
+
+// ImportResult is a converted quiz plus anything that could not be converted
+type ImportResult struct {
+	Config    Config
+	Questions []QuestionFile
+	Problems  []string
+}
+
+// ImportFile converts the quiz file at path. An empty format is guessed
+// from the file extension; a quiz bundle is unpacked as it is, even though
+// its .zip extension would otherwise mean QTI. The quiz title defaults to
+// the file name.
+func ImportFile(path, format string) (*ImportResult, error) {
+	ext := filepath.Ext(path)
+	if format == "" && isBundle(path) || format == "bundle" {
+		config, questions, err := LoadQuizFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		return &ImportResult{Config: config, Questions: questions}, nil
+	}
+	if format == "" {
+		format = importExtensions[strings.ToLower(ext)]
+	}
+	importer, ok := Importers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown import format for %s, use one of: gift, aiken, moodle, qti, csv, bundle", filepath.Base(path))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %v", path, err)
+	}
+	defer f.Close()
+
+	result, err := importer(f)
+	if err != nil {
+		return nil, err
+	}
+	if result.Config.Title == "" {
+		result.Config.Title = strings.TrimSuffix(filepath.Base(path), ext)
+	}
+	return result, nil
+}
+
+// addQuestion keeps the question if createQuestion accepts it and records
+// a problem otherwise. source names the question in problem reports.
+func (r *ImportResult) addQuestion(question QuestionFile, source string) {
+	if err := validateQuestionFile(question); err != nil {
+		r.addProblem(source, "%v", err)
+		return
+	}
+	r.Questions = append(r.Questions, question)
+}
+
+func (r *ImportResult) addProblem(source string, format string, args ...interface{}) {
+	r.Problems = append(r.Problems, source+": "+fmt.Sprintf(format, args...))
+}
+
+// validateQuestionFile checks a question the same way loading it would
+func validateQuestionFile(question QuestionFile) error {
+	_, err := question.toQuestion()
+	return err
+}
+
+// toQuestion builds the question through createQuestion, exactly as if it
+// had been read from a question file
+func (qf QuestionFile) toQuestion() (Question, error) {
+	data, err := json.Marshal(qf)
+	if err != nil {
+		return nil, err
+	}
+
+	var questionData map[string]interface{}
+	if err := json.Unmarshal(data, &questionData); err != nil {
+		return nil, err
+	}
+	if qf.ID != "" {
+		questionData["id"] = qf.ID
+	}
+	return createQuestion(questionData)
+}
+
+var safeQuestionID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// assignQuestionIDs keeps names that are usable as unique file names as
+// question IDs and clears the rest, so WriteQuizDir numbers them instead
+func assignQuestionIDs(questions []QuestionFile) {
+	seen := make(map[string]bool)
+	for i := range questions {
+		id := questions[i].ID
+		if !safeQuestionID.MatchString(id) || seen[strings.ToLower(id)] || strings.EqualFold(id, "config") {
+			questions[i].ID = ""
+			continue
+		}
+		seen[strings.ToLower(id)] = true
+	}
+}
+
+// LoadQuizFiles reads a quiz directory in its on-disk form, with questions
+// sorted by ID, for converting it to other formats
+func LoadQuizFiles(quizPath string) (Config, []QuestionFile, error) {
+	config, err := LoadConfig(quizPath)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading config: %v", err)
+	}
+
+	bank, err := loadQuestionBank(quizPath)
+	if err != nil {
+		return config, nil, fmt.Errorf("error loading questions: %v", err)
+	}
+
+	var questions []QuestionFile
+	for _, question := range bank {
+		questions = append(questions, toQuestionFile(question))
+	}
+	sort.Slice(questions, func(i, j int) bool {
+		return questions[i].ID < questions[j].ID
+	})
+
+	return config, questions, nil
+}
+
+// toQuestionFile turns a loaded question back into its on-disk form
+func toQuestionFile(question Question) QuestionFile {
+	file := QuestionFile{
+		ID:       question.getID(),
+		Question: question.getQuestion(),
+		Type:     question.getType(),
+		Answers:  question.getAnswers(),
+		Tags:     question.getTags(),
+	}
+	if points := question.getPoints(); points != 1 {
+		file.Points = points
+	}
+	if mcq, ok := question.(*MultipleChoiceQuestion); ok {
+		file.Options = mcq.Options
+		file.ShuffleOptions = mcq.ShuffleOptions
+		file.PinnedOptions = mcq.PinnedOptions
+	}
+	return file
+}
+
+// WriteQuizDir writes config.json and one JSON file per question to dir,
+// named by the question ID or questionNNN when it has none. Without
+// question sets in the config, every question gets its own set.
+func WriteQuizDir(dir string, config Config, questions []QuestionFile) error {
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("output directory %s is not empty", dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("error creating quiz directory: %v", err)
+	}
+
+	// Questions without an ID are numbered, skipping IDs already taken
+	taken := make(map[string]bool)
+	for _, question := range questions {
+		taken[question.ID] = true
+	}
+	next := 0
+	newID := func() string {
+		for {
+			next++
+			if id := fmt.Sprintf("question%03d", next); !taken[id] {
+				return id
+			}
+		}
+	}
+
+	setsFromFiles := len(config.Questions) == 0
+	for _, question := range questions {
+		id := question.ID
+		if id == "" {
+			id = newID()
+		}
+		if err := writeJSONFile(filepath.Join(dir, id+".json"), question); err != nil {
+			return err
+		}
+		if setsFromFiles {
+			config.Questions = append(config.Questions, []string{id})
+		}
+	}
+
+	return writeJSONFile(filepath.Join(dir, "config.json"), config)
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error encoding %s: %v", filepath.Base(path), err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", filepath.Base(path), err)
+	}
+	return nil
+}
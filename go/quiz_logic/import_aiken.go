@@ -0,0 +1,91 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	aikenOption = regexp.MustCompile(`^([A-Z])[.)]\s+(.*)$`)
+	aikenAnswer = regexp.MustCompile(`^ANSWER:\s*([A-Z])\s*$`)
+)
+
+// ImportAiken converts a Moodle Aiken file, which only holds multiple
+// choice questions with a single correct answer
+func ImportAiken(r io.Reader) (*ImportResult, error) {
+	result := &ImportResult{}
+
+	var text []string
+	var labels, options []string
+	number := 0
+
+	reset := func() {
+		text, labels, options = nil, nil, nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if answer := aikenAnswer.FindStringSubmatch(line); answer != nil {
+			number++
+			source := fmt.Sprintf("question %d", number)
+
+			correct := -1
+			for i, label := range labels {
+				if label == answer[1] {
+					correct = i
+				}
+			}
+
+			switch {
+			case len(text) == 0:
+				result.addProblem(source, "answer line without a question")
+			case len(options) == 0:
+				result.addProblem(source, "question has no options")
+			case correct < 0:
+				result.addProblem(source, "answer %s is not one of the options", answer[1])
+			default:
+				result.addQuestion(QuestionFile{
+					Question: strings.Join(text, " "),
+					Type:     "multiple_choice",
+					Options:  options,
+					Answers:  []string{options[correct]},
+				}, source)
+			}
+			reset()
+			continue
+		}
+
+		if option := aikenOption.FindStringSubmatch(line); option != nil && len(text) > 0 {
+			labels = append(labels, option[1])
+			options = append(options, option[2])
+			continue
+		}
+
+		if len(options) > 0 {
+			// Text after the options without an ANSWER line starts a new
+			// question; the unfinished one cannot be converted
+			number++
+			result.addProblem(fmt.Sprintf("question %d", number), "missing ANSWER line")
+			reset()
+		}
+		text = append(text, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading Aiken file: %v", err)
+	}
+
+	if len(text) > 0 {
+		number++
+		result.addProblem(fmt.Sprintf("question %d", number), "missing ANSWER line")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package quiz_logic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportAiken(t *testing.T) {
+	aiken := `What is the capital of France?
+A. London
+B. Paris
+C) Berlin
+ANSWER: B
+
+Which planet is largest?
+A. Mars
+B. Jupiter
+ANSWER: D
+
+Which language compiles to native code?
+A. Go
+B. Python
+`
+
+	result, err := ImportAiken(strings.NewReader(aiken))
+	if err != nil {
+		t.Fatalf("ImportAiken() error = %v", err)
+	}
+
+	if len(result.Questions) != 1 {
+		t.Fatalf("Expected 1 question, got %d", len(result.Questions))
+	}
+	question := result.Questions[0]
+	if question.Type != "multiple_choice" || len(question.Options) != 3 {
+		t.Errorf("Unexpected question %+v", question)
+	}
+	if len(question.Answers) != 1 || question.Answers[0] != "Paris" {
+		t.Errorf("Expected answer Paris, got %v", question.Answers)
+	}
+
+	if len(result.Problems) != 2 {
+		t.Fatalf("Expected 2 problems, got %v", result.Problems)
+	}
+	if !strings.Contains(result.Problems[0], "answer D") {
+		t.Errorf("Expected a problem about answer D, got %q", result.Problems[0])
+	}
+	if !strings.Contains(result.Problems[1], "missing ANSWER") {
+		t.Errorf("Expected a problem about the missing answer, got %q", result.Problems[1])
+	}
+}
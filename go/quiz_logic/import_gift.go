@@ -0,0 +1,259 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// giftBlank replaces the answer block of a missing word question
+const giftBlank = "_______"
+
+var (
+	giftTitle  = regexp.MustCompile(`^::((?:[^:\\]|\\.)*)::`)
+	giftFormat = regexp.MustCompile(`^\[(html|moodle|plain|markdown)\]`)
+	giftWeight = regexp.MustCompile(`^%(-?[0-9.]+)%`)
+)
+
+// ImportGIFT converts a Moodle GIFT file. Question types without an
+// equivalent here (essay, matching, numeric ranges) are reported as problems.
+func ImportGIFT(r io.Reader) (*ImportResult, error) {
+	blocks, err := splitGIFT(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading GIFT file: %v", err)
+	}
+
+	result := &ImportResult{}
+	for i, block := range blocks {
+		source := fmt.Sprintf("question %d", i+1)
+		if title := giftTitle.FindStringSubmatch(block); title != nil {
+			source = fmt.Sprintf("question %d (%s)", i+1, unescapeGIFT(title[1]))
+			block = strings.TrimSpace(block[len(title[0]):])
+		}
+		block = giftFormat.ReplaceAllString(block, "")
+
+		open, close := giftAnswerBlock(block)
+		if open < 0 {
+			result.addProblem(source, "descriptions without answers are not supported")
+			continue
+		}
+
+		before := strings.TrimSpace(block[:open])
+		after := strings.TrimSpace(block[close+1:])
+		text := unescapeGIFT(before)
+		if after != "" {
+			text = strings.TrimSpace(text + " " + giftBlank)
+			if after = unescapeGIFT(after); after != "" && !strings.ContainsAny(after[:1], ".,;:!?") {
+				text += " "
+			}
+			text += after
+		}
+
+		question, problem := parseGIFTAnswers(block[open+1 : close])
+		if problem != "" {
+			result.addProblem(source, "%s", problem)
+			if question.Type == "" {
+				continue
+			}
+		}
+		question.Question = text
+		result.addQuestion(question, source)
+	}
+
+	return result, nil
+}
+
+// splitGIFT drops comments and category lines and splits the file into
+// questions at blank lines outside answer blocks
+func splitGIFT(r io.Reader) ([]string, error) {
+	var blocks []string
+	var current []string
+	depth := 0
+
+	flush := func() {
+		if block := strings.TrimSpace(strings.Join(current, " ")); block != "" {
+			blocks = append(blocks, block)
+		}
+		current = nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "//"):
+			continue
+		case strings.HasPrefix(line, "$CATEGORY:"):
+			flush()
+			continue
+		case line == "" && depth == 0:
+			flush()
+			continue
+		}
+
+		current = append(current, line)
+		for i := 0; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+		}
+	}
+	flush()
+
+	return blocks, scanner.Err()
+}
+
+// giftAnswerBlock finds the first unescaped {...} in a question
+func giftAnswerBlock(block string) (int, int) {
+	open := -1
+	for i := 0; i < len(block); i++ {
+		switch block[i] {
+		case '\\':
+			i++
+		case '{':
+			if open < 0 {
+				open = i
+			}
+		case '}':
+			if open >= 0 {
+				return open, i
+			}
+		}
+	}
+	return -1, -1
+}
+
+// parseGIFTAnswers converts the inside of an answer block. A question with
+// an empty Type could not be converted at all; a non-empty problem with a
+// Type means something was dropped on the way.
+func parseGIFTAnswers(body string) (QuestionFile, string) {
+	body = strings.TrimSpace(body)
+
+	switch {
+	case body == "":
+		return QuestionFile{}, "essay questions are not supported"
+	case strings.HasPrefix(body, "#"):
+		return parseGIFTNumeric(body[1:])
+	}
+
+	switch value := strings.ToUpper(strings.TrimSpace(stripGIFTFeedback(body))); value {
+	case "T", "TRUE":
+		return QuestionFile{Type: "true_false", Answers: []string{"true"}}, ""
+	case "F", "FALSE":
+		return QuestionFile{Type: "true_false", Answers: []string{"false"}}, ""
+	}
+
+	var question QuestionFile
+	var problem string
+	wrong := 0
+	for _, choice := range splitGIFTChoices(body) {
+		text := stripGIFTFeedback(choice[1:])
+		if strings.Contains(text, "->") {
+			return QuestionFile{}, "matching questions are not supported"
+		}
+
+		correct := choice[0] == '='
+		if weight := giftWeight.FindStringSubmatch(text); weight != nil {
+			text = text[len(weight[0]):]
+			value, _ := strconv.ParseFloat(weight[1], 64)
+			correct = value >= 100
+			if value > 0 && value < 100 {
+				problem = "partial credit weights were dropped"
+			}
+		}
+
+		text = unescapeGIFT(strings.TrimSpace(text))
+		question.Options = append(question.Options, text)
+		if correct {
+			question.Answers = append(question.Answers, text)
+		} else {
+			wrong++
+		}
+	}
+
+	if wrong == 0 {
+		// Only right answers: a short answer question
+		question.Type = "fill_in_blank"
+		question.Options = nil
+	} else {
+		question.Type = "multiple_choice"
+	}
+	return question, problem
+}
+
+// parseGIFTNumeric accepts exact numeric answers; ranges and tolerances
+// cannot be graded by a fill in the blank question
+func parseGIFTNumeric(body string) (QuestionFile, string) {
+	var answers []string
+	for _, choice := range strings.FieldsFunc(body, func(r rune) bool { return r == '=' }) {
+		value := strings.TrimSpace(stripGIFTFeedback(choice))
+		if value == "" {
+			continue
+		}
+		if strings.Contains(value, "..") || strings.Contains(value, "%") {
+			return QuestionFile{}, "numeric ranges are not supported"
+		}
+		if number, tolerance, found := strings.Cut(value, ":"); found {
+			if t, err := strconv.ParseFloat(strings.TrimSpace(tolerance), 64); err != nil || t != 0 {
+				return QuestionFile{}, "numeric tolerances are not supported"
+			}
+			value = strings.TrimSpace(number)
+		}
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return QuestionFile{}, fmt.Sprintf("invalid numeric answer %q", value)
+		}
+		answers = append(answers, value)
+	}
+	return QuestionFile{Type: "fill_in_blank", Answers: answers}, ""
+}
+
+// splitGIFTChoices splits an answer block at unescaped = and ~, keeping the
+// marker as the first byte of each choice
+func splitGIFTChoices(body string) []string {
+	var choices []string
+	start := -1
+	for i := 0; i < len(body); i++ {
+		switch body[i] {
+		case '\\':
+			i++
+		case '=', '~':
+			if start >= 0 {
+				choices = append(choices, body[start:i])
+			}
+			start = i
+		}
+	}
+	if start >= 0 {
+		choices = append(choices, body[start:])
+	}
+	return choices
+}
+
+// stripGIFTFeedback removes "#feedback" from an answer
+func stripGIFTFeedback(answer string) string {
+	for i := 0; i < len(answer); i++ {
+		switch answer[i] {
+		case '\\':
+			i++
+		case '#':
+			return answer[:i]
+		}
+	}
+	return answer
+}
+
+var giftUnescaper = strings.NewReplacer(
+	`\~`, "~", `\=`, "=", `\#`, "#", `\{`, "{", `\}`, "}", `\:`, ":", `\n`, " ", `\\`, `\`,
+)
+
+func unescapeGIFT(text string) string {
+	return strings.TrimSpace(giftUnescaper.Replace(text))
+}
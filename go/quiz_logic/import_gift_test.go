@@ -0,0 +1,95 @@
+package quiz_logic
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImportGIFT(t *testing.T) {
+	gift := `// Sample bank
+$CATEGORY: $course$/Geography
+
+::Capital:: What is the capital of France? {
+=Paris
+~London#Not quite
+~Berlin
+}
+
+::Sun:: The sun is a star.{T}
+
+Water turning into gas is called {=evaporation =vaporization}.
+
+What is 2 + 2? {#4}
+
+::Colon:: What does \: mean in GIFT? {=an escaped colon ~nothing}
+`
+
+	result, err := ImportGIFT(strings.NewReader(gift))
+	if err != nil {
+		t.Fatalf("ImportGIFT() error = %v", err)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("Unexpected problems: %v", result.Problems)
+	}
+
+	expected := []struct {
+		text    string
+		qtype   string
+		options int
+		answers []string
+	}{
+		{"What is the capital of France?", "multiple_choice", 3, []string{"Paris"}},
+		{"The sun is a star.", "true_false", 0, []string{"true"}},
+		{"Water turning into gas is called _______.", "fill_in_blank", 0, []string{"evaporation", "vaporization"}},
+		{"What is 2 + 2?", "fill_in_blank", 0, []string{"4"}},
+		{"What does : mean in GIFT?", "multiple_choice", 2, []string{"an escaped colon"}},
+	}
+
+	if len(result.Questions) != len(expected) {
+		t.Fatalf("Expected %d questions, got %d", len(expected), len(result.Questions))
+	}
+	for i, want := range expected {
+		got := result.Questions[i]
+		if got.Question != want.text {
+			t.Errorf("Question %d: text = %q, want %q", i+1, got.Question, want.text)
+		}
+		if got.Type != want.qtype {
+			t.Errorf("Question %d: type = %q, want %q", i+1, got.Type, want.qtype)
+		}
+		if len(got.Options) != want.options {
+			t.Errorf("Question %d: %d options, want %d", i+1, len(got.Options), want.options)
+		}
+		if strings.Join(got.Answers, ",") != strings.Join(want.answers, ",") {
+			t.Errorf("Question %d: answers = %v, want %v", i+1, got.Answers, want.answers)
+		}
+	}
+}
+
+func TestImportGIFT_Unsupported(t *testing.T) {
+	tests := []struct {
+		name    string
+		gift    string
+		problem string
+	}{
+		{"Essay", "Tell me about your day. {}", "essay"},
+		{"Matching", "Match these. {=cat -> meow =dog -> woof}", "matching"},
+		{"Numeric range", "Pick a number. {#1..5}", "ranges"},
+		{"Numeric tolerance", "What is pi? {#3.14:0.01}", "tolerances"},
+		{"Description", "Just some text.", "descriptions"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ImportGIFT(strings.NewReader(tt.gift))
+			if err != nil {
+				t.Fatalf("ImportGIFT() error = %v", err)
+			}
+			if len(result.Questions) != 0 {
+				t.Errorf("Expected no questions, got %v", result.Questions)
+			}
+			if len(result.Problems) != 1 || !strings.Contains(result.Problems[0], tt.problem) {
+				t.Errorf("Expected a problem mentioning %q, got %v", tt.problem, result.Problems)
+			}
+		})
+	}
+}
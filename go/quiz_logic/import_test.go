@@ -0,0 +1,58 @@
+package quiz_logic
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteQuizDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "quiz01")
+	questions := []QuestionFile{
+		{Question: "What is the capital of France?", Type: "multiple_choice", Options: []string{"London", "Paris"}, Answers: []string{"Paris"}},
+		{Question: "The sun is a star.", Type: "true_false", Answers: []string{"true"}},
+	}
+
+	if err := WriteQuizDir(dir, Config{Title: "Imported"}, questions); err != nil {
+		t.Fatalf("WriteQuizDir() error = %v", err)
+	}
+
+	config, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Title != "Imported" || len(config.Questions) != 2 {
+		t.Errorf("Unexpected config %+v", config)
+	}
+
+	quiz := &Quiz{Config: config}
+	if err := quiz.selectQuestions(dir); err != nil {
+		t.Fatalf("selectQuestions() error = %v", err)
+	}
+	if len(quiz.Questions) != 2 || !quiz.Questions[0].checkAnswer("2") {
+		t.Errorf("Written questions did not load back correctly")
+	}
+
+	if err := WriteQuizDir(dir, Config{}, questions); err == nil {
+		t.Error("Expected error writing into a non-empty directory, got nil")
+	}
+}
+
+func TestImportFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geography.gift")
+	if err := os.WriteFile(path, []byte("The sun is a star.{T}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write GIFT file: %v", err)
+	}
+
+	result, err := ImportFile(path, "")
+	if err != nil {
+		t.Fatalf("ImportFile() error = %v", err)
+	}
+	if result.Config.Title != "geography" {
+		t.Errorf("Expected title from file name, got %q", result.Config.Title)
+	}
+
+	if _, err := ImportFile(path+".unknown", ""); err == nil {
+		t.Error("Expected error for unknown format, got nil")
+	}
+}
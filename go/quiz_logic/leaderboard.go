@@ -0,0 +1,197 @@
+package quiz_logic
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AttemptRule picks which of a learner's attempts at a quiz is ranked
+type AttemptRule string
+
+const (
+	AttemptsBest   AttemptRule = "best"   // highest score, then fastest
+	AttemptsLatest AttemptRule = "latest" // most recently finished
+)
+
+// TieRule says how learners with the same score and time are ranked
+type TieRule string
+
+const (
+	TiesShared        TieRule = "shared" // share a rank and the next is skipped: 1, 1, 3
+	TiesDense         TieRule = "dense"  // share a rank and the next follows on: 1, 1, 2
+	TiesFirstFinished TieRule = "first"  // whoever finished first ranks higher: 1, 2, 3
+)
+
+// LeaderboardOptions choose the results on a leaderboard and how they are
+// ranked. The zero value ranks every learner's best attempt of all time,
+// with ties sharing a rank.
+type LeaderboardOptions struct {
+	Since    time.Time   // only attempts finished at or after this; zero for all time
+	Group    string      // only learners in this group, ignoring case; empty for everyone
+	Attempts AttemptRule // empty is AttemptsBest
+	Ties     TieRule     // empty is TiesShared
+	Limit    int         // the top entries to keep; 0 keeps all
+}
+
+// Leaderboard ranks the learners who took one quiz
+type Leaderboard struct {
+	QuizID  string          `json:"quizId"`
+	Title   string          `json:"title"`
+	Entries []RankedAttempt `json:"entries"` // best first
+}
+
+// RankedAttempt is the attempt of one learner that a Leaderboard ranks
+type RankedAttempt struct {
+	Rank      int       `json:"rank"` // 1 for the top
+	LearnerID string    `json:"learnerId,omitempty"`
+	Learner   string    `json:"learner"`
+	Group     string    `json:"group,omitempty"`
+	Score     int       `json:"score"` // percentage
+	Correct   int       `json:"correct"`
+	Total     int       `json:"total"`
+	Seconds   float64   `json:"seconds"` // time taken
+	Finished  time.Time `json:"finished"`
+	Attempts  int       `json:"attempts"` // within the time window
+}
+
+// Check rejects attempt and tie rules that are not known
+func (o LeaderboardOptions) Check() error {
+	switch o.Attempts {
+	case "", AttemptsBest, AttemptsLatest:
+	default:
+		return fmt.Errorf("unknown attempt rule %q, use best or latest", o.Attempts)
+	}
+	switch o.Ties {
+	case "", TiesShared, TiesDense, TiesFirstFinished:
+	default:
+		return fmt.Errorf("unknown tie rule %q, use shared, dense or first", o.Ties)
+	}
+	if o.Limit < 0 {
+		return fmt.Errorf("the limit must not be negative")
+	}
+	return nil
+}
+
+// ParseWindow reads how far back a leaderboard looks, such as "7d", "12h"
+// or "90m". Empty and "all" mean all time, returned as 0.
+func ParseWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(window)
+	if window == "" || strings.EqualFold(window, "all") {
+		return 0, nil
+	}
+	var duration time.Duration
+	var err error
+	if days, ok := strings.CutSuffix(window, "d"); ok {
+		var n int
+		n, err = strconv.Atoi(days)
+		duration = time.Duration(n) * 24 * time.Hour
+	} else {
+		duration, err = time.ParseDuration(window)
+	}
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("time window %q is not like 7d, 12h or all", window)
+	}
+	return duration, nil
+}
+
+// BuildLeaderboard ranks the learners who took the quiz with ID quizID by
+// score, then by time taken, using one attempt each. Learners are told apart
+// as in Standings.
+func BuildLeaderboard(results []Result, quizID string, options LeaderboardOptions) Leaderboard {
+	board := Leaderboard{QuizID: quizID, Entries: []RankedAttempt{}}
+	byLearner := make(map[string]int) // index in board.Entries
+	var titled time.Time              // when the attempt the title was taken from finished
+	for _, result := range FilterResults(results, quizID, "") {
+		if result.Finished.Before(options.Since) ||
+			(options.Group != "" && !strings.EqualFold(result.Group, options.Group)) {
+			continue
+		}
+		if board.Title == "" || !result.Finished.Before(titled) {
+			board.Title, titled = result.Title, result.Finished
+		}
+		entry := RankedAttempt{
+			LearnerID: result.LearnerID,
+			Learner:   result.Learner,
+			Group:     result.Group,
+			Score:     result.Score,
+			Correct:   result.Correct,
+			Total:     result.Total,
+			Seconds:   result.Seconds,
+			Finished:  result.Finished,
+			Attempts:  1,
+		}
+		i, ok := byLearner[learnerKey(result)]
+		if !ok {
+			byLearner[learnerKey(result)] = len(board.Entries)
+			board.Entries = append(board.Entries, entry)
+			continue
+		}
+		kept := &board.Entries[i]
+		entry.Attempts = kept.Attempts + 1
+		if options.Attempts == AttemptsLatest && !entry.Finished.Before(kept.Finished) ||
+			options.Attempts != AttemptsLatest && rankedAbove(entry, *kept) {
+			*kept = entry
+		} else {
+			kept.Attempts = entry.Attempts
+		}
+	}
+
+	sort.SliceStable(board.Entries, func(i, j int) bool {
+		a, b := board.Entries[i], board.Entries[j]
+		if rankedAbove(a, b) || rankedAbove(b, a) {
+			return rankedAbove(a, b)
+		}
+		return a.Finished.Before(b.Finished)
+	})
+	for i := range board.Entries {
+		entry, rank := &board.Entries[i], i+1
+		if i > 0 && options.Ties != TiesFirstFinished {
+			previous := board.Entries[i-1]
+			if !rankedAbove(previous, *entry) {
+				rank = previous.Rank
+			} else if options.Ties == TiesDense {
+				rank = previous.Rank + 1
+			}
+		}
+		entry.Rank = rank
+	}
+	if options.Limit > 0 && len(board.Entries) > options.Limit {
+		board.Entries = board.Entries[:options.Limit]
+	}
+	return board
+}
+
+// rankedAbove reports whether a has a higher score than b, or the same
+// score in less time
+func rankedAbove(a, b RankedAttempt) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.Seconds < b.Seconds
+}
+
+// WriteLeaderboard writes board as a table
+func WriteLeaderboard(w io.Writer, board Leaderboard) {
+	title := board.Title
+	if title == "" {
+		title = board.QuizID
+	}
+	fmt.Fprintf(w, "\n=== Leaderboard: %s ===\n", title)
+	if len(board.Entries) == 0 {
+		fmt.Fprintln(w, "No results recorded.")
+		return
+	}
+	fmt.Fprintln(w, "Rank\tLearner\tScore\tTime\tAttempts")
+	for _, entry := range board.Entries {
+		learner := entry.Learner
+		if entry.Group != "" {
+			learner += " (" + entry.Group + ")"
+		}
+		taken := time.Duration(entry.Seconds * float64(time.Second)).Round(time.Second)
+		fmt.Fprintf(w, "%d\t%s\t%d%%\t%s\t%d\n", entry.Rank, learner, entry.Score, taken, entry.Attempts)
+	}
+}
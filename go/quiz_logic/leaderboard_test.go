@@ -0,0 +1,110 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildLeaderboard(t *testing.T) {
+	start := time.Now().Add(-10 * 24 * time.Hour)
+	results := []Result{
+		{QuizID: "capitals", Title: "Capitals", LearnerID: "ada", Learner: "Ada", Group: "7b", Score: 100, Seconds: 50, Finished: start},
+		{QuizID: "capitals", Title: "Capitals", LearnerID: "ada", Learner: "Ada", Group: "7b", Score: 50, Seconds: 20, Finished: start.Add(9 * 24 * time.Hour)},
+		{QuizID: "capitals", Title: "Capitals", LearnerID: "bob", Learner: "Bob", Group: "7b", Score: 100, Seconds: 30, Finished: start.Add(time.Hour)},
+		{QuizID: "capitals", Title: "Capitals", Learner: "cy", Group: "8a", Score: 50, Seconds: 20, Finished: start.Add(8 * 24 * time.Hour)},
+		{QuizID: "capitals", Title: "Capitals", Learner: "dee", Score: 40, Seconds: 10, Finished: start.Add(2 * time.Hour)},
+		{QuizID: "algebra", Title: "Algebra", LearnerID: "ada", Learner: "Ada", Score: 100, Finished: start},
+	}
+
+	tests := []struct {
+		name    string
+		options LeaderboardOptions
+		want    string // learner:rank:score, best first
+	}{
+		{"Best attempts", LeaderboardOptions{}, "Bob:1:100 Ada:2:100 cy:3:50 dee:4:40"},
+		{"Latest attempts with shared ties", LeaderboardOptions{Attempts: AttemptsLatest}, "Bob:1:100 cy:2:50 Ada:2:50 dee:4:40"},
+		{"Dense ties", LeaderboardOptions{Attempts: AttemptsLatest, Ties: TiesDense}, "Bob:1:100 cy:2:50 Ada:2:50 dee:3:40"},
+		{"Ties to the first finished", LeaderboardOptions{Attempts: AttemptsLatest, Ties: TiesFirstFinished}, "Bob:1:100 cy:2:50 Ada:3:50 dee:4:40"},
+		{"Time window", LeaderboardOptions{Since: start.Add(24 * time.Hour)}, "cy:1:50 Ada:1:50"},
+		{"Group", LeaderboardOptions{Group: "7B"}, "Bob:1:100 Ada:2:100"},
+		{"Limit", LeaderboardOptions{Limit: 1}, "Bob:1:100"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			board := BuildLeaderboard(results, "Capitals", tt.options)
+			var got []string
+			for _, entry := range board.Entries {
+				got = append(got, entry.Learner+":"+strconv.Itoa(entry.Rank)+":"+strconv.Itoa(entry.Score))
+			}
+			if strings.Join(got, " ") != tt.want || board.Title != "Capitals" {
+				t.Errorf("BuildLeaderboard() = %q %v, want %v", board.Title, got, tt.want)
+			}
+		})
+	}
+
+	board := BuildLeaderboard(results, "capitals", LeaderboardOptions{})
+	if ada := board.Entries[1]; ada.Attempts != 2 || ada.Seconds != 50 || ada.Group != "7b" {
+		t.Errorf("Ada's entry = %+v, want her best of 2 attempts", ada)
+	}
+	if board := BuildLeaderboard(results, "history", LeaderboardOptions{}); board.Entries == nil || len(board.Entries) != 0 {
+		t.Errorf("BuildLeaderboard() for a quiz not taken = %+v, want no entries", board)
+	}
+
+	if err := (LeaderboardOptions{Attempts: "worst"}).Check(); err == nil {
+		t.Error("Check() accepted an unknown attempt rule")
+	}
+	if err := (LeaderboardOptions{Ties: "random"}).Check(); err == nil {
+		t.Error("Check() accepted an unknown tie rule")
+	}
+}
+
+func TestParseWindow(t *testing.T) {
+	tests := []struct {
+		window  string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"all", 0, false},
+		{"7d", 7 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"0d", 0, true},
+		{"week", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseWindow(tt.window)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("ParseWindow(%q) = %v, %v", tt.window, got, err)
+		}
+	}
+}
+
+func TestMenu_Leaderboard(t *testing.T) {
+	finished := time.Now()
+	results := []Result{
+		{QuizID: "capitals", Title: "Capitals", Learner: "ada", Group: "7b", Score: 100, Seconds: 65, Finished: finished},
+		{QuizID: "capitals", Title: "Capitals", Learner: "bob", Group: "8a", Score: 50, Seconds: 30, Finished: finished},
+	}
+	var out bytes.Buffer
+	menu := &Menu{
+		Catalog: newTestCatalog(t, `{}`),
+		In:      strings.NewReader("5\ncapitals\n1d\n7b\n5\ncapitals\nsoon\n6\n"),
+		Out:     &out,
+		Results: func() ([]Result, error) { return results, nil },
+	}
+	menu.Run()
+
+	transcript := out.String()
+	if !strings.Contains(transcript, "=== Leaderboard: Capitals ===") || !strings.Contains(transcript, "1\tada (7b)\t100%\t1m5s\t1") {
+		t.Errorf("Expected ada's leaderboard, got:\n%s", transcript)
+	}
+	if strings.Contains(transcript, "bob") {
+		t.Errorf("Expected only group 7b, got:\n%s", transcript)
+	}
+	if !strings.Contains(transcript, `time window "soon" is not like 7d`) {
+		t.Errorf("Expected the bad time window to be reported, got:\n%s", transcript)
+	}
+}
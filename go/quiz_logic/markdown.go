@@ -0,0 +1,268 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MarkdownExt marks a quiz authored as a single Markdown file instead of a
+// directory of JSON files
+const MarkdownExt = ".md"
+
+var (
+	markdownHeading = regexp.MustCompile(`^##\s+(.+?)\s*#*$`)
+	markdownOption  = regexp.MustCompile(`^[-*+]\s+(?:\[([ xX])\]\s+)?(.+)$`)
+	markdownField   = regexp.MustCompile(`^(Answers?|Type|Tags|Points|Shuffle|Pinned):\s*(.*)$`)
+)
+
+// markdownSettings are front matter keys that belong to Config.Settings
+var markdownSettings = map[string]bool{
+	"showFeedbackAfterEach": true,
+	"allowSkipping":         true,
+	"showTimer":             true,
+	"shuffleOptions":        true,
+}
+
+// isMarkdownQuiz reports whether quizPath names a Markdown quiz file
+func isMarkdownQuiz(quizPath string) bool {
+	return strings.EqualFold(filepath.Ext(quizPath), MarkdownExt)
+}
+
+// loadMarkdownQuiz reads a Markdown quiz: front matter with Config fields
+// followed by one "## heading" section per question. Without a questions
+// list in the front matter every question gets its own set.
+func loadMarkdownQuiz(fsys fs.FS, name string) (Config, []QuestionFile, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return Config{}, nil, fmt.Errorf("error reading quiz file: %v", err)
+	}
+
+	config, questions, err := parseMarkdownQuiz(data)
+	if err != nil {
+		return config, nil, fmt.Errorf("%s: %v", path.Base(name), err)
+	}
+	return config, questions, nil
+}
+
+// markdownError carries the line a Markdown problem was found on
+func markdownError(line int, format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+func parseMarkdownQuiz(data []byte) (Config, []QuestionFile, error) {
+	var config Config
+	var lines []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), " \t"))
+	}
+	if err := scanner.Err(); err != nil {
+		return config, nil, err
+	}
+
+	body := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		end := -1
+		for i := 1; i < len(lines); i++ {
+			if lines[i] == "---" {
+				end = i
+				break
+			}
+		}
+		if end < 0 {
+			return config, nil, markdownError(1, "front matter is not closed with ---")
+		}
+		if err := parseFrontMatter(lines[1:end], 2, &config); err != nil {
+			return config, nil, err
+		}
+		body = end + 1
+	}
+
+	var questions []QuestionFile
+	var section []string
+	start := 0
+	flush := func() error {
+		if section == nil {
+			return nil
+		}
+		question, err := parseMarkdownQuestion(section, start)
+		if err != nil {
+			return err
+		}
+		if question.ID == "" {
+			question.ID = fmt.Sprintf("question%03d", len(questions)+1)
+		}
+		questions = append(questions, question)
+		return nil
+	}
+
+	for i := body; i < len(lines); i++ {
+		if markdownHeading.MatchString(lines[i]) {
+			if err := flush(); err != nil {
+				return config, nil, err
+			}
+			section, start = nil, i+1
+		} else if section == nil && strings.TrimSpace(lines[i]) == "" {
+			continue
+		} else if section == nil && start == 0 {
+			// Text before the first question; a "# Title" names the quiz
+			// unless the front matter already does
+			if title, found := strings.CutPrefix(lines[i], "# "); found && config.Title == "" {
+				config.Title = strings.TrimSpace(title)
+			}
+			continue
+		}
+		section = append(section, lines[i])
+	}
+	if err := flush(); err != nil {
+		return config, nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, question := range questions {
+		if seen[question.ID] {
+			return config, nil, fmt.Errorf("question %s is defined twice", question.ID)
+		}
+		seen[question.ID] = true
+	}
+	if len(config.Questions) == 0 {
+		for _, question := range questions {
+			config.Questions = append(config.Questions, []string{question.ID})
+		}
+	}
+
+	return config, questions, nil
+}
+
+// parseFrontMatter reads the YAML front matter into the config. It decodes
+// exactly like a config.yaml, except that settings may also be given at
+// the top level.
+func parseFrontMatter(lines []string, firstLine int, config *Config) error {
+	// Blank lines in front keep YAML's line numbers in step with the file
+	source := []byte(strings.Repeat("\n", firstLine-1) + strings.Join(lines, "\n"))
+	jsonData, err := toJSON(".yaml", source)
+	if err != nil {
+		return err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(jsonData, &fields); err != nil {
+		return markdownError(firstLine, "front matter must be \"key: value\" lines")
+	}
+	settings, _ := fields["settings"].(map[string]interface{})
+	for key, value := range fields {
+		if markdownSettings[key] {
+			if settings == nil {
+				settings = make(map[string]interface{})
+			}
+			settings[key] = value
+			delete(fields, key)
+		}
+	}
+	if settings != nil {
+		fields["settings"] = settings
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return unmarshalQuizJSON(".yaml", source, data, config)
+}
+
+// parseMarkdownQuestion reads one question section. A heading that looks
+// like an ID names the question; otherwise it is only a label, or the
+// question text itself when the section has no other text.
+func parseMarkdownQuestion(lines []string, headingLine int) (QuestionFile, error) {
+	heading := markdownHeading.FindStringSubmatch(lines[0])[1]
+	question := QuestionFile{}
+
+	var text []string
+	var shuffle *bool
+	for i, line := range lines[1:] {
+		lineNumber := headingLine + i + 1
+		trimmed := strings.TrimSpace(line)
+
+		if option := markdownOption.FindStringSubmatch(trimmed); option != nil {
+			question.Options = append(question.Options, option[2])
+			if option[1] == "x" || option[1] == "X" {
+				question.Answers = append(question.Answers, option[2])
+			}
+			continue
+		}
+
+		if field := markdownField.FindStringSubmatch(trimmed); field != nil {
+			value := strings.TrimSpace(field[2])
+			switch field[1] {
+			case "Answer", "Answers":
+				question.Answers = append(question.Answers, splitCSVList(value)...)
+			case "Type":
+				question.Type = value
+			case "Tags":
+				question.Tags = splitCSVList(value)
+			case "Points":
+				points, err := strconv.Atoi(value)
+				if err != nil {
+					return question, markdownError(lineNumber, "points must be a whole number, got %q", value)
+				}
+				question.Points = points
+			case "Shuffle":
+				value, err := strconv.ParseBool(value)
+				if err != nil {
+					return question, markdownError(lineNumber, "shuffle must be true or false")
+				}
+				shuffle = &value
+			case "Pinned":
+				question.PinnedOptions = splitCSVList(value)
+			}
+			continue
+		}
+
+		text = append(text, trimmed)
+	}
+
+	question.Question = strings.TrimSpace(strings.Join(strings.Fields(strings.Join(text, " ")), " "))
+	if question.Question == "" {
+		question.Question = heading
+	} else if safeQuestionID.MatchString(heading) {
+		question.ID = heading
+	}
+	question.ShuffleOptions = shuffle
+
+	if question.Type == "" {
+		question.Type = inferMarkdownType(question)
+	}
+	if question.Type == "true_false" && len(question.Options) > 0 {
+		for i, answer := range question.Answers {
+			question.Answers[i] = strings.ToLower(answer)
+		}
+		question.Options = nil
+	}
+
+	if err := validateQuestionFile(question); err != nil {
+		return question, markdownError(headingLine, "%v", err)
+	}
+	return question, nil
+}
+
+// inferMarkdownType picks the question type from the section's shape
+func inferMarkdownType(question QuestionFile) string {
+	switch {
+	case len(question.Options) == 2 && strings.EqualFold(question.Options[0], "true") && strings.EqualFold(question.Options[1], "false"):
+		return "true_false"
+	case len(question.Options) > 0:
+		return "multiple_choice"
+	case len(question.Answers) == 1 && (strings.EqualFold(question.Answers[0], "true") || strings.EqualFold(question.Answers[0], "false")):
+		return "true_false"
+	default:
+		return "fill_in_blank"
+	}
+}
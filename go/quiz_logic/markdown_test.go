@@ -0,0 +1,142 @@
+package quiz_logic
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMarkdownQuiz(t *testing.T) {
+	quizPath := filepath.Join("../../quiz", "quiz02.md")
+
+	config, err := LoadConfig(quizPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if config.Title != "Science Basics" || config.TimeLimit != 5 || config.PassingScore != 60 || !config.RandomizeOrder {
+		t.Errorf("Unexpected config %+v", config)
+	}
+	if !config.Settings.ShowFeedbackAfterEach || !config.Settings.AllowSkipping || !config.Settings.ShuffleOptions {
+		t.Errorf("Unexpected settings %+v", config.Settings)
+	}
+	if len(config.Questions) != 3 || len(config.Questions[1]) != 2 {
+		t.Errorf("Unexpected question sets %v", config.Questions)
+	}
+
+	bank, err := loadQuestionBank(quizPath)
+	if err != nil {
+		t.Fatalf("loadQuestionBank() error = %v", err)
+	}
+
+	tests := []struct {
+		id     string
+		qtype  string
+		answer string
+	}{
+		{"question001", "multiple_choice", "Mars"},
+		{"question002", "true_false", "true"},
+		{"question003", "true_false", "false"},
+		{"question004", "fill_in_blank", "au"},
+	}
+	for _, tt := range tests {
+		question, ok := bank[tt.id]
+		if !ok {
+			t.Errorf("Question %s not loaded", tt.id)
+			continue
+		}
+		if question.getType() != tt.qtype {
+			t.Errorf("Question %s: type = %s, want %s", tt.id, question.getType(), tt.qtype)
+		}
+		if !question.checkAnswer(tt.answer) {
+			t.Errorf("Question %s: answer %q not accepted", tt.id, tt.answer)
+		}
+	}
+
+	quiz := &Quiz{Config: config, Seed: 1}
+	if err := quiz.selectQuestions(quizPath); err != nil {
+		t.Fatalf("selectQuestions() error = %v", err)
+	}
+	if len(quiz.Questions) != 3 {
+		t.Errorf("Expected 3 questions, got %d", len(quiz.Questions))
+	}
+}
+
+func TestParseMarkdownQuiz(t *testing.T) {
+	markdown := `# Capitals
+
+## What is the capital of France?
+- London
+- [x] Paris
+
+## spain
+The capital of Spain is _______.
+
+Answer: Madrid
+Tags: europe | capitals
+Points: 2
+`
+
+	config, questions, err := parseMarkdownQuiz([]byte(markdown))
+	if err != nil {
+		t.Fatalf("parseMarkdownQuiz() error = %v", err)
+	}
+	if config.Title != "Capitals" {
+		t.Errorf("Expected title from the # heading, got %q", config.Title)
+	}
+	if len(questions) != 2 {
+		t.Fatalf("Expected 2 questions, got %d", len(questions))
+	}
+
+	if questions[0].ID != "question001" || questions[0].Question != "What is the capital of France?" {
+		t.Errorf("Expected the heading as question text, got %+v", questions[0])
+	}
+	if questions[1].ID != "spain" || questions[1].Points != 2 || strings.Join(questions[1].Tags, ",") != "europe,capitals" {
+		t.Errorf("Unexpected second question %+v", questions[1])
+	}
+	if len(config.Questions) != 2 || config.Questions[1][0] != "spain" {
+		t.Errorf("Expected one set per question, got %v", config.Questions)
+	}
+}
+
+func TestParseMarkdownQuiz_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		wantLine string
+	}{
+		{"Unclosed front matter", "---\ntitle: x\n", "line 1"},
+		{"Bad front matter", "---\ntitle x\n---\n", "line 2"},
+		{"Wrong config type", "---\ntimeLimit: soon\n---\n", "line 2"},
+		{"No correct option", "---\ntitle: x\n---\n\n## q1\nPick one\n- [ ] a\n- [ ] b\n", "line 5"},
+		{"Bad points", "## q1\nText\nAnswer: a\nPoints: lots\n", "line 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := parseMarkdownQuiz([]byte(tt.markdown))
+			if err == nil || !strings.Contains(err.Error(), tt.wantLine) {
+				t.Errorf("Expected an error at %s, got %v", tt.wantLine, err)
+			}
+		})
+	}
+}
+
+func TestGetAvailableQuizzes_Markdown(t *testing.T) {
+	tempDir := t.TempDir()
+	markdown := "---\ntitle: Markdown Quiz\n---\n\n## q1\nGo is compiled.\n\nAnswer: true\n"
+	if err := os.WriteFile(filepath.Join(tempDir, "quiz01.md"), []byte(markdown), 0644); err != nil {
+		t.Fatalf("Failed to write quiz file: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "notes.md"), []byte("# Notes\n"), 0644); err != nil {
+		t.Fatalf("Failed to write notes file: %v", err)
+	}
+
+	quizzes, _, err := GetAvailableQuizzes(tempDir)
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzes() error = %v", err)
+	}
+	if len(quizzes) != 1 || quizzes[0].Title != "Markdown Quiz" {
+		t.Errorf("Expected the Markdown quiz to be discovered, got %+v", quizzes)
+	}
+}
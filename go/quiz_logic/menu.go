@@ -1,89 +1,331 @@
 package quiz_logic
 
 import (
+	"bufio"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
+	"io"
+	"io/fs"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type QuizInfo struct {
-	ID    int
-	Title string
-	Path  string
+	ID       string `json:"id"` // stable identifier used for selection and records
+	Index    int    `json:"-"`  // position in the menu, only for display
+	Title    string `json:"title"`
+	Path     string `json:"path"`
+	FS       fs.FS  `json:"-"`                  // file system Path is in, nil for the OS file system
+	Category string `json:"category,omitempty"` // from the config, or the folder below the root; "" at the top
+
+	Description string   `json:"description,omitempty"`
+	Difficulty  string   `json:"difficulty,omitempty"`
+	Duration    int      `json:"estimatedDuration,omitempty"` // estimated minutes, 0 if unknown
+	Tags        []string `json:"tags,omitempty"`
+
+	// The quiz as it was when discovered, so later edits to its files
+	// cannot break it halfway; nil when only the path is known
+	config Config
+	bank   map[string]Question
 }
 
-func GetAvailableQuizzes(basePath string) ([]QuizInfo, error) {
-	entries, err := os.ReadDir(basePath)
+// Start runs the quiz with the given seed, see StartQuiz
+func (info QuizInfo) Start(seed int64) error {
+	quiz, err := info.Load(seed)
 	if err != nil {
-		return nil, fmt.Errorf("error reading directory: %v", err)
-	}
-
-	var quizzes []QuizInfo
-	quizID := 1
-	for _, entry := range entries {
-		if entry.IsDir() && strings.HasPrefix(entry.Name(), "quiz") {
-			quizPath := filepath.Join(basePath, entry.Name())
-			config, err := LoadConfig(quizPath)
-			if err == nil {
-				quizzes = append(quizzes, QuizInfo{
-					ID:    quizID,
-					Title: config.Title,
-					Path:  quizPath,
-				})
-				quizID++
-			} else {
-				return nil, fmt.Errorf("error loading config for %s: %v", entry.Name(), err)
+		return err
+	}
+	quiz.Run()
+	return nil
+}
+
+// Load picks the quiz's form for the given seed without running it. A
+// discovered quiz is loaded as it was when it was discovered.
+func (info QuizInfo) Load(seed int64) (*Quiz, error) {
+	if info.bank != nil {
+		return newQuiz(info.ID, info.config, info.bank, seed)
+	}
+	if info.FS == nil {
+		fsys, name := osQuiz(info.Path)
+		return LoadQuizFS(fsys, name, seed)
+	}
+	return LoadQuizFS(info.FS, info.Path, seed)
+}
+
+// GetAvailableQuizzes lists every quiz below basePath and the quizzes that
+// cannot be taken, see Discovery
+func GetAvailableQuizzes(basePath string) ([]QuizInfo, []QuizProblem, error) {
+	return Discovery{Roots: []string{basePath}}.Find()
+}
+
+// GetAvailableQuizzesFS lists every quiz in fsys, such as a quiz bank
+// embedded in the binary with go:embed
+func GetAvailableQuizzesFS(fsys fs.FS) ([]QuizInfo, []QuizProblem, error) {
+	return Discovery{}.FindFS(fsys)
+}
+
+// Menu is the numbered menu for listing, finding and taking quizzes with
+// line prompts. It reads only In and writes only Out, so several can run at
+// once, one for each learner connected to a TerminalServer.
+type Menu struct {
+	Catalog *Catalog
+	In      io.Reader
+	Out     io.Writer
+	Seed    int64                    // seed for every quiz taken, 0 for a random form
+	Profile Profile                  // who takes the quizzes, recorded in the results
+	Save    func(Result) error       // records the result of each quiz; nil records nothing
+	Results func() ([]Result, error) // the recorded results leaderboards are built from; nil has none
+	Ranking LeaderboardOptions       // how leaderboards rank attempts; the window and group are asked for
+
+	in *bufio.Reader
+}
+
+// Run shows the menu until the learner exits or In ends
+func (m *Menu) Run() {
+	if in, ok := m.In.(*bufio.Reader); ok {
+		m.in = in // such as one ChooseProfile read from first
+	} else {
+		m.in = bufio.NewReader(m.In)
+	}
+	if _, problems := m.Catalog.Quizzes(); len(problems) > 0 {
+		fmt.Fprintf(m.Out, "%d quizzes could not be loaded; list the quizzes for details.\n", len(problems))
+	}
+
+	for {
+		m.showMenu()
+		choice, ok := m.readLine()
+		if !ok {
+			return
+		}
+
+		switch strings.TrimSpace(choice) {
+		case "1":
+			quizzes, problems := m.Catalog.Quizzes()
+			ListQuizzes(m.Out, quizzes, problems)
+		case "2":
+			quizzes, _ := m.Catalog.Quizzes()
+			m.take(quizzes)
+		case "3":
+			quizzes, _ := m.Catalog.Quizzes()
+			if category, ok := m.promptForCategory(quizzes); ok {
+				matches := FilterQuizzes(quizzes, QuizFilter{Category: category})
+				ListQuizzes(m.Out, matches, nil)
+				m.take(matches)
+			}
+		case "4":
+			quizzes, _ := m.Catalog.Quizzes()
+			matches := FilterQuizzes(quizzes, m.promptForSearch())
+			ListQuizzes(m.Out, matches, nil)
+			if len(matches) > 0 {
+				m.take(matches)
 			}
+		case "5":
+			quizzes, _ := m.Catalog.Quizzes()
+			m.showLeaderboard(quizzes)
+		case "6":
+			fmt.Fprintln(m.Out, "Goodbye!")
+			return
+		default:
+			fmt.Fprintln(m.Out, "Invalid choice. Please try again.")
 		}
 	}
+}
 
-	// Sort quizzes by ID
-	sort.Slice(quizzes, func(i, j int) bool {
-		return quizzes[i].ID < quizzes[j].ID
-	})
+func (m *Menu) showMenu() {
+	fmt.Fprintln(m.Out, "\n=== Quiz Program Menu ===")
+	fmt.Fprintln(m.Out, "1. List Available Quizzes")
+	fmt.Fprintln(m.Out, "2. Start a Quiz")
+	fmt.Fprintln(m.Out, "3. Browse by Category")
+	fmt.Fprintln(m.Out, "4. Search Quizzes")
+	fmt.Fprintln(m.Out, "5. Leaderboards")
+	fmt.Fprintln(m.Out, "6. Exit")
+	fmt.Fprint(m.Out, "\nEnter your choice (1-6): ")
+}
 
-	return quizzes, nil
+// take lets the learner pick one of the quizzes, runs it and records the
+// result
+func (m *Menu) take(quizzes []QuizInfo) {
+	info := m.promptForQuiz(quizzes)
+	if info == nil {
+		return
+	}
+	quiz, err := info.Load(m.Seed)
+	if err != nil {
+		fmt.Fprintf(m.Out, "Error running quiz: %v\n", err)
+		return
+	}
+	quiz.SetProfile(m.Profile)
+	quiz.In, quiz.Out, quiz.Interactive = m.in, m.Out, true
+	result := quiz.Run()
+	if m.Save == nil {
+		return
+	}
+	if err := m.Save(result); err != nil {
+		fmt.Fprintf(m.Out, "Error running quiz: %v\n", err)
+	}
 }
 
-func ShowMenu() {
-	fmt.Println("\n=== Quiz Program Menu ===")
-	fmt.Println("1. List Available Quizzes")
-	fmt.Println("2. Start a Quiz")
-	fmt.Println("3. Exit")
-	fmt.Print("\nEnter your choice (1-3): ")
+// showLeaderboard lets the learner pick one of the quizzes, a time window
+// and a group, and shows the leaderboard for them
+func (m *Menu) showLeaderboard(quizzes []QuizInfo) {
+	info := m.promptForQuiz(quizzes)
+	if info == nil {
+		return
+	}
+	options := m.Ranking
+	fmt.Fprint(m.Out, "Time window such as 7d or 12h (Enter for all time): ")
+	line, _ := m.readLine()
+	window, err := ParseWindow(line)
+	if err != nil {
+		fmt.Fprintf(m.Out, "%v\n", err)
+		return
+	}
+	if window > 0 {
+		options.Since = time.Now().Add(-window)
+	}
+	fmt.Fprint(m.Out, "Group (Enter for everyone): ")
+	line, _ = m.readLine()
+	options.Group = strings.TrimSpace(line)
+
+	var results []Result
+	if m.Results != nil {
+		if results, err = m.Results(); err != nil {
+			fmt.Fprintf(m.Out, "Error reading results: %v\n", err)
+			return
+		}
+	}
+	board := BuildLeaderboard(results, info.ID, options)
+	if board.Title == "" {
+		board.Title = info.Title
+	}
+	WriteLeaderboard(m.Out, board)
 }
 
-func ListQuizzes(quizzes []QuizInfo) {
-	fmt.Println("\n=== Available Quizzes ===")
+// ListQuizzes writes the quizzes that can be taken, then those that cannot
+// with the reason
+func ListQuizzes(w io.Writer, quizzes []QuizInfo, problems []QuizProblem) {
+	fmt.Fprintln(w, "\n=== Available Quizzes ===")
 	if len(quizzes) == 0 {
-		fmt.Println("No quizzes available.")
-		return
+		fmt.Fprintln(w, "No quizzes available.")
+	} else {
+		fmt.Fprintln(w, "No.\tID\tTitle")
+		fmt.Fprintln(w, "---\t--\t-----")
+		for _, quiz := range quizzes {
+			fmt.Fprintf(w, "%d\t%s\t%s%s\n", quiz.Index, quiz.ID, quiz.Title, quizDetails(quiz))
+		}
 	}
 
-	fmt.Println("ID\tTitle")
-	fmt.Println("--\t-----")
-	for _, quiz := range quizzes {
-		fmt.Printf("%d\t%s\n", quiz.ID, quiz.Title)
+	if len(problems) > 0 {
+		fmt.Fprintln(w, "\n=== Unavailable Quizzes ===")
+		for _, problem := range problems {
+			fmt.Fprintf(w, "%s\n  %v\n", problem.Path, problem.Err)
+		}
 	}
 }
 
-func PromptForQuiz(quizzes []QuizInfo) *QuizInfo {
-	fmt.Print("\nEnter quiz number (or 0 to return to menu): ")
-	var input int
-	_, err := fmt.Scanln(&input)
+// quizDetails formats the category, difficulty and duration shown after a
+// quiz's title
+func quizDetails(quiz QuizInfo) string {
+	var details string
+	if quiz.Category != "" {
+		details += " (" + quiz.Category + ")"
+	}
 
-	if err != nil || input == 0 {
+	var notes []string
+	if quiz.Difficulty != "" {
+		notes = append(notes, quiz.Difficulty)
+	}
+	if quiz.Duration > 0 {
+		notes = append(notes, fmt.Sprintf("~%d min", quiz.Duration))
+	}
+	if len(notes) > 0 {
+		details += " [" + strings.Join(notes, ", ") + "]"
+	}
+	return details
+}
+
+func (m *Menu) promptForQuiz(quizzes []QuizInfo) *QuizInfo {
+	fmt.Fprint(m.Out, "\nEnter quiz number or ID (or 0 to return to menu): ")
+	input, ok := m.readLine()
+	input = strings.TrimSpace(input)
+	if !ok || input == "" || input == "0" {
 		return nil
 	}
 
+	if quiz, ok := FindQuiz(quizzes, input); ok {
+		return &quiz
+	}
+
+	fmt.Fprintln(m.Out, "Invalid quiz number or ID.")
+	return nil
+}
+
+// FindQuiz looks a quiz up by its ID, ignoring case, or else by its menu
+// number
+func FindQuiz(quizzes []QuizInfo, ref string) (QuizInfo, bool) {
 	for _, quiz := range quizzes {
-		if quiz.ID == input {
-			return &quiz
+		if strings.EqualFold(quiz.ID, ref) {
+			return quiz, true
 		}
 	}
+	if index, err := strconv.Atoi(ref); err == nil {
+		for _, quiz := range quizzes {
+			if quiz.Index == index {
+				return quiz, true
+			}
+		}
+	}
+	return QuizInfo{}, false
+}
+
+// promptForCategory lists the categories of the quizzes and returns the
+// one chosen, or false to return to the menu
+func (m *Menu) promptForCategory(quizzes []QuizInfo) (string, bool) {
+	categories := Categories(quizzes)
+	fmt.Fprintln(m.Out, "\n=== Categories ===")
+	if len(categories) == 0 {
+		fmt.Fprintln(m.Out, "No categories available.")
+		return "", false
+	}
+	for i, category := range categories {
+		count := len(FilterQuizzes(quizzes, QuizFilter{Category: category}))
+		fmt.Fprintf(m.Out, "%d\t%s (%d)\n", i+1, category, count)
+	}
 
-	fmt.Println("Invalid quiz number.")
-	return nil
+	fmt.Fprint(m.Out, "\nEnter category number (or 0 to return to menu): ")
+	line, _ := m.readLine()
+	input, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || input == 0 {
+		return "", false
+	}
+	if input < 1 || input > len(categories) {
+		fmt.Fprintln(m.Out, "Invalid category number.")
+		return "", false
+	}
+	return categories[input-1], true
+}
+
+// promptForSearch asks for a keyword and a longest duration
+func (m *Menu) promptForSearch() QuizFilter {
+	var filter QuizFilter
+	fmt.Fprint(m.Out, "\nSearch titles, descriptions and tags for (Enter for all): ")
+	keyword, _ := m.readLine()
+	filter.Keyword = strings.TrimSpace(keyword)
+
+	fmt.Fprint(m.Out, "Longest duration in minutes (Enter for any): ")
+	duration, _ := m.readLine()
+	if minutes, err := strconv.Atoi(strings.TrimSpace(duration)); err == nil && minutes > 0 {
+		filter.MaxDuration = minutes
+	}
+	return filter
+}
+
+// readLine reads a line of input without its line ending, or returns
+// false once the input has ended
+func (m *Menu) readLine() (string, bool) {
+	line, err := m.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
 }
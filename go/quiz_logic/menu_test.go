@@ -1,9 +1,13 @@
 package quiz_logic
 
 import (
+	"archive/zip"
+	"bytes"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 func TestGetAvailableQuizzes(t *testing.T) {
@@ -44,7 +48,7 @@ func TestGetAvailableQuizzes(t *testing.T) {
 	}
 
 	// Test getting available quizzes
-	result, err := GetAvailableQuizzes(tempDir)
+	result, _, err := GetAvailableQuizzes(tempDir)
 	if err != nil {
 		t.Errorf("getAvailableQuizzes() error = %v", err)
 		return
@@ -57,8 +61,11 @@ func TestGetAvailableQuizzes(t *testing.T) {
 
 	// Verify quiz information
 	for i, quiz := range result {
-		if quiz.ID != i+1 {
-			t.Errorf("Expected quiz ID %d, got %d", i+1, quiz.ID)
+		if quiz.Index != i+1 {
+			t.Errorf("Expected quiz number %d, got %d", i+1, quiz.Index)
+		}
+		if quiz.ID != quizzes[i].dir {
+			t.Errorf("Expected quiz ID %q, got %q", quizzes[i].dir, quiz.ID)
 		}
 		expectedTitle := quizzes[i].title
 		if quiz.Title != expectedTitle {
@@ -68,7 +75,7 @@ func TestGetAvailableQuizzes(t *testing.T) {
 }
 
 func TestGetAvailableQuizzes_InvalidDirectory(t *testing.T) {
-	_, err := GetAvailableQuizzes("/nonexistent/directory")
+	_, _, err := GetAvailableQuizzes("/nonexistent/directory")
 	if err == nil {
 		t.Error("Expected error for nonexistent directory, got nil")
 	}
@@ -95,8 +102,123 @@ func TestGetAvailableQuizzes_InvalidConfig(t *testing.T) {
 		t.Fatalf("Failed to write config file: %v", err)
 	}
 
-	_, err = GetAvailableQuizzes(tempDir)
-	if err == nil {
-		t.Error("Expected error for invalid config, got nil")
+	// A valid quiz next to the broken one must still be listed
+	writeQuizTree(t, tempDir, "quiz02")
+
+	quizzes, problems, err := GetAvailableQuizzes(tempDir)
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzes() error = %v", err)
+	}
+	if len(quizzes) != 1 || quizzes[0].Title != "quiz02" || quizzes[0].Index != 1 {
+		t.Errorf("Expected only quiz02 to be available, got %+v", quizzes)
+	}
+	if len(problems) != 1 || problems[0].Path != quizDir || !strings.Contains(problems[0].Error(), "config.json: line 1") {
+		t.Errorf("Expected a problem for quiz01, got %v", problems)
+	}
+}
+
+func TestGetAvailableQuizzes_BrokenQuestions(t *testing.T) {
+	tempDir := t.TempDir()
+	writeQuizTree(t, tempDir, "quiz01")
+	config := []byte(`{"title": "Missing question", "questions": [["q1"]]}`)
+	if err := os.WriteFile(filepath.Join(tempDir, "quiz01", "config.json"), config, 0644); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	quizzes, problems, err := GetAvailableQuizzes(tempDir)
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzes() error = %v", err)
+	}
+	if len(quizzes) != 0 || len(problems) != 1 || !strings.Contains(problems[0].Error(), "question file not found: q1") {
+		t.Errorf("Expected quiz01 to be unavailable, got %+v and %v", quizzes, problems)
+	}
+}
+
+func TestGetAvailableQuizzesFS(t *testing.T) {
+	question := `{"question": "Go is compiled.", "type": "true_false", "answers": ["true"]}`
+
+	var bundle bytes.Buffer
+	archive := zip.NewWriter(&bundle)
+	for name, content := range map[string]string{
+		"config.json": `{"title": "Bundled", "questions": [["q1"]]}`,
+		"q1.json":     question,
+	} {
+		f, _ := archive.Create(name)
+		f.Write([]byte(content))
+	}
+	archive.Close()
+
+	fsys := fstest.MapFS{
+		"quiz01/config.yaml":          {Data: []byte("title: Directory\nquestions: [[q1]]\n")},
+		"quiz01/q1.json":              {Data: []byte(question)},
+		"quiz02.md":                   {Data: []byte("---\ntitle: Markdown\n---\n\n## q1\nGo is compiled.\n\nAnswer: true\n")},
+		"quiz03" + BundleExt:          {Data: bundle.Bytes()},
+		"science/physics/config.json": {Data: []byte(`{"title": "Physics", "questions": [["q1"]]}`)},
+		"science/physics/q1.json":     {Data: []byte(question)},
+		"README.md":                   {Data: []byte("# Quiz bank\n")},
+	}
+
+	quizzes, _, err := GetAvailableQuizzesFS(fsys)
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzesFS() error = %v", err)
+	}
+
+	want := []string{"Directory", "Markdown", "Bundled", "Physics"}
+	if len(quizzes) != len(want) {
+		t.Fatalf("Expected %d quizzes, got %+v", len(want), quizzes)
+	}
+	for i, quiz := range quizzes {
+		if quiz.Title != want[i] || quiz.Index != i+1 || quiz.FS == nil {
+			t.Errorf("Quiz %d = %+v, want title %q", i, quiz, want[i])
+		}
+
+		bank, err := loadQuestionBankFS(quiz.FS, quiz.Path)
+		if err != nil {
+			t.Errorf("loadQuestionBankFS(%s) error = %v", quiz.Path, err)
+		} else if len(bank) != 1 {
+			t.Errorf("Expected 1 question in %s, got %d", quiz.Path, len(bank))
+		}
+	}
+	if quizzes[3].Path != "science/physics" || quizzes[3].Category != "science" {
+		t.Errorf("Expected science/physics in category science, got %+v", quizzes[3])
+	}
+}
+
+func TestGetAvailableQuizzes_OSPaths(t *testing.T) {
+	quizzes, _, err := GetAvailableQuizzes("../../quiz")
+	if err != nil {
+		t.Fatalf("GetAvailableQuizzes() error = %v", err)
+	}
+	for _, quiz := range quizzes {
+		if quiz.FS != nil || filepath.Dir(quiz.Path) != filepath.Clean("../../quiz") {
+			t.Errorf("Expected an OS path without a file system, got %+v", quiz)
+		}
+	}
+}
+
+func TestFindQuiz(t *testing.T) {
+	quizzes := []QuizInfo{
+		{ID: "science/forces", Index: 1},
+		{ID: "2", Index: 2},
+		{ID: "capitals", Index: 3},
+	}
+
+	tests := []struct {
+		ref    string
+		wantID string
+		found  bool
+	}{
+		{"science/forces", "science/forces", true},
+		{"CAPITALS", "capitals", true},
+		{"3", "capitals", true},
+		{"2", "2", true}, // IDs win over menu numbers
+		{"4", "", false},
+		{"history", "", false},
+	}
+	for _, tt := range tests {
+		quiz, found := FindQuiz(quizzes, tt.ref)
+		if found != tt.found || quiz.ID != tt.wantID {
+			t.Errorf("FindQuiz(%q) = %q, %v, want %q, %v", tt.ref, quiz.ID, found, tt.wantID, tt.found)
+		}
 	}
 }
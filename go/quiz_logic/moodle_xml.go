@@ -0,0 +1,304 @@
+package quiz_logic
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// moodleCategoryPrefix starts the category that carries the quiz title
+const moodleCategoryPrefix = "$course$/"
+
+type moodleQuiz struct {
+	XMLName   xml.Name         `xml:"quiz"`
+	Questions []moodleQuestion `xml:"question"`
+}
+
+type moodleQuestion struct {
+	Type           string         `xml:"type,attr"`
+	Category       *moodleText    `xml:"category,omitempty"`
+	Name           *moodleText    `xml:"name,omitempty"`
+	QuestionText   *moodleText    `xml:"questiontext,omitempty"`
+	DefaultGrade   string         `xml:"defaultgrade,omitempty"`
+	Single         string         `xml:"single,omitempty"`
+	ShuffleAnswers string         `xml:"shuffleanswers,omitempty"`
+	UseCase        string         `xml:"usecase,omitempty"`
+	Answers        []moodleAnswer `xml:"answer"`
+	Tags           *moodleTags    `xml:"tags,omitempty"`
+}
+
+type moodleTags struct {
+	Tags []moodleText `xml:"tag"`
+}
+
+type moodleText struct {
+	Format string `xml:"format,attr,omitempty"`
+	Text   string `xml:"text"`
+}
+
+type moodleAnswer struct {
+	Fraction  string `xml:"fraction,attr"`
+	Format    string `xml:"format,attr,omitempty"`
+	Text      string `xml:"text"`
+	Tolerance string `xml:"tolerance,omitempty"`
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// ImportMoodleXML converts a Moodle XML question export. Question names
+// become question IDs where they are usable as file names.
+func ImportMoodleXML(r io.Reader) (*ImportResult, error) {
+	var quiz moodleQuiz
+	if err := xml.NewDecoder(r).Decode(&quiz); err != nil {
+		return nil, fmt.Errorf("error parsing Moodle XML: %v", err)
+	}
+
+	result := &ImportResult{}
+	number := 0
+	for _, mq := range quiz.Questions {
+		if mq.Type == "category" {
+			if mq.Category != nil && result.Config.Title == "" {
+				path := strings.TrimSuffix(mq.Category.Text, "/")
+				result.Config.Title = path[strings.LastIndex(path, "/")+1:]
+			}
+			continue
+		}
+
+		number++
+		source := fmt.Sprintf("question %d", number)
+		var name string
+		if mq.Name != nil {
+			name = strings.TrimSpace(mq.Name.Text)
+			source = fmt.Sprintf("question %d (%s)", number, name)
+		}
+
+		question, problem := convertMoodleQuestion(mq)
+		if problem != "" {
+			result.addProblem(source, "%s", problem)
+			if question.Type == "" {
+				continue
+			}
+		}
+		question.ID = name
+		result.addQuestion(question, source)
+	}
+
+	assignQuestionIDs(result.Questions)
+	return result, nil
+}
+
+// convertMoodleQuestion follows the parseGIFTAnswers convention: an empty
+// Type means the question was not converted
+func convertMoodleQuestion(mq moodleQuestion) (QuestionFile, string) {
+	question := QuestionFile{}
+	if mq.QuestionText != nil {
+		question.Question = moodlePlainText(*mq.QuestionText)
+	}
+
+	var problem string
+	if grade, err := strconv.ParseFloat(strings.TrimSpace(mq.DefaultGrade), 64); err == nil && grade != 1 {
+		question.Points = int(grade)
+		if float64(question.Points) != grade || grade < 1 {
+			question.Points = 0
+			problem = fmt.Sprintf("default grade %s was dropped, as points are whole numbers from 1", mq.DefaultGrade)
+		}
+	}
+	if mq.Tags != nil {
+		for _, tag := range mq.Tags.Tags {
+			question.Tags = append(question.Tags, strings.TrimSpace(tag.Text))
+		}
+	}
+	correctAnswers := func() {
+		for _, answer := range mq.Answers {
+			text := moodlePlainText(moodleText{Format: answer.Format, Text: answer.Text})
+			fraction, _ := strconv.ParseFloat(answer.Fraction, 64)
+			if mq.Type == "multichoice" {
+				question.Options = append(question.Options, text)
+			}
+			if fraction >= 100 {
+				question.Answers = append(question.Answers, text)
+			} else if fraction > 0 {
+				problem = "partial credit fractions were dropped"
+			}
+		}
+	}
+
+	switch mq.Type {
+	case "multichoice":
+		question.Type = "multiple_choice"
+		correctAnswers()
+		if shuffle, err := strconv.ParseBool(mq.ShuffleAnswers); err == nil {
+			question.ShuffleOptions = &shuffle
+		}
+	case "truefalse":
+		question.Type = "true_false"
+		correctAnswers()
+		for i, answer := range question.Answers {
+			question.Answers[i] = strings.ToLower(answer)
+		}
+	case "shortanswer":
+		question.Type = "fill_in_blank"
+		correctAnswers()
+		if mq.UseCase == "1" {
+			problem = "case sensitive matching was dropped"
+		}
+	case "numerical":
+		question.Type = "fill_in_blank"
+		for _, answer := range mq.Answers {
+			if tolerance, _ := strconv.ParseFloat(answer.Tolerance, 64); tolerance != 0 {
+				return QuestionFile{}, "numeric tolerances are not supported"
+			}
+		}
+		correctAnswers()
+	default:
+		return QuestionFile{}, fmt.Sprintf("%s questions are not supported", mq.Type)
+	}
+
+	return question, problem
+}
+
+// moodlePlainText turns Moodle's HTML text into plain text
+func moodlePlainText(text moodleText) string {
+	value := text.Text
+	if text.Format == "" || text.Format == "html" || text.Format == "moodle_auto_format" {
+		value = html.UnescapeString(htmlTag.ReplaceAllString(value, ""))
+	}
+	return strings.Join(strings.Fields(value), " ")
+}
+
+// ExportMoodleXML writes the quiz as Moodle XML. The title becomes the
+// question category; other config fields have no Moodle equivalent. The
+// returned problems list anything that could not be represented, including
+// each of those fields that is set.
+func ExportMoodleXML(w io.Writer, config Config, questions []QuestionFile) ([]string, error) {
+	quiz := moodleQuiz{}
+	problems := moodleDroppedConfig(config)
+
+	if config.Title != "" {
+		quiz.Questions = append(quiz.Questions, moodleQuestion{
+			Type:     "category",
+			Category: &moodleText{Text: moodleCategoryPrefix + config.Title},
+		})
+	}
+
+	for _, question := range questions {
+		mq := moodleQuestion{
+			Name:         &moodleText{Text: question.ID},
+			QuestionText: &moodleText{Format: "html", Text: html.EscapeString(question.Question)},
+			DefaultGrade: strconv.Itoa(max(question.Points, 1)),
+		}
+		if len(question.Tags) > 0 {
+			mq.Tags = &moodleTags{}
+			for _, tag := range question.Tags {
+				mq.Tags.Tags = append(mq.Tags.Tags, moodleText{Text: tag})
+			}
+		}
+
+		switch question.Type {
+		case "multiple_choice":
+			mq.Type = "multichoice"
+			mq.Single = "true"
+			mq.ShuffleAnswers = strconv.FormatBool(shouldShuffle(question, config))
+			if len(question.PinnedOptions) > 0 && mq.ShuffleAnswers == "true" {
+				problems = append(problems, question.ID+": pinned options cannot be kept in place in Moodle")
+			}
+			for _, option := range question.Options {
+				mq.Answers = append(mq.Answers, moodleAnswer{
+					Fraction: moodleFraction(containsFold(question.Answers, option)),
+					Format:   "html",
+					Text:     html.EscapeString(option),
+				})
+			}
+		case "true_false":
+			mq.Type = "truefalse"
+			correct := len(question.Answers) > 0 && strings.EqualFold(question.Answers[0], "true")
+			mq.Answers = []moodleAnswer{
+				{Fraction: moodleFraction(correct), Text: "true"},
+				{Fraction: moodleFraction(!correct), Text: "false"},
+			}
+		case "fill_in_blank":
+			mq.Type = "shortanswer"
+			mq.UseCase = "0"
+			for _, answer := range question.Answers {
+				mq.Answers = append(mq.Answers, moodleAnswer{Fraction: "100", Text: answer})
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("%s: unknown question type %s", question.ID, question.Type))
+			continue
+		}
+
+		quiz.Questions = append(quiz.Questions, mq)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return problems, err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(quiz); err != nil {
+		return problems, fmt.Errorf("error writing Moodle XML: %v", err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return problems, err
+}
+
+// moodleDroppedConfig lists the config fields set in config that Moodle
+// XML cannot hold, as it has questions and categories but no quizzes
+func moodleDroppedConfig(config Config) []string {
+	var problems []string
+	for _, set := range config.Questions {
+		if len(set) > 1 {
+			problems = append(problems, fmt.Sprintf("config: question set %s picks one of %d questions; Moodle gets all of them", strings.Join(set, ", "), len(set)))
+		}
+	}
+	dropped := []struct {
+		name string
+		set  bool
+	}{
+		{"timeLimit", config.TimeLimit != 0},
+		{"passingScore", config.PassingScore != 0},
+		{"randomizeOrder", config.RandomizeOrder},
+		{"seed", config.Seed != 0},
+		{"description", config.Description != ""},
+		{"difficulty", config.Difficulty != ""},
+		{"estimatedDuration", config.EstimatedDuration != 0},
+		{"tags", len(config.Tags) > 0},
+		{"settings.showFeedbackAfterEach", config.Settings.ShowFeedbackAfterEach},
+		{"settings.allowSkipping", config.Settings.AllowSkipping},
+		{"settings.showTimer", config.Settings.ShowTimer},
+	}
+	for _, field := range dropped {
+		if field.set {
+			problems = append(problems, "config: "+field.name+" has no Moodle equivalent")
+		}
+	}
+	return problems
+}
+
+func moodleFraction(correct bool) string {
+	if correct {
+		return "100"
+	}
+	return "0"
+}
+
+// shouldShuffle resolves a question's shuffle override against the quiz
+func shouldShuffle(question QuestionFile, config Config) bool {
+	if question.ShuffleOptions != nil {
+		return *question.ShuffleOptions
+	}
+	return config.Settings.ShuffleOptions
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
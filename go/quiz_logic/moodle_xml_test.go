@@ -0,0 +1,127 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sameQuestions compares the parts of questions every format keeps
+func sameQuestions(t *testing.T, want, got []QuestionFile) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d questions, got %d", len(want), len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Type != want[i].Type || got[i].Question != want[i].Question {
+			t.Errorf("Question %d: got %s %s %q, want %s %s %q", i+1,
+				got[i].ID, got[i].Type, got[i].Question, want[i].ID, want[i].Type, want[i].Question)
+		}
+		if strings.Join(got[i].Options, "|") != strings.Join(want[i].Options, "|") {
+			t.Errorf("Question %d: options = %v, want %v", i+1, got[i].Options, want[i].Options)
+		}
+		if !strings.EqualFold(strings.Join(got[i].Answers, "|"), strings.Join(want[i].Answers, "|")) {
+			t.Errorf("Question %d: answers = %v, want %v", i+1, got[i].Answers, want[i].Answers)
+		}
+	}
+}
+
+func TestMoodleXML_RoundTrip(t *testing.T) {
+	config, questions, err := LoadQuizFiles(filepath.Join("../../quiz", "test02"))
+	if err != nil {
+		t.Fatalf("LoadQuizFiles() error = %v", err)
+	}
+	questions[0].Points, questions[0].Tags = 3, []string{"geography", "europe"}
+
+	var buf bytes.Buffer
+	problems, err := ExportMoodleXML(&buf, config, questions)
+	if err != nil {
+		t.Fatalf("ExportMoodleXML() error = %v", err)
+	}
+	// The questions all survive, but not the quiz around them
+	want := []string{
+		"config: question set question001, question002 picks one of 2 questions; Moodle gets all of them",
+		"config: question set question003, question004 picks one of 2 questions; Moodle gets all of them",
+		"config: question set question002, question005 picks one of 2 questions; Moodle gets all of them",
+		"config: question set question001, question003 picks one of 2 questions; Moodle gets all of them",
+		"config: timeLimit has no Moodle equivalent",
+		"config: passingScore has no Moodle equivalent",
+		"config: randomizeOrder has no Moodle equivalent",
+		"config: settings.allowSkipping has no Moodle equivalent",
+	}
+	if strings.Join(problems, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Export problems = %q, want %q", problems, want)
+	}
+	if problems, _ := ExportMoodleXML(&bytes.Buffer{}, Config{Title: config.Title}, questions); len(problems) != 0 {
+		t.Errorf("Export problems without quiz settings = %v, want none", problems)
+	}
+
+	result, err := ImportMoodleXML(&buf)
+	if err != nil {
+		t.Fatalf("ImportMoodleXML() error = %v", err)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("Unexpected import problems: %v", result.Problems)
+	}
+	if result.Config.Title != config.Title {
+		t.Errorf("Expected title %q, got %q", config.Title, result.Config.Title)
+	}
+	sameQuestions(t, questions, result.Questions)
+	if got := result.Questions[0]; got.Points != 3 || strings.Join(got.Tags, ",") != "geography,europe" {
+		t.Errorf("Expected points and tags to survive, got %d and %v", got.Points, got.Tags)
+	}
+	if got := result.Questions[1]; got.Points != 0 || got.Tags != nil {
+		t.Errorf("Expected default points and no tags, got %d and %v", got.Points, got.Tags)
+	}
+}
+
+func TestImportMoodleXML(t *testing.T) {
+	moodle := `<?xml version="1.0" encoding="UTF-8"?>
+<quiz>
+  <question type="multichoice">
+    <name><text>Capital of France</text></name>
+    <questiontext format="html"><text><![CDATA[<p>What is the <b>capital</b> of France?</p>]]></text></questiontext>
+    <defaultgrade>2.0000000</defaultgrade>
+    <shuffleanswers>1</shuffleanswers>
+    <answer fraction="100"><text>Paris</text></answer>
+    <answer fraction="0"><text>Rome</text></answer>
+    <tags><tag><text>europe</text></tag></tags>
+  </question>
+  <question type="essay">
+    <name><text>Essay</text></name>
+    <questiontext><text>Discuss.</text></questiontext>
+  </question>
+  <question type="numerical">
+    <name><text>pi</text></name>
+    <questiontext><text>What is pi?</text></questiontext>
+    <answer fraction="100"><text>3.14</text><tolerance>0.01</tolerance></answer>
+  </question>
+</quiz>`
+
+	result, err := ImportMoodleXML(strings.NewReader(moodle))
+	if err != nil {
+		t.Fatalf("ImportMoodleXML() error = %v", err)
+	}
+
+	if len(result.Questions) != 1 {
+		t.Fatalf("Expected 1 question, got %d", len(result.Questions))
+	}
+	question := result.Questions[0]
+	if question.Question != "What is the capital of France?" {
+		t.Errorf("Expected HTML to be stripped, got %q", question.Question)
+	}
+	if question.ID != "" {
+		t.Errorf("Expected a name with spaces to be dropped as ID, got %q", question.ID)
+	}
+	if question.ShuffleOptions == nil || !*question.ShuffleOptions {
+		t.Error("Expected shuffleanswers to become a shuffle override")
+	}
+	if question.Points != 2 || strings.Join(question.Tags, ",") != "europe" {
+		t.Errorf("Expected defaultgrade and tags to become points and tags, got %d and %v", question.Points, question.Tags)
+	}
+
+	if len(result.Problems) != 2 {
+		t.Errorf("Expected 2 problems, got %v", result.Problems)
+	}
+}
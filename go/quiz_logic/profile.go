@@ -0,0 +1,271 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Profile says who takes a quiz. Its ID, name and group are recorded in
+// every result, so that instructors can tell who passed what.
+type Profile struct {
+	ID    string `json:"id"` // short and stable, such as "ada"; picks the profile
+	Name  string `json:"name"`
+	Group string `json:"group,omitempty"` // such as a class or a team
+}
+
+// String is how the profile is shown in lists: the name with the ID and
+// group
+func (p Profile) String() string {
+	if p.Group != "" {
+		return fmt.Sprintf("%s (%s, %s)", p.Name, p.ID, p.Group)
+	}
+	return fmt.Sprintf("%s (%s)", p.Name, p.ID)
+}
+
+// ProfilesEnv names the file profiles are kept in instead of the default
+const ProfilesEnv = "QUIZ_PROFILES"
+
+// ProfilesPath returns the file learner profiles are kept in: the file
+// named by QUIZ_PROFILES, or profiles.json next to the user's settings file
+func ProfilesPath() (string, error) {
+	if env := os.Getenv(ProfilesEnv); env != "" {
+		return env, nil
+	}
+	settingsPath, err := UserSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(settingsPath), "profiles.json"), nil
+}
+
+// profilesMu keeps the profiles added or removed at the same time, such as
+// by a server and the menu, from being saved over each other
+var profilesMu sync.Mutex
+
+// LoadProfiles reads the profiles kept at path, in the order they were
+// added. A missing file means there are none yet.
+func LoadProfiles(path string) ([]Profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading profiles: %v", err)
+	}
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("error reading profiles: %s: %v", filepath.Base(path), err)
+	}
+	return profiles, nil
+}
+
+// SaveProfiles replaces the profiles kept at path, creating the file and
+// its folder if needed
+func SaveProfiles(path string, profiles []Profile) error {
+	data, err := json.MarshalIndent(nonNil(profiles), "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("error saving profiles: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("error saving profiles: %v", err)
+	}
+	return nil
+}
+
+// FindProfile looks a profile up by its ID, or failing that by its name.
+// Both ignore case.
+func FindProfile(profiles []Profile, ref string) (Profile, bool) {
+	ref = strings.TrimSpace(ref)
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.ID, ref) {
+			return profile, true
+		}
+	}
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Name, ref) {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// profileIDPattern is what a profile ID may look like
+var profileIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// AddProfile adds a profile for the learner called name to the profiles
+// kept at path. An empty id is made from the name, numbered if another
+// profile has it already.
+func AddProfile(path, name, id, group string) (Profile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return addProfile(path, profiles, name, id, group)
+}
+
+func addProfile(path string, profiles []Profile, name, id, group string) (Profile, error) {
+	profile := Profile{ID: strings.ToLower(strings.TrimSpace(id)), Name: strings.TrimSpace(name), Group: strings.TrimSpace(group)}
+	if profile.Name == "" {
+		return Profile{}, errors.New("a profile needs a name")
+	}
+	taken := func(id string) bool {
+		for _, other := range profiles {
+			if strings.EqualFold(other.ID, id) {
+				return true
+			}
+		}
+		return false
+	}
+	if profile.ID == "" {
+		base := profileID(profile.Name)
+		profile.ID = base
+		for n := 2; taken(profile.ID); n++ {
+			profile.ID = base + strconv.Itoa(n)
+		}
+	} else if !profileIDPattern.MatchString(profile.ID) {
+		return Profile{}, fmt.Errorf("profile ID %q may only have letters, digits, '.', '_' and '-'", id)
+	} else if taken(profile.ID) {
+		return Profile{}, fmt.Errorf("there is already a profile with ID %s", profile.ID)
+	}
+	return profile, SaveProfiles(path, append(profiles, profile))
+}
+
+// profileID makes an ID from a learner's name: its letters and digits in
+// lower case, with dots for the gaps, such as "ada.lovelace"
+func profileID(name string) string {
+	var id strings.Builder
+	gap := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			if gap && id.Len() > 0 {
+				id.WriteByte('.')
+			}
+			id.WriteRune(r)
+			gap = false
+		default:
+			gap = true
+		}
+	}
+	if id.Len() == 0 {
+		return "learner"
+	}
+	return id.String()
+}
+
+// RemoveProfile removes the profile with the given ID from those kept at
+// path. The results recorded under it are kept.
+func RemoveProfile(path, id string) error {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return err
+	}
+	for i, profile := range profiles {
+		if strings.EqualFold(profile.ID, id) {
+			return SaveProfiles(path, append(profiles[:i], profiles[i+1:]...))
+		}
+	}
+	return fmt.Errorf("no profile with ID %s", id)
+}
+
+// OpenProfile returns the profile with ID or name ref from those kept at
+// path, adding one named ref the first time it is used
+func OpenProfile(path, ref string) (Profile, error) {
+	profilesMu.Lock()
+	defer profilesMu.Unlock()
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	if profile, ok := FindProfile(profiles, ref); ok {
+		return profile, nil
+	}
+	return addProfile(path, profiles, ref, "", "")
+}
+
+// LookupProfile returns the profile with ID or name ref from those kept at
+// path. Unlike OpenProfile it never adds a profile, so a ref that matches
+// none, or an empty path, gives a learner known only by the name ref.
+func LookupProfile(path, ref string) (Profile, error) {
+	ref = strings.TrimSpace(ref)
+	if path == "" || ref == "" {
+		return Profile{Name: ref}, nil
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	if profile, ok := FindProfile(profiles, ref); ok {
+		return profile, nil
+	}
+	return Profile{Name: ref}, nil
+}
+
+// profileByID returns the profile with the given ID, ignoring case, from
+// those kept at path
+func profileByID(path, id string) (Profile, bool, error) {
+	if path == "" {
+		return Profile{}, false, nil
+	}
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Profile{}, false, err
+	}
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.ID, id) {
+			return profile, true, nil
+		}
+	}
+	return Profile{}, false, nil
+}
+
+// ChooseProfile asks who is taking quizzes, offering the profiles kept at
+// path. The learner picks one by number, ID or name, or types a new name
+// to add a profile; an empty line picks suggested.
+func ChooseProfile(in *bufio.Reader, out io.Writer, path, suggested string) (Profile, error) {
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	fmt.Fprintln(out, "\n=== Who is taking quizzes? ===")
+	for i, profile := range profiles {
+		fmt.Fprintf(out, "%d. %s\n", i+1, profile)
+	}
+	fmt.Fprintf(out, "\nEnter a number, an ID or a new name (Enter for %s): ", suggested)
+	line, _ := in.ReadString('\n')
+	ref := strings.TrimSpace(line)
+	if ref == "" {
+		ref = suggested
+	}
+	if n, err := strconv.Atoi(ref); err == nil && n >= 1 && n <= len(profiles) {
+		return profiles[n-1], nil
+	}
+	if profile, ok := FindProfile(profiles, ref); ok {
+		return profile, nil
+	}
+
+	fmt.Fprintf(out, "Adding a profile for %s. Group (Enter for none): ", ref)
+	group, _ := in.ReadString('\n')
+	profile, err := AddProfile(path, ref, "", group)
+	if err != nil {
+		return Profile{}, err
+	}
+	fmt.Fprintf(out, "Your ID is %s; use it to pick your profile next time.\n", profile.ID)
+	return profile, nil
+}
@@ -0,0 +1,120 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quiz", "profiles.json")
+	if profiles, err := LoadProfiles(path); err != nil || profiles != nil {
+		t.Fatalf("LoadProfiles() before any = %v, %v, want none", profiles, err)
+	}
+
+	ada, err := AddProfile(path, " Ada Lovelace ", "", "7b")
+	if err != nil || ada != (Profile{ID: "ada.lovelace", Name: "Ada Lovelace", Group: "7b"}) {
+		t.Fatalf("AddProfile() = %+v, %v", ada, err)
+	}
+	if again, err := AddProfile(path, "Ada Lovelace", "", ""); err != nil || again.ID != "ada.lovelace2" {
+		t.Errorf("AddProfile() with a name taken = %+v, %v, want a numbered ID", again, err)
+	}
+	if _, err := AddProfile(path, "Grace", "ADA.lovelace", ""); err == nil {
+		t.Error("AddProfile() with an ID taken succeeded")
+	}
+	if _, err := AddProfile(path, "Grace", "grace hopper", ""); err == nil {
+		t.Error("AddProfile() with a space in the ID succeeded")
+	}
+	if _, err := AddProfile(path, "  ", "", ""); err == nil {
+		t.Error("AddProfile() without a name succeeded")
+	}
+
+	profiles, err := LoadProfiles(path)
+	if err != nil || len(profiles) != 2 {
+		t.Fatalf("LoadProfiles() = %+v, %v", profiles, err)
+	}
+	if found, ok := FindProfile(profiles, "ADA.LOVELACE"); !ok || found != ada {
+		t.Errorf("FindProfile(ID) = %+v, %v", found, ok)
+	}
+	if found, ok := FindProfile(profiles, "ada lovelace"); !ok || found != ada {
+		t.Errorf("FindProfile(name) = %+v, %v, want the first with the name", found, ok)
+	}
+
+	// Opening a profile adds it the first time only
+	grace, err := OpenProfile(path, "grace")
+	if err != nil || grace.ID != "grace" || grace.Name != "grace" {
+		t.Fatalf("OpenProfile() = %+v, %v", grace, err)
+	}
+	if again, err := OpenProfile(path, "Grace"); err != nil || again != grace {
+		t.Errorf("OpenProfile() the second time = %+v, %v", again, err)
+	}
+
+	if err := RemoveProfile(path, "ada.lovelace2"); err != nil {
+		t.Fatalf("RemoveProfile() error = %v", err)
+	}
+	if err := RemoveProfile(path, "nobody"); err == nil {
+		t.Error("RemoveProfile() of an unknown ID succeeded")
+	}
+	if profiles, _ := LoadProfiles(path); len(profiles) != 2 || profiles[1] != grace {
+		t.Errorf("Profiles after removing one = %+v", profiles)
+	}
+}
+
+func TestChooseProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	ada, _ := AddProfile(path, "Ada", "", "")
+	if _, err := AddProfile(path, "Grace", "", "7b"); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  Profile
+	}{
+		{"By number", "1\n", ada},
+		{"By ID", "grace\n", Profile{ID: "grace", Name: "Grace", Group: "7b"}},
+		{"Suggested", "\n", Profile{ID: "cy", Name: "cy"}},
+		{"New name", "Dora Maar\n8a\n", Profile{ID: "dora.maar", Name: "Dora Maar", Group: "8a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			got, err := ChooseProfile(bufio.NewReader(strings.NewReader(tt.input)), &out, path, "cy")
+			if err != nil || got != tt.want {
+				t.Errorf("ChooseProfile() = %+v, %v, want %+v", got, err, tt.want)
+			}
+			if !strings.Contains(out.String(), "2. Grace (grace, 7b)") {
+				t.Errorf("Expected the profiles to be listed, got:\n%s", out.String())
+			}
+		})
+	}
+
+	if profiles, _ := LoadProfiles(path); len(profiles) != 4 {
+		t.Errorf("Got %d profiles, want the new ones kept", len(profiles))
+	}
+}
+
+func TestLookupProfile(t *testing.T) {
+	path := newTestProfiles(t)
+
+	if grace, err := LookupProfile(path, " GRACE "); err != nil || grace.ID != "grace" || grace.Group != "navy" {
+		t.Errorf("LookupProfile(grace) = %+v, %v", grace, err)
+	}
+	if alan, err := LookupProfile(path, "Alan Turing"); err != nil || alan.ID != "alan" {
+		t.Errorf("LookupProfile(Alan Turing) = %+v, %v", alan, err)
+	}
+
+	// Other names are taken as they are, without adding a profile
+	if ada, err := LookupProfile(path, "Ada"); err != nil || ada != (Profile{Name: "Ada"}) {
+		t.Errorf("LookupProfile(Ada) = %+v, %v, want just the name", ada, err)
+	}
+	if profiles, _ := LoadProfiles(path); len(profiles) != 2 {
+		t.Errorf("Got %d profiles, want none added", len(profiles))
+	}
+	if ada, err := LookupProfile("", "grace"); err != nil || ada.ID != "" {
+		t.Errorf("LookupProfile() without profiles = %+v, %v", ada, err)
+	}
+}
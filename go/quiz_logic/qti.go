@@ -0,0 +1,715 @@
+package quiz_logic
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	qtiNamespace      = "http://www.imsglobal.org/xsd/imsqti_v2p1"
+	qtiCPNamespace    = "http://www.imsglobal.org/xsd/imscp_v1p1"
+	qtiManifestFile   = "imsmanifest.xml"
+	qtiTestFile       = "assessment.xml"
+	qtiItemType       = "imsqti_item_xmlv2p1"
+	qtiTestType       = "imsqti_test_xmlv2p1"
+	qtiResponse       = "RESPONSE"
+	qtiPassingOutcome = "PASSING_SCORE"
+	qtiMatchCorrect   = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/match_correct"
+	qtiMapResponse    = "http://www.imsglobal.org/question/qti_v2p1/rptemplates/map_response"
+)
+
+type qtiManifest struct {
+	XMLName       xml.Name      `xml:"manifest"`
+	Xmlns         string        `xml:"xmlns,attr,omitempty"`
+	Identifier    string        `xml:"identifier,attr"`
+	Schema        string        `xml:"metadata>schema"`
+	SchemaVersion string        `xml:"metadata>schemaversion"`
+	Organizations string        `xml:"organizations"`
+	Resources     []qtiResource `xml:"resources>resource"`
+}
+
+type qtiResource struct {
+	Identifier   string          `xml:"identifier,attr"`
+	Type         string          `xml:"type,attr"`
+	Href         string          `xml:"href,attr"`
+	Files        []qtiFile       `xml:"file"`
+	Dependencies []qtiDependency `xml:"dependency"`
+}
+
+type qtiFile struct {
+	Href string `xml:"href,attr"`
+}
+
+type qtiDependency struct {
+	IdentifierRef string `xml:"identifierref,attr"`
+}
+
+type qtiItem struct {
+	XMLName            xml.Name             `xml:"assessmentItem"`
+	Xmlns              string               `xml:"xmlns,attr,omitempty"`
+	Identifier         string               `xml:"identifier,attr"`
+	Title              string               `xml:"title,attr"`
+	Adaptive           bool                 `xml:"adaptive,attr"`
+	TimeDependent      bool                 `xml:"timeDependent,attr"`
+	Responses          []qtiResponseDecl    `xml:"responseDeclaration"`
+	Outcome            *qtiOutcomeDecl      `xml:"outcomeDeclaration,omitempty"`
+	ItemBody           qtiItemBody          `xml:"itemBody"`
+	ResponseProcessing *qtiResponseTemplate `xml:"responseProcessing,omitempty"`
+}
+
+type qtiResponseDecl struct {
+	Identifier      string      `xml:"identifier,attr"`
+	Cardinality     string      `xml:"cardinality,attr"`
+	BaseType        string      `xml:"baseType,attr"`
+	CorrectResponse *qtiValues  `xml:"correctResponse,omitempty"`
+	Mapping         *qtiMapping `xml:"mapping,omitempty"`
+}
+
+type qtiValues struct {
+	Values []string `xml:"value"`
+}
+
+type qtiMapping struct {
+	DefaultValue string        `xml:"defaultValue,attr"`
+	Entries      []qtiMapEntry `xml:"mapEntry"`
+}
+
+type qtiMapEntry struct {
+	Key           string `xml:"mapKey,attr"`
+	Value         string `xml:"mappedValue,attr"`
+	CaseSensitive string `xml:"caseSensitive,attr,omitempty"`
+}
+
+type qtiOutcomeDecl struct {
+	Identifier    string     `xml:"identifier,attr"`
+	Cardinality   string     `xml:"cardinality,attr"`
+	BaseType      string     `xml:"baseType,attr"`
+	NormalMaximum string     `xml:"normalMaximum,attr,omitempty"`
+	DefaultValue  *qtiValues `xml:"defaultValue,omitempty"`
+}
+
+type qtiResponseTemplate struct {
+	Template string `xml:"template,attr"`
+}
+
+// qtiItemBody is written from the typed fields and read back from the raw
+// XML, because items from other tools can hold arbitrary markup
+type qtiItemBody struct {
+	Inner      string                `xml:",innerxml"`
+	Paragraphs []qtiParagraph        `xml:"p,omitempty"`
+	Choice     *qtiChoiceInteraction `xml:"choiceInteraction,omitempty"`
+}
+
+type qtiParagraph struct {
+	Text      string        `xml:",chardata"`
+	TextEntry *qtiTextEntry `xml:"textEntryInteraction,omitempty"`
+}
+
+type qtiTextEntry struct {
+	ResponseIdentifier string `xml:"responseIdentifier,attr"`
+	ExpectedLength     int    `xml:"expectedLength,attr,omitempty"`
+}
+
+type qtiChoiceInteraction struct {
+	ResponseIdentifier string            `xml:"responseIdentifier,attr"`
+	Shuffle            bool              `xml:"shuffle,attr"`
+	MaxChoices         int               `xml:"maxChoices,attr"`
+	Prompt             string            `xml:"prompt"`
+	Choices            []qtiSimpleChoice `xml:"simpleChoice"`
+}
+
+type qtiSimpleChoice struct {
+	Identifier string `xml:"identifier,attr"`
+	Fixed      bool   `xml:"fixed,attr,omitempty"`
+	Text       string `xml:",chardata"`
+}
+
+type qtiTest struct {
+	XMLName    xml.Name         `xml:"assessmentTest"`
+	Xmlns      string           `xml:"xmlns,attr,omitempty"`
+	Identifier string           `xml:"identifier,attr"`
+	Title      string           `xml:"title,attr"`
+	Outcomes   []qtiOutcomeDecl `xml:"outcomeDeclaration"`
+	TimeLimits *qtiTimeLimits   `xml:"timeLimits,omitempty"`
+	TestParts  []qtiTestPart    `xml:"testPart"`
+}
+
+type qtiTimeLimits struct {
+	MaxTime int `xml:"maxTime,attr"` // seconds
+}
+
+type qtiTestPart struct {
+	Identifier         string             `xml:"identifier,attr"`
+	NavigationMode     string             `xml:"navigationMode,attr"`
+	SubmissionMode     string             `xml:"submissionMode,attr"`
+	ItemSessionControl *qtiSessionControl `xml:"itemSessionControl,omitempty"`
+	Sections           []qtiSection       `xml:"assessmentSection"`
+}
+
+type qtiSessionControl struct {
+	ShowFeedback  bool `xml:"showFeedback,attr"`
+	AllowSkipping bool `xml:"allowSkipping,attr"`
+}
+
+type qtiSection struct {
+	Identifier string        `xml:"identifier,attr"`
+	Title      string        `xml:"title,attr"`
+	Visible    bool          `xml:"visible,attr"`
+	Selection  *qtiSelection `xml:"selection,omitempty"`
+	Ordering   *qtiOrdering  `xml:"ordering,omitempty"`
+	Sections   []qtiSection  `xml:"assessmentSection"`
+	ItemRefs   []qtiItemRef  `xml:"assessmentItemRef"`
+}
+
+type qtiSelection struct {
+	Select int `xml:"select,attr"`
+}
+
+type qtiOrdering struct {
+	Shuffle bool `xml:"shuffle,attr"`
+}
+
+type qtiItemRef struct {
+	Identifier string `xml:"identifier,attr"`
+	Href       string `xml:"href,attr"`
+}
+
+// ExportQTI writes the quiz as an IMS QTI 2.1 content package (zip). Each
+// question set becomes a section selecting one item, and the quiz settings
+// map to the test's ordering, time limit and item session control.
+func ExportQTI(w io.Writer, config Config, questions []QuestionFile) ([]string, error) {
+	var problems []string
+	if config.Settings.ShowTimer {
+		problems = append(problems, "config: showTimer has no QTI equivalent")
+	}
+	if config.Seed != 0 {
+		problems = append(problems, "config: seed has no QTI equivalent")
+	}
+
+	archive := zip.NewWriter(w)
+	manifest := qtiManifest{
+		Xmlns:         qtiCPNamespace,
+		Identifier:    "MANIFEST-" + qtiIdentifier(config.Title, "quiz"),
+		Schema:        "QTIv2.1 Package",
+		SchemaVersion: "1.0.0",
+	}
+	testResource := qtiResource{
+		Identifier: "TEST",
+		Type:       qtiTestType,
+		Href:       qtiTestFile,
+		Files:      []qtiFile{{Href: qtiTestFile}},
+	}
+
+	hrefs := make(map[string]string)
+	for _, question := range questions {
+		item, err := qtiItemFor(question, config)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", question.ID, err))
+			continue
+		}
+		if len(question.Tags) > 0 {
+			problems = append(problems, question.ID+": tags have no QTI equivalent")
+		}
+
+		href := path.Join("items", question.ID+".xml")
+		if err := writeZipXML(archive, href, item); err != nil {
+			return problems, err
+		}
+		hrefs[question.ID] = href
+		manifest.Resources = append(manifest.Resources, qtiResource{
+			Identifier: item.Identifier,
+			Type:       qtiItemType,
+			Href:       href,
+			Files:      []qtiFile{{Href: href}},
+		})
+		testResource.Dependencies = append(testResource.Dependencies, qtiDependency{IdentifierRef: item.Identifier})
+	}
+
+	if err := writeZipXML(archive, qtiTestFile, qtiTestFor(config, hrefs)); err != nil {
+		return problems, err
+	}
+	manifest.Resources = append([]qtiResource{testResource}, manifest.Resources...)
+	if err := writeZipXML(archive, qtiManifestFile, manifest); err != nil {
+		return problems, err
+	}
+
+	if err := archive.Close(); err != nil {
+		return problems, fmt.Errorf("error writing QTI package: %v", err)
+	}
+	return problems, nil
+}
+
+// qtiItemFor converts a question to an item whose SCORE ranges up to the
+// question's points
+func qtiItemFor(question QuestionFile, config Config) (qtiItem, error) {
+	points := strconv.Itoa(max(question.Points, 1))
+	item := qtiItem{
+		Xmlns:              qtiNamespace,
+		Identifier:         qtiIdentifier(question.ID, "item"),
+		Title:              question.ID,
+		Outcome:            &qtiOutcomeDecl{Identifier: "SCORE", Cardinality: "single", BaseType: "float", NormalMaximum: points},
+		ResponseProcessing: &qtiResponseTemplate{Template: qtiMapResponse},
+	}
+	response := qtiResponseDecl{Identifier: qtiResponse, Cardinality: "single", Mapping: &qtiMapping{DefaultValue: "0"}}
+
+	switch question.Type {
+	case "multiple_choice":
+		// Pinned options go last and stay there, as they do when shuffled here
+		var free, pinned []string
+		for _, option := range question.Options {
+			if containsFold(question.PinnedOptions, option) {
+				pinned = append(pinned, option)
+			} else {
+				free = append(free, option)
+			}
+		}
+
+		interaction := &qtiChoiceInteraction{
+			ResponseIdentifier: qtiResponse,
+			Shuffle:            shouldShuffle(question, config),
+			MaxChoices:         1,
+			Prompt:             question.Question,
+		}
+		response.BaseType = "identifier"
+		for i, option := range append(free, pinned...) {
+			id := optionLabel(i)
+			interaction.Choices = append(interaction.Choices, qtiSimpleChoice{
+				Identifier: id,
+				Fixed:      i >= len(free),
+				Text:       option,
+			})
+			if containsFold(question.Answers, option) {
+				response.Mapping.Entries = append(response.Mapping.Entries, qtiMapEntry{Key: id, Value: points})
+			}
+		}
+		item.ItemBody.Choice = interaction
+	case "true_false":
+		correct := "false"
+		if len(question.Answers) > 0 && strings.EqualFold(question.Answers[0], "true") {
+			correct = "true"
+		}
+		response.BaseType = "identifier"
+		response.Mapping = nil
+		response.CorrectResponse = &qtiValues{Values: []string{correct}}
+		item.ResponseProcessing.Template = qtiMatchCorrect
+		item.ItemBody.Choice = &qtiChoiceInteraction{
+			ResponseIdentifier: qtiResponse,
+			MaxChoices:         1,
+			Prompt:             question.Question,
+			Choices: []qtiSimpleChoice{
+				{Identifier: "true", Text: "True"},
+				{Identifier: "false", Text: "False"},
+			},
+		}
+	case "fill_in_blank":
+		response.BaseType = "string"
+		for _, answer := range question.Answers {
+			response.Mapping.Entries = append(response.Mapping.Entries, qtiMapEntry{Key: answer, Value: points, CaseSensitive: "false"})
+		}
+		item.ItemBody.Paragraphs = []qtiParagraph{
+			{Text: question.Question},
+			{TextEntry: &qtiTextEntry{ResponseIdentifier: qtiResponse, ExpectedLength: 20}},
+		}
+	default:
+		return item, fmt.Errorf("unknown question type %s", question.Type)
+	}
+
+	// Tools that ignore the mapping still find one correct response
+	if response.CorrectResponse == nil && len(response.Mapping.Entries) > 0 {
+		response.CorrectResponse = &qtiValues{Values: []string{response.Mapping.Entries[0].Key}}
+	}
+	item.Responses = []qtiResponseDecl{response}
+	return item, nil
+}
+
+func qtiTestFor(config Config, hrefs map[string]string) qtiTest {
+	test := qtiTest{
+		Xmlns:      qtiNamespace,
+		Identifier: qtiIdentifier(config.Title, "quiz"),
+		Title:      config.Title,
+	}
+	if config.PassingScore > 0 {
+		test.Outcomes = append(test.Outcomes, qtiOutcomeDecl{
+			Identifier:   qtiPassingOutcome,
+			Cardinality:  "single",
+			BaseType:     "float",
+			DefaultValue: &qtiValues{Values: []string{strconv.Itoa(config.PassingScore)}},
+		})
+	}
+	if config.TimeLimit > 0 {
+		test.TimeLimits = &qtiTimeLimits{MaxTime: config.TimeLimit * 60}
+	}
+
+	root := qtiSection{
+		Identifier: "QUIZ",
+		Title:      config.Title,
+		Visible:    true,
+		Ordering:   &qtiOrdering{Shuffle: config.RandomizeOrder},
+	}
+	// Item refs need unique identifiers, but a question may sit in
+	// several sets
+	refs := make(map[string]int)
+	for i, set := range config.Questions {
+		section := qtiSection{
+			Identifier: fmt.Sprintf("SET%d", i+1),
+			Title:      fmt.Sprintf("Set %d", i+1),
+			Selection:  &qtiSelection{Select: 1},
+		}
+		for _, id := range set {
+			href, ok := hrefs[id]
+			if !ok {
+				continue
+			}
+			refs[id]++
+			identifier := qtiIdentifier(id, "item")
+			if refs[id] > 1 {
+				identifier = fmt.Sprintf("%s-%d", identifier, refs[id])
+			}
+			section.ItemRefs = append(section.ItemRefs, qtiItemRef{Identifier: identifier, Href: href})
+		}
+		if len(section.ItemRefs) > 0 {
+			root.Sections = append(root.Sections, section)
+		}
+	}
+
+	test.TestParts = []qtiTestPart{{
+		Identifier:     "PART1",
+		NavigationMode: "linear",
+		SubmissionMode: "individual",
+		ItemSessionControl: &qtiSessionControl{
+			ShowFeedback:  config.Settings.ShowFeedbackAfterEach,
+			AllowSkipping: config.Settings.AllowSkipping,
+		},
+		Sections: []qtiSection{root},
+	}}
+	return test
+}
+
+// qtiIdentifier makes a valid QTI identifier, which must not start with a
+// digit or contain spaces
+func qtiIdentifier(name, fallback string) string {
+	var b strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	id := b.String()
+	if id == "" || (id[0] >= '0' && id[0] <= '9') || id[0] == '-' || id[0] == '.' {
+		id = fallback + "_" + id
+	}
+	return id
+}
+
+func writeZipXML(archive *zip.Writer, name string, v interface{}) error {
+	f, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("error adding %s to QTI package: %v", name, err)
+	}
+	if _, err := io.WriteString(f, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(f)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("error writing %s: %v", name, err)
+	}
+	return nil
+}
+
+// ImportQTI converts an IMS QTI 2.1 content package. Question sets, order
+// and settings come from the assessment test when the package has one.
+func ImportQTI(r io.Reader) (*ImportResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading QTI package: %v", err)
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error opening QTI package: %v", err)
+	}
+
+	var manifest qtiManifest
+	if err := readZipXML(archive, qtiManifestFile, &manifest); err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{}
+	ids := make(map[string]string) // item href -> question ID
+	var testHref string
+	for _, resource := range manifest.Resources {
+		switch {
+		case strings.HasPrefix(resource.Type, "imsqti_test"):
+			testHref = resource.Href
+		case strings.HasPrefix(resource.Type, "imsqti_item"):
+			var item qtiItem
+			if err := readZipXML(archive, resource.Href, &item); err != nil {
+				result.addProblem(resource.Href, "%v", err)
+				continue
+			}
+
+			question, problem := convertQTIItem(item)
+			if problem != "" {
+				result.addProblem(resource.Href, "%s", problem)
+				if question.Type == "" {
+					continue
+				}
+			}
+			question.ID = item.Identifier
+			if item.Title != "" && safeQuestionID.MatchString(item.Title) {
+				question.ID = item.Title
+			}
+			before := len(result.Questions)
+			result.addQuestion(question, resource.Href)
+			if len(result.Questions) > before {
+				ids[resource.Href] = question.ID
+			}
+		}
+	}
+	assignQuestionIDs(result.Questions)
+
+	if testHref != "" {
+		var test qtiTest
+		if err := readZipXML(archive, testHref, &test); err != nil {
+			result.addProblem(testHref, "%v", err)
+		} else {
+			result.Config = configFromQTITest(test, path.Dir(testHref), ids)
+		}
+	}
+	// Questions renamed by assignQuestionIDs cannot be referenced by sets
+	for _, question := range result.Questions {
+		if question.ID == "" {
+			result.Config.Questions = nil
+			break
+		}
+	}
+	return result, nil
+}
+
+func readZipXML(archive *zip.Reader, name string, v interface{}) error {
+	f, err := archive.Open(name)
+	if err != nil {
+		return fmt.Errorf("error opening %s: %v", name, err)
+	}
+	defer f.Close()
+	if err := xml.NewDecoder(f).Decode(v); err != nil {
+		return fmt.Errorf("error parsing %s: %v", name, err)
+	}
+	return nil
+}
+
+// convertQTIItem follows the parseGIFTAnswers convention: an empty Type
+// means the item was not converted
+func convertQTIItem(item qtiItem) (QuestionFile, string) {
+	body, err := parseQTIBody(item.ItemBody.Inner)
+	if err != nil {
+		return QuestionFile{}, err.Error()
+	}
+	if body.unsupported != "" {
+		return QuestionFile{}, body.unsupported + " is not supported"
+	}
+
+	var response qtiResponseDecl
+	for _, decl := range item.Responses {
+		if decl.Identifier == body.response {
+			response = decl
+		}
+	}
+	correct := qtiCorrectValues(response)
+	question := QuestionFile{Question: body.text, Points: qtiPoints(item, response)}
+
+	switch {
+	case body.choice != nil:
+		choice := body.choice
+		if choice.MaxChoices != 1 && choice.MaxChoices != 0 {
+			return QuestionFile{}, "choice interactions with several selections are not supported"
+		}
+		if len(choice.Choices) == 2 && choice.Choices[0].Identifier == "true" && choice.Choices[1].Identifier == "false" {
+			question.Type = "true_false"
+			question.Answers = correct
+			break
+		}
+
+		question.Type = "multiple_choice"
+		shuffle := choice.Shuffle
+		question.ShuffleOptions = &shuffle
+		for _, simple := range choice.Choices {
+			text := strings.Join(strings.Fields(simple.Text), " ")
+			question.Options = append(question.Options, text)
+			if containsFold(correct, simple.Identifier) {
+				question.Answers = append(question.Answers, text)
+			}
+			if simple.Fixed {
+				question.PinnedOptions = append(question.PinnedOptions, text)
+			}
+		}
+	case body.textEntry:
+		question.Type = "fill_in_blank"
+		question.Answers = correct
+		if response.Mapping != nil {
+			for _, entry := range response.Mapping.Entries {
+				if entry.CaseSensitive == "true" {
+					return question, "case sensitive matching was dropped"
+				}
+			}
+		}
+	default:
+		return QuestionFile{}, "items without a choice or text entry interaction are not supported"
+	}
+
+	return question, ""
+}
+
+// qtiCorrectValues lists the responses that earn full credit
+func qtiCorrectValues(response qtiResponseDecl) []string {
+	var values []string
+	if response.Mapping != nil {
+		for _, entry := range response.Mapping.Entries {
+			if value, err := strconv.ParseFloat(entry.Value, 64); err == nil && value > 0 {
+				values = append(values, entry.Key)
+			}
+		}
+	}
+	if len(values) == 0 && response.CorrectResponse != nil {
+		values = response.CorrectResponse.Values
+	}
+	return values
+}
+
+// qtiPoints returns what a correct response scores: the normal maximum of
+// SCORE, else the highest mapped value. Both are rounded down to whole
+// points, and 1 comes back as 0, the default.
+func qtiPoints(item qtiItem, response qtiResponseDecl) int {
+	best := 0.0
+	if item.Outcome != nil && item.Outcome.Identifier == "SCORE" {
+		best, _ = strconv.ParseFloat(item.Outcome.NormalMaximum, 64)
+	}
+	if best == 0 && response.Mapping != nil {
+		for _, entry := range response.Mapping.Entries {
+			if value, err := strconv.ParseFloat(entry.Value, 64); err == nil {
+				best = max(best, value)
+			}
+		}
+	}
+	if best <= 1 {
+		return 0
+	}
+	return int(best)
+}
+
+type qtiBody struct {
+	text        string
+	response    string
+	choice      *qtiChoiceInteraction
+	textEntry   bool
+	unsupported string
+}
+
+// parseQTIBody walks the item body markup, collecting the visible question
+// text and the first interaction
+func parseQTIBody(inner string) (qtiBody, error) {
+	var body qtiBody
+	var text strings.Builder
+	decoder := xml.NewDecoder(strings.NewReader(inner))
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return body, fmt.Errorf("error parsing item body: %v", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Local == "choiceInteraction":
+				var choice qtiChoiceInteraction
+				if err := decoder.DecodeElement(&choice, &t); err != nil {
+					return body, fmt.Errorf("error parsing choice interaction: %v", err)
+				}
+				if body.choice == nil && !body.textEntry {
+					body.choice = &choice
+					body.response = choice.ResponseIdentifier
+				}
+				text.WriteString(" " + choice.Prompt + " ")
+			case t.Name.Local == "textEntryInteraction":
+				if body.choice == nil && !body.textEntry {
+					body.textEntry = true
+					for _, attr := range t.Attr {
+						if attr.Name.Local == "responseIdentifier" {
+							body.response = attr.Value
+						}
+					}
+				}
+				text.WriteString(" " + giftBlank + " ")
+			case strings.HasSuffix(t.Name.Local, "Interaction") && body.unsupported == "":
+				body.unsupported = t.Name.Local
+			}
+		case xml.CharData:
+			text.Write(t)
+		}
+	}
+
+	body.text = strings.Join(strings.Fields(text.String()), " ")
+	body.text = strings.TrimSpace(strings.TrimSuffix(body.text, giftBlank))
+	body.text = strings.ReplaceAll(body.text, giftBlank+" .", giftBlank+".")
+	return body, nil
+}
+
+func configFromQTITest(test qtiTest, base string, ids map[string]string) Config {
+	config := Config{Title: test.Title}
+	if test.TimeLimits != nil && test.TimeLimits.MaxTime > 0 {
+		config.TimeLimit = (test.TimeLimits.MaxTime + 59) / 60
+	}
+	for _, outcome := range test.Outcomes {
+		if outcome.Identifier == qtiPassingOutcome && outcome.DefaultValue != nil && len(outcome.DefaultValue.Values) > 0 {
+			if score, err := strconv.ParseFloat(outcome.DefaultValue.Values[0], 64); err == nil {
+				config.PassingScore = int(score)
+			}
+		}
+	}
+
+	var addSets func(sections []qtiSection)
+	addSets = func(sections []qtiSection) {
+		for _, section := range sections {
+			if section.Ordering != nil && section.Ordering.Shuffle {
+				config.RandomizeOrder = true
+			}
+
+			var set []string
+			for _, ref := range section.ItemRefs {
+				if id, ok := ids[path.Join(base, ref.Href)]; ok {
+					set = append(set, id)
+				}
+			}
+			if section.Selection != nil && section.Selection.Select == 1 && len(set) > 0 {
+				config.Questions = append(config.Questions, set)
+			} else {
+				for _, id := range set {
+					config.Questions = append(config.Questions, []string{id})
+				}
+			}
+			addSets(section.Sections)
+		}
+	}
+
+	for _, part := range test.TestParts {
+		if part.ItemSessionControl != nil {
+			config.Settings.ShowFeedbackAfterEach = part.ItemSessionControl.ShowFeedback
+			config.Settings.AllowSkipping = part.ItemSessionControl.AllowSkipping
+		}
+		addSets(part.Sections)
+	}
+	return config
+}
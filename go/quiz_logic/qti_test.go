@@ -0,0 +1,109 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQTI_RoundTrip(t *testing.T) {
+	config, questions, err := LoadQuizFiles(filepath.Join("../../quiz", "test02"))
+	if err != nil {
+		t.Fatalf("LoadQuizFiles() error = %v", err)
+	}
+	for i := range questions {
+		questions[i].Points = i + 1
+	}
+	questions[0].Tags = []string{"geography"}
+
+	var buf bytes.Buffer
+	problems, err := ExportQTI(&buf, config, questions)
+	if err != nil {
+		t.Fatalf("ExportQTI() error = %v", err)
+	}
+	if want := questions[0].ID + ": tags have no QTI equivalent"; strings.Join(problems, "\n") != want {
+		t.Errorf("Export problems = %q, want %q", problems, want)
+	}
+
+	result, err := ImportQTI(&buf)
+	if err != nil {
+		t.Fatalf("ImportQTI() error = %v", err)
+	}
+	if len(result.Problems) != 0 {
+		t.Errorf("Unexpected import problems: %v", result.Problems)
+	}
+	sameQuestions(t, questions, result.Questions)
+	for i, question := range result.Questions {
+		if want := questions[i].Points; question.Points != want && !(want == 1 && question.Points == 0) {
+			t.Errorf("Question %d: points = %d, want %d", i+1, question.Points, want)
+		}
+	}
+
+	got := result.Config
+	if got.Title != config.Title || got.TimeLimit != config.TimeLimit || got.PassingScore != config.PassingScore {
+		t.Errorf("Config = %+v, want %+v", got, config)
+	}
+	if got.RandomizeOrder != config.RandomizeOrder || got.Settings != config.Settings {
+		t.Errorf("Config settings = %+v, want %+v", got, config)
+	}
+	if len(got.Questions) != len(config.Questions) {
+		t.Fatalf("Expected %d question sets, got %v", len(config.Questions), got.Questions)
+	}
+	for i := range config.Questions {
+		if strings.Join(got.Questions[i], ",") != strings.Join(config.Questions[i], ",") {
+			t.Errorf("Set %d = %v, want %v", i+1, got.Questions[i], config.Questions[i])
+		}
+	}
+}
+
+func TestQTI_PinnedOptions(t *testing.T) {
+	shuffle := true
+	questions := []QuestionFile{{
+		ID:             "planets",
+		Question:       "Which are planets?",
+		Type:           "multiple_choice",
+		Options:        []string{"All of the above", "Mars", "Venus"},
+		Answers:        []string{"All of the above"},
+		ShuffleOptions: &shuffle,
+		PinnedOptions:  []string{"All of the above"},
+	}}
+
+	var buf bytes.Buffer
+	if _, err := ExportQTI(&buf, Config{Title: "Space"}, questions); err != nil {
+		t.Fatalf("ExportQTI() error = %v", err)
+	}
+	result, err := ImportQTI(&buf)
+	if err != nil {
+		t.Fatalf("ImportQTI() error = %v", err)
+	}
+
+	question := result.Questions[0]
+	if last := question.Options[len(question.Options)-1]; last != "All of the above" {
+		t.Errorf("Expected pinned option last, got %v", question.Options)
+	}
+	if len(question.PinnedOptions) != 1 || question.ShuffleOptions == nil || !*question.ShuffleOptions {
+		t.Errorf("Expected shuffle with one pinned option, got %+v", question)
+	}
+	if len(question.Answers) != 1 || question.Answers[0] != "All of the above" {
+		t.Errorf("Expected the pinned option as answer, got %v", question.Answers)
+	}
+}
+
+func TestQTIIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"question001", "question001"},
+		{"Basic Quiz", "Basic_Quiz"},
+		{"1st", "item_1st"},
+		{"", "item_"},
+	}
+
+	for _, tt := range tests {
+		if got := qtiIdentifier(tt.name, "item"); got != tt.want {
+			t.Errorf("qtiIdentifier(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,23 +2,35 @@ package quiz_logic
 
 import (
 	"fmt"
+	"math/rand"
 	"strconv"
 	"strings"
 )
 
 // Question interface defines the common behavior for all question types
 type Question interface {
+	getID() string
 	getQuestion() string
 	getType() string
+	getAnswers() []string
+	getTags() []string
+	getPoints() int
 	checkAnswer(answer string) bool
 	getOptions() []string
 }
 
 // BaseQuestion contains common fields for all question types
 type BaseQuestion struct {
+	ID           string   `json:"id"` // question file name without extension
 	QuestionText string   `json:"question"`
 	Type         string   `json:"type"`
 	Answers      []string `json:"answers"`
+	Tags         []string `json:"tags"`
+	Points       int      `json:"points"` // 0 counts as 1
+}
+
+func (bq *BaseQuestion) getID() string {
+	return bq.ID
 }
 
 func (bq *BaseQuestion) getQuestion() string {
@@ -29,17 +41,35 @@ func (bq *BaseQuestion) getType() string {
 	return bq.Type
 }
 
+func (bq *BaseQuestion) getAnswers() []string {
+	return bq.Answers
+}
+
+func (bq *BaseQuestion) getTags() []string {
+	return bq.Tags
+}
+
+func (bq *BaseQuestion) getPoints() int {
+	if bq.Points == 0 {
+		return 1
+	}
+	return bq.Points
+}
+
 // MultipleChoiceQuestion implements Question interface
 type MultipleChoiceQuestion struct {
 	BaseQuestion
-	Options []string `json:"options"`
+	Options        []string `json:"options"`
+	ShuffleOptions *bool    `json:"shuffleOptions"` // overrides Settings.ShuffleOptions when set
+	PinnedOptions  []string `json:"pinnedOptions"`  // kept at the end, e.g. "All of the above"
+	order          []int    // display position -> index into Options, nil for file order
 }
 
 func (mcq *MultipleChoiceQuestion) checkAnswer(answer string) bool {
 	// First try to parse the answer as a number
 	if num, err := strconv.Atoi(answer); err == nil && num > 0 && num <= len(mcq.Options) {
-		// Convert to zero-based index
-		answer = mcq.Options[num-1]
+		// Convert to zero-based index in display order
+		answer = mcq.getOptions()[num-1]
 	}
 
 	// Convert answer to lowercase for case-insensitive comparison
@@ -55,7 +85,51 @@ func (mcq *MultipleChoiceQuestion) checkAnswer(answer string) bool {
 }
 
 func (mcq *MultipleChoiceQuestion) getOptions() []string {
-	return mcq.Options
+	if mcq.order == nil {
+		return mcq.Options
+	}
+	options := make([]string, len(mcq.order))
+	for i, index := range mcq.order {
+		options[i] = mcq.Options[index]
+	}
+	return options
+}
+
+// shouldShuffle reports whether options are shuffled, given the quiz default
+func (mcq *MultipleChoiceQuestion) shouldShuffle(quizDefault bool) bool {
+	if mcq.ShuffleOptions != nil {
+		return *mcq.ShuffleOptions
+	}
+	return quizDefault
+}
+
+// shuffled returns a copy of the question with its options in a random
+// display order. Pinned options stay at the end in their file order.
+func (mcq *MultipleChoiceQuestion) shuffled(rng *rand.Rand) *MultipleChoiceQuestion {
+	var free, pinned []int
+	for i, option := range mcq.Options {
+		if mcq.isPinned(option) {
+			pinned = append(pinned, i)
+		} else {
+			free = append(free, i)
+		}
+	}
+	rng.Shuffle(len(free), func(i, j int) {
+		free[i], free[j] = free[j], free[i]
+	})
+
+	shuffled := *mcq
+	shuffled.order = append(free, pinned...)
+	return &shuffled
+}
+
+func (mcq *MultipleChoiceQuestion) isPinned(option string) bool {
+	for _, pinned := range mcq.PinnedOptions {
+		if strings.EqualFold(option, pinned) {
+			return true
+		}
+	}
+	return false
 }
 
 // TrueFalseQuestion implements Question interface
@@ -109,36 +183,76 @@ func (fib *FillInBlankQuestion) getOptions() []string {
 	return nil
 }
 
-// createQuestion factory function to create the appropriate question type
+// createQuestion factory function to create the appropriate question type.
+// It is the single place where question files are validated.
 func createQuestion(questionData map[string]interface{}) (Question, error) {
 	// Extract common fields
+	questionText, err := stringField(questionData, "question")
+	if err != nil {
+		return nil, err
+	}
+	questionType, err := stringField(questionData, "type")
+	if err != nil {
+		return nil, err
+	}
 	baseQuestion := BaseQuestion{
-		QuestionText: questionData["question"].(string),
-		Type:         questionData["type"].(string),
+		QuestionText: questionText,
+		Type:         questionType,
+	}
+	if id, ok := questionData["id"].(string); ok {
+		baseQuestion.ID = id
 	}
 
-	// Extract answers
-	if answersData, ok := questionData["answers"].([]interface{}); ok {
-		answers := make([]string, len(answersData))
-		for i, ans := range answersData {
-			answers[i] = ans.(string)
+	// Extract answers, tags and points
+	if baseQuestion.Answers, err = stringList(questionData, "answers"); err != nil {
+		return nil, err
+	}
+	if len(baseQuestion.Answers) == 0 {
+		return nil, fmt.Errorf("question has no answers")
+	}
+	if baseQuestion.Tags, err = stringList(questionData, "tags"); err != nil {
+		return nil, err
+	}
+	if pointsData, ok := questionData["points"]; ok {
+		points, ok := pointsData.(float64)
+		if !ok || points < 0 || points != float64(int(points)) {
+			return nil, fmt.Errorf("points must be a whole number of at least 0")
 		}
-		baseQuestion.Answers = answers
+		baseQuestion.Points = int(points)
 	}
 
 	// Create specific question type
 	switch baseQuestion.Type {
 	case "multiple_choice":
 		mcq := &MultipleChoiceQuestion{BaseQuestion: baseQuestion}
-		if optionsData, ok := questionData["options"].([]interface{}); ok {
-			options := make([]string, len(optionsData))
-			for i, opt := range optionsData {
-				options[i] = opt.(string)
+		if mcq.Options, err = stringList(questionData, "options"); err != nil {
+			return nil, err
+		}
+		if len(mcq.Options) < 2 {
+			return nil, fmt.Errorf("multiple choice question needs at least 2 options")
+		}
+		for _, answer := range mcq.Answers {
+			if !containsFold(mcq.Options, answer) {
+				return nil, fmt.Errorf("answer %q is not one of the options", answer)
 			}
-			mcq.Options = options
+		}
+		if shuffleData, ok := questionData["shuffleOptions"]; ok {
+			shuffle, ok := shuffleData.(bool)
+			if !ok {
+				return nil, fmt.Errorf("shuffleOptions must be true or false")
+			}
+			mcq.ShuffleOptions = &shuffle
+		}
+		if mcq.PinnedOptions, err = stringList(questionData, "pinnedOptions"); err != nil {
+			return nil, err
 		}
 		return mcq, nil
 	case "true_false":
+		for _, answer := range baseQuestion.Answers {
+			if !strings.EqualFold(answer, "true") && !strings.EqualFold(answer, "false") {
+				return nil, fmt.Errorf("true/false answer must be true or false, got %q", answer)
+			}
+		}
 		return &TrueFalseQuestion{BaseQuestion: baseQuestion}, nil
 	case "fill_in_blank":
 		return &FillInBlankQuestion{BaseQuestion: baseQuestion}, nil
@@ -146,3 +260,33 @@ func createQuestion(questionData map[string]interface{}) (Question, error) {
 		return nil, fmt.Errorf("unknown question type: %s", baseQuestion.Type)
 	}
 }
+
+// stringField reads a required, non-empty string field
+func stringField(questionData map[string]interface{}, key string) (string, error) {
+	value, ok := questionData[key].(string)
+	if !ok || strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("missing or invalid %q", key)
+	}
+	return value, nil
+}
+
+// stringList reads an optional list of strings
+func stringList(questionData map[string]interface{}, key string) ([]string, error) {
+	data, ok := questionData[key]
+	if !ok || data == nil {
+		return nil, nil
+	}
+	items, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%q must be a list", key)
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		value, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("%q must only contain text", key)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
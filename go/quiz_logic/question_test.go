@@ -1,6 +1,8 @@
 package quiz_logic
 
 import (
+	"math/rand"
+	"strconv"
 	"testing"
 )
 
@@ -163,6 +165,71 @@ func TestCreateQuestion(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Missing question text",
+			data: map[string]interface{}{
+				"type":    "fill_in_blank",
+				"answers": []interface{}{"Paris"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Missing answers",
+			data: map[string]interface{}{
+				"question": "The capital of France is ___.",
+				"type":     "fill_in_blank",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Answer not among options",
+			data: map[string]interface{}{
+				"question": "What is the capital of France?",
+				"type":     "multiple_choice",
+				"answers":  []interface{}{"Rome"},
+				"options":  []interface{}{"London", "Paris"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Invalid true/false answer",
+			data: map[string]interface{}{
+				"question": "Is Paris the capital of France?",
+				"type":     "true_false",
+				"answers":  []interface{}{"yes"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Non-text answers",
+			data: map[string]interface{}{
+				"question": "The square root of 144 is ___.",
+				"type":     "fill_in_blank",
+				"answers":  []interface{}{12.0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Tags and points",
+			data: map[string]interface{}{
+				"question": "The capital of France is ___.",
+				"type":     "fill_in_blank",
+				"answers":  []interface{}{"Paris"},
+				"tags":     []interface{}{"geography"},
+				"points":   2.0,
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fractional points",
+			data: map[string]interface{}{
+				"question": "The capital of France is ___.",
+				"type":     "fill_in_blank",
+				"answers":  []interface{}{"Paris"},
+				"points":   1.5,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -178,3 +245,86 @@ func TestCreateQuestion(t *testing.T) {
 		})
 	}
 }
+
+func TestMultipleChoiceQuestion_Shuffled(t *testing.T) {
+	mcq := &MultipleChoiceQuestion{
+		BaseQuestion: BaseQuestion{
+			QuestionText: "Which of these are planets?",
+			Type:         "multiple_choice",
+			Answers:      []string{"All of the above"},
+		},
+		Options:       []string{"Mars", "Venus", "Earth", "Jupiter", "All of the above"},
+		PinnedOptions: []string{"all of the above"},
+	}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		shuffled := mcq.shuffled(rand.New(rand.NewSource(seed)))
+		options := shuffled.getOptions()
+
+		if len(options) != len(mcq.Options) {
+			t.Fatalf("Seed %d: got %d options, want %d", seed, len(options), len(mcq.Options))
+		}
+		if options[len(options)-1] != "All of the above" {
+			t.Errorf("Seed %d: pinned option moved, options = %v", seed, options)
+		}
+		if !shuffled.checkAnswer(strconv.Itoa(len(options))) {
+			t.Errorf("Seed %d: displayed number of the pinned answer was not accepted", seed)
+		}
+		for i, option := range options[:len(options)-1] {
+			if shuffled.checkAnswer(strconv.Itoa(i + 1)) {
+				t.Errorf("Seed %d: option %d (%q) accepted as correct", seed, i+1, option)
+			}
+		}
+	}
+
+	if got := mcq.getOptions(); got[0] != "Mars" {
+		t.Errorf("Shuffling changed the original question, options = %v", got)
+	}
+}
+
+func TestMultipleChoiceQuestion_ShouldShuffle(t *testing.T) {
+	on, off := true, false
+
+	tests := []struct {
+		name        string
+		override    *bool
+		quizDefault bool
+		want        bool
+	}{
+		{"Quiz default off", nil, false, false},
+		{"Quiz default on", nil, true, true},
+		{"Question forces on", &on, false, true},
+		{"Question forces off", &off, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mcq := &MultipleChoiceQuestion{ShuffleOptions: tt.override}
+			if got := mcq.shouldShuffle(tt.quizDefault); got != tt.want {
+				t.Errorf("shouldShuffle(%v) = %v, want %v", tt.quizDefault, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateQuestion_ShuffleSettings(t *testing.T) {
+	q, err := createQuestion(map[string]interface{}{
+		"question":       "Pick one",
+		"type":           "multiple_choice",
+		"answers":        []interface{}{"A"},
+		"options":        []interface{}{"A", "B", "None of these"},
+		"shuffleOptions": false,
+		"pinnedOptions":  []interface{}{"None of these"},
+	})
+	if err != nil {
+		t.Fatalf("createQuestion() error = %v", err)
+	}
+
+	mcq := q.(*MultipleChoiceQuestion)
+	if mcq.ShuffleOptions == nil || *mcq.ShuffleOptions {
+		t.Errorf("Expected shuffleOptions override false, got %v", mcq.ShuffleOptions)
+	}
+	if len(mcq.PinnedOptions) != 1 || mcq.PinnedOptions[0] != "None of these" {
+		t.Errorf("Expected pinned option \"None of these\", got %v", mcq.PinnedOptions)
+	}
+}
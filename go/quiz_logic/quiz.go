@@ -1,66 +1,133 @@
 package quiz_logic
 
 import (
-	"encoding/json"
+	"bufio"
 	"fmt"
+	"io"
+	"io/fs"
 	"math/rand"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 )
 
 type Quiz struct {
+	ID             string            // stable quiz ID recorded in the result
+	Learner        string            // who takes the quiz, recorded in the result
+	LearnerID      string            // the learner's profile, see SetProfile
+	Group          string            // the learner's group, see SetProfile
+	In             io.Reader         // answers, one per line; nil reads the terminal
+	Interactive    bool              // In is typed by someone watching Out, so answers are not repeated
+	Out            io.Writer         // where the quiz is shown; nil is standard output
+	Answers        map[string]string // scripted answers by question ID, used instead of In
 	Config         Config
 	Questions      []Question
+	Seed           int64 // seed that produced this form, see random()
+	rng            *rand.Rand
 	startTime      time.Time
 	correctAnswers int
 	totalQuestions int
+	questions      []QuestionResult // how each question went, see Result
 }
 
 func (q *Quiz) selectQuestions(quizPath string) error {
-	// Create a map to track loaded questions
+	bank, err := loadQuestionBank(quizPath)
+	if err != nil {
+		return err
+	}
+	return q.pickQuestions(bank)
+}
+
+// loadQuestionBank loads every JSON, YAML or TOML question file in the quiz
+// directory, keyed by file name without extension, or every question of a
+// Markdown quiz, from a quiz path as accepted by LoadConfig
+func loadQuestionBank(quizPath string) (map[string]Question, error) {
+	return loadQuestionBankFS(osQuiz(quizPath))
+}
+
+// loadQuestionBankFS is loadQuestionBank for the quiz called name in fsys
+func loadQuestionBankFS(fsys fs.FS, name string) (map[string]Question, error) {
+	fsys, name, err := openQuiz(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	return loadQuestions(fsys, name)
+}
+
+// loadQuestions loads the questions of the quiz called name in fsys
+func loadQuestions(fsys fs.FS, name string) (map[string]Question, error) {
 	loadedQuestions := make(map[string]Question)
 
-	// First, load all question files and store them in the map
-	files, err := os.ReadDir(quizPath)
+	if isMarkdownQuiz(name) {
+		_, questions, err := loadMarkdownQuiz(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, questionFile := range questions {
+			question, err := questionFile.toQuestion()
+			if err != nil {
+				return nil, fmt.Errorf("error creating question %s: %v", questionFile.ID, err)
+			}
+			loadedQuestions[questionFile.ID] = question
+		}
+		return loadedQuestions, nil
+	}
+
+	files, err := fs.ReadDir(fsys, name)
 	if err != nil {
-		return fmt.Errorf("error reading quiz directory: %v", err)
+		return nil, fmt.Errorf("error reading quiz directory: %v", err)
 	}
 
 	for _, file := range files {
-		if file.Name() == "config.json" {
+		if file.IsDir() || !isQuizFile(file.Name()) || isConfigFile(file.Name()) {
 			continue
 		}
 
-		data, err := os.ReadFile(filepath.Join(quizPath, file.Name()))
+		data, err := fs.ReadFile(fsys, path.Join(name, file.Name()))
 		if err != nil {
-			return fmt.Errorf("error reading question file %s: %v", file.Name(), err)
+			return nil, fmt.Errorf("error reading question file %s: %v", file.Name(), err)
 		}
 
 		var questionData map[string]interface{}
-		if err := json.Unmarshal(data, &questionData); err != nil {
-			return fmt.Errorf("error parsing question file %s: %v", file.Name(), err)
+		if err := decodeQuizFile(file.Name(), data, &questionData); err != nil {
+			return nil, fmt.Errorf("error parsing question file %s: %v", file.Name(), err)
+		}
+		if questionData == nil {
+			questionData = make(map[string]interface{}) // an empty YAML file
 		}
 
+		// The file name without extension is the question ID
+		key := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
+		if _, exists := loadedQuestions[key]; exists {
+			return nil, fmt.Errorf("question %s is defined by more than one file", key)
+		}
+		questionData["id"] = key
+
 		question, err := createQuestion(questionData)
 		if err != nil {
-			return fmt.Errorf("error creating question from %s: %v", file.Name(), err)
+			return nil, fmt.Errorf("error creating question from %s: %v", file.Name(), err)
 		}
-
-		// Store the question in the map using filename without extension as key
-		key := strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))
 		loadedQuestions[key] = question
 	}
 
-	// Now process the question sets from the config
+	return loadedQuestions, nil
+}
+
+// pickQuestions builds the quiz form from a loaded bank using the quiz's
+// random source, so the same seed always yields the same form
+func (q *Quiz) pickQuestions(loadedQuestions map[string]Question) error {
+	q.Questions = nil
+
+	// Process the question sets from the config
 	for _, questionSet := range q.Config.Questions {
 		if len(questionSet) == 0 {
 			continue // Skip empty sets
 		}
 
 		// Randomly select one question from the set
-		questionID := questionSet[rand.Intn(len(questionSet))]
+		questionID := questionSet[q.random().Intn(len(questionSet))]
 
 		if question, exists := loadedQuestions[questionID]; exists {
 			q.Questions = append(q.Questions, question)
@@ -70,69 +137,157 @@ func (q *Quiz) selectQuestions(quizPath string) error {
 	}
 
 	if q.Config.RandomizeOrder {
-		rand.Shuffle(len(q.Questions), func(i, j int) {
+		q.random().Shuffle(len(q.Questions), func(i, j int) {
 			q.Questions[i], q.Questions[j] = q.Questions[j], q.Questions[i]
 		})
 	}
 
+	q.shuffleOptions()
 	return nil
 }
 
-func (q *Quiz) Run() {
-	q.startTime = time.Now()
-	q.totalQuestions = len(q.Questions)
-	q.correctAnswers = 0
+// shuffleOptions fixes the display order of options for every question
+// that shuffles, so the numbers shown in Run map back to the right answer
+func (q *Quiz) shuffleOptions() {
+	for i, question := range q.Questions {
+		if mcq, ok := question.(*MultipleChoiceQuestion); ok && mcq.shouldShuffle(q.Config.Settings.ShuffleOptions) {
+			q.Questions[i] = mcq.shuffled(q.random())
+		}
+	}
+}
+
+// Run presents the questions one at a time and grades the answers. Answers
+// come from Answers when set, else from In, else from the terminal.
+func (q *Quiz) Run() Result {
+	session := q.Begin()
 
-	fmt.Printf("\nStarting Quiz: %s\n", q.Config.Title)
+	out := q.Out
+	if out == nil {
+		out = os.Stdout
+	}
+	var in *bufio.Reader
+	if buffered, ok := q.In.(*bufio.Reader); ok {
+		in = buffered // keep what is buffered for whoever reads next
+	} else if q.In != nil {
+		in = bufio.NewReader(q.In)
+	}
+
+	fmt.Fprintf(out, "\nStarting Quiz: %s\n", q.Config.Title)
 	if q.Config.TimeLimit > 0 && q.Config.Settings.ShowTimer {
-		fmt.Printf("Time Limit: %d minutes\n", q.Config.TimeLimit)
+		fmt.Fprintf(out, "Time Limit: %d minutes\n", q.Config.TimeLimit)
 	}
-	fmt.Printf("Number of Questions: %d\n\n", len(q.Questions))
+	fmt.Fprintf(out, "Number of Questions: %d\n\n", len(q.Questions))
 
-	for i, question := range q.Questions {
-		if q.Config.Settings.ShowTimer && q.isTimeUp() {
-			fmt.Println("\nTime's up!")
+	for {
+		if session.TimeUp() {
+			fmt.Fprintln(out, "\nTime's up!")
+			break
+		}
+		question, ok := session.Current()
+		if !ok {
 			break
 		}
 
-		fmt.Printf("\nQuestion %d: %s\n", i+1, question.getQuestion())
-		options := question.getOptions()
-		if len(options) > 0 {
-			fmt.Println("Options:")
-			for j, option := range options {
-				fmt.Printf("%d. %s\n", j+1, option)
+		fmt.Fprintf(out, "\nQuestion %d: %s\n", question.Number, question.Text)
+		if len(question.Options) > 0 {
+			fmt.Fprintln(out, "Options:")
+			for j, option := range question.Options {
+				fmt.Fprintf(out, "%d. %s\n", j+1, option)
 			}
 		}
 
-		var answer string
-		if q.Config.Settings.AllowSkipping {
-			fmt.Print("\nEnter your answer (or press Enter to skip): ")
+		if question.AllowSkipping {
+			fmt.Fprint(out, "\nEnter your answer (or press Enter to skip): ")
 		} else {
-			fmt.Print("\nEnter your answer: ")
+			fmt.Fprint(out, "\nEnter your answer: ")
 		}
-		fmt.Scanln(&answer)
-
-		if answer == "" && q.Config.Settings.AllowSkipping {
-			fmt.Println("Question skipped.")
-			continue
+		answer := q.readAnswer(question.ID, in)
+		if q.Answers != nil || (in != nil && !q.Interactive) {
+			fmt.Fprintln(out, answer) // show scripted answers in the transcript
 		}
 
-		if question.checkAnswer(answer) {
-			q.correctAnswers++
-			if q.Config.Settings.ShowFeedbackAfterEach {
-				fmt.Println("Correct!")
-			}
-		} else if q.Config.Settings.ShowFeedbackAfterEach {
-			fmt.Println("Incorrect.")
+		if session.TimeUp() {
+			continue // too late to count; the top of the loop says so
+		}
+		correct, skipped := session.Answer(answer)
+		switch {
+		case skipped:
+			fmt.Fprintln(out, "Question skipped.")
+		case !q.Config.Settings.ShowFeedbackAfterEach:
+		case correct:
+			fmt.Fprintln(out, "Correct!")
+		default:
+			fmt.Fprintln(out, "Incorrect.")
 		}
 	}
 
-	score := q.calculateScore()
-	fmt.Printf("\nQuiz completed!\nScore: %d/%d (%d%%)\n", q.correctAnswers, q.totalQuestions, score)
-	if q.hasPassed() {
-		fmt.Println("Congratulations! You passed!")
+	result := session.Finish()
+	fmt.Fprintf(out, "\nQuiz completed!\nScore: %d/%d (%d%%)\n", result.Correct, result.Total, result.Score)
+	fmt.Fprintf(out, "Seed: %d\n", result.Seed)
+	if result.Passed {
+		fmt.Fprintln(out, "Congratulations! You passed!")
 	} else {
-		fmt.Println("Sorry, you didn't pass. Keep practicing!")
+		fmt.Fprintln(out, "Sorry, you didn't pass. Keep practicing!")
+	}
+	return result
+}
+
+// readAnswer returns the answer to a question: the scripted one for its ID,
+// the next line of in, or a word typed at the terminal. A missing answer,
+// including one past the end of in, is empty.
+func (q *Quiz) readAnswer(questionID string, in *bufio.Reader) string {
+	if q.Answers != nil {
+		return q.Answers[questionID]
+	}
+	if in != nil {
+		line, _ := in.ReadString('\n')
+		return strings.TrimSpace(line)
+	}
+
+	var answer string
+	fmt.Scanln(&answer)
+	return answer
+}
+
+// record notes how a question went
+func (q *Quiz) record(question Question, answer string, correct, skipped bool, spent time.Duration) {
+	points := 0
+	if correct {
+		points = question.getPoints()
+	}
+	q.questions = append(q.questions, QuestionResult{
+		ID:        question.getID(),
+		Question:  question.getQuestion(),
+		Answer:    answer,
+		Expected:  question.getAnswers(),
+		Correct:   correct,
+		Skipped:   skipped,
+		Points:    points,
+		MaxPoints: question.getPoints(),
+		Seconds:   spent.Seconds(),
+	})
+}
+
+// SetProfile records the attempt as that of the learner with profile p
+func (q *Quiz) SetProfile(p Profile) {
+	q.Learner, q.LearnerID, q.Group = p.Name, p.ID, p.Group
+}
+
+// result summarises the attempt so far
+func (q *Quiz) result() Result {
+	return Result{
+		QuizID:    q.ID,
+		Title:     q.Config.Title,
+		Learner:   q.Learner,
+		LearnerID: q.LearnerID,
+		Group:     q.Group,
+		Seed:      q.Seed,
+		Correct:   q.correctAnswers,
+		Total:     q.totalQuestions,
+		Score:     q.calculateScore(),
+		Passed:    q.hasPassed(),
+
+		Questions: q.questions,
 	}
 }
 
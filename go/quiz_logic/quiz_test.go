@@ -200,3 +200,27 @@ func TestQuiz_HasPassed(t *testing.T) {
 		})
 	}
 }
+
+func TestQuiz_ShuffleOptions(t *testing.T) {
+	off := false
+	shuffled := &MultipleChoiceQuestion{
+		BaseQuestion: BaseQuestion{Type: "multiple_choice", Answers: []string{"A"}},
+		Options:      []string{"A", "B", "C", "D", "E", "F"},
+	}
+	fixed := &MultipleChoiceQuestion{
+		BaseQuestion:   BaseQuestion{Type: "multiple_choice", Answers: []string{"A"}},
+		Options:        []string{"A", "B", "C", "D", "E", "F"},
+		ShuffleOptions: &off,
+	}
+
+	quiz := &Quiz{Seed: 3, Questions: []Question{shuffled, fixed}}
+	quiz.Config.Settings.ShuffleOptions = true
+	quiz.shuffleOptions()
+
+	if quiz.Questions[0] == Question(shuffled) {
+		t.Error("Expected the shuffled question to be replaced by a shuffled copy")
+	}
+	if quiz.Questions[1] != Question(fixed) {
+		t.Error("Expected the question with shuffleOptions false to keep file order")
+	}
+}
@@ -6,9 +6,8 @@ import (
 	"time"
 )
 
-var rng = rand.New(rand.NewSource(time.Now().UnixNano()))
-
 type Quoter struct {
+	rng       *rand.Rand
 	wisdom    int
 	humor     string
 	power     float64
@@ -17,7 +16,14 @@ type Quoter struct {
 }
 
 func NewQuoter() *Quoter {
+	return NewQuoterWithSeed(time.Now().UnixNano())
+}
+
+// NewQuoterWithSeed creates a Quoter whose picks are reproducible for a given seed
+func NewQuoterWithSeed(seed int64) *Quoter {
+	rng := rand.New(rand.NewSource(seed))
 	return &Quoter{
+		rng:       rng,
 		wisdom:    rng.Intn(100),
 		humor:     "haha",
 		power:     rng.Float64() * 9000,
@@ -88,7 +94,7 @@ func (q *Quoter) GetWisdomQuote() string {
 		"With great power comes great responsibility.",
 		"Knowledge speaks, but wisdom listens.",
 	}
-	return quotes[rng.Intn(len(quotes))]
+	return quotes[q.rng.Intn(len(quotes))]
 }
 
 func (q *Quoter) GetHumorQuote() string {
@@ -97,13 +103,12 @@ func (q *Quoter) GetHumorQuote() string {
 		"There are 10 types of people in this world. Those who understand binary and those who don't.",
 		"A SQL query walks into a bar, walks up to two tables and asks... 'Can I join you?'",
 	}
-	return quotes[rng.Intn(len(quotes))]
+	return quotes[q.rng.Intn(len(quotes))]
 }
 
 func (q *Quoter) GetRandomQuote() string {
 	if len(q.quotes) == 0 {
 		return ""
 	}
-	rng.Seed(time.Now().UnixNano())
-	return q.quotes[rng.Intn(len(q.quotes))]
+	return q.quotes[q.rng.Intn(len(q.quotes))]
 }
@@ -152,3 +152,18 @@ func TestQuoter_QuoteExists(t *testing.T) {
 		})
 	}
 }
+
+func TestNewQuoterWithSeed(t *testing.T) {
+	first := NewQuoterWithSeed(42)
+	second := NewQuoterWithSeed(42)
+
+	if first.GetWisdom() != second.GetWisdom() || first.GetPower() != second.GetPower() {
+		t.Error("Quoters with the same seed have different attributes")
+	}
+
+	for i := 0; i < 10; i++ {
+		if a, b := first.GetRandomQuote(), second.GetRandomQuote(); a != b {
+			t.Errorf("Quote %d differs for the same seed: %q vs %q", i, a, b)
+		}
+	}
+}
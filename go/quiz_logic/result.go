@@ -0,0 +1,119 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result summarises a finished quiz attempt
+type Result struct {
+	QuizID    string    `json:"quizId,omitempty"` // see QuizInfo.ID
+	Title     string    `json:"title"`
+	Learner   string    `json:"learner,omitempty"`
+	LearnerID string    `json:"learnerId,omitempty"` // see Profile; empty for learners without one
+	Group     string    `json:"group,omitempty"`
+	Finished  time.Time `json:"finished"`
+	Seed      int64     `json:"seed"` // regenerates the exact form with the same quiz files
+	Correct   int       `json:"correct"`
+	Total     int       `json:"total"`
+	Score     int       `json:"score"` // percentage
+	Passed    bool      `json:"passed"`
+	Seconds   float64   `json:"seconds"` // time taken for the whole quiz
+
+	Questions []QuestionResult `json:"questions,omitempty"` // in the order asked
+}
+
+// QuestionResult is how one question of an attempt went. Questions that
+// were not reached before the time ran out count as skipped.
+type QuestionResult struct {
+	ID        string   `json:"id"`
+	Question  string   `json:"question"`
+	Answer    string   `json:"answer"` // as given, such as an option number
+	Expected  []string `json:"expected"`
+	Correct   bool     `json:"correct"`
+	Skipped   bool     `json:"skipped,omitempty"`
+	Points    int      `json:"points"` // earned
+	MaxPoints int      `json:"maxPoints"`
+	Seconds   float64  `json:"seconds"` // time spent answering
+}
+
+// ResultsEnv names the file results are recorded in instead of the default
+const ResultsEnv = "QUIZ_RESULTS"
+
+// ResultsPath returns the file finished attempts are recorded in: the file
+// named by QUIZ_RESULTS, or results.jsonl next to the user's settings file
+func ResultsPath() (string, error) {
+	if env := os.Getenv(ResultsEnv); env != "" {
+		return env, nil
+	}
+	settingsPath, err := UserSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(settingsPath), "results.jsonl"), nil
+}
+
+// SaveResult appends result to the results file at resultsPath as one line
+// of JSON, creating the file and its folder if needed
+func SaveResult(resultsPath string, result Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(resultsPath), 0755); err != nil {
+		return fmt.Errorf("error saving result: %v", err)
+	}
+	f, err := os.OpenFile(resultsPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("error saving result: %v", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("error saving result: %v", err)
+	}
+	return f.Close()
+}
+
+// LoadResults reads every result recorded at resultsPath, oldest first. A
+// missing file means nothing was recorded yet.
+func LoadResults(resultsPath string) ([]Result, error) {
+	data, err := os.ReadFile(resultsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading results: %v", err)
+	}
+
+	var results []Result
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal([]byte(line), &result); err != nil {
+			return nil, fmt.Errorf("error reading results: %s line %d: %v", filepath.Base(resultsPath), i+1, err)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// FilterResults keeps the results of one quiz and one learner, given by
+// profile ID or name; an empty quiz ID or learner matches every result.
+// Both ignore case.
+func FilterResults(results []Result, quizID, learner string) []Result {
+	var matches []Result
+	for _, result := range results {
+		if (quizID == "" || strings.EqualFold(result.QuizID, quizID)) &&
+			(learner == "" || strings.EqualFold(result.LearnerID, learner) || strings.EqualFold(result.Learner, learner)) {
+			matches = append(matches, result)
+		}
+	}
+	return matches
+}
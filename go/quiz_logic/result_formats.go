@@ -0,0 +1,176 @@
+package quiz_logic
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ResultWriters maps result format names to their writers
+var ResultWriters = map[string]func(io.Writer, Result) error{
+	"json":  WriteResultJSON,
+	"csv":   WriteResultCSV,
+	"junit": WriteResultJUnit,
+}
+
+// WriteResult writes result in the given format
+func WriteResult(w io.Writer, result Result, format string) error {
+	writer, ok := ResultWriters[format]
+	if !ok {
+		return fmt.Errorf("unknown result format: %s", format)
+	}
+	return writer(w, result)
+}
+
+// WriteResultJSON writes the result with every question as indented JSON
+func WriteResultJSON(w io.Writer, result Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// ResultCSVColumns are the columns written by WriteResultCSV. Every row is
+// one question and repeats the attempt's columns, so rows from many
+// attempts can be appended to one sheet.
+var ResultCSVColumns = []string{
+	"quiz_id", "title", "learner", "finished", "seed", "score", "passed",
+	"question_id", "question", "answer", "expected", "correct", "skipped", "points", "max_points", "seconds",
+}
+
+// WriteResultCSV writes one row per question, see ResultCSVColumns
+func WriteResultCSV(w io.Writer, result Result) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(ResultCSVColumns); err != nil {
+		return err
+	}
+
+	finished := ""
+	if !result.Finished.IsZero() {
+		finished = result.Finished.Format(time.RFC3339)
+	}
+	for _, question := range result.Questions {
+		row := []string{
+			result.QuizID, result.Title, result.Learner, finished,
+			strconv.FormatInt(result.Seed, 10), strconv.Itoa(result.Score), strconv.FormatBool(result.Passed),
+			question.ID, question.Question, question.Answer, strings.Join(question.Expected, "|"),
+			strconv.FormatBool(question.Correct), strconv.FormatBool(question.Skipped),
+			strconv.Itoa(question.Points), strconv.Itoa(question.MaxPoints), formatSeconds(question.Seconds),
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Timestamp  string          `xml:"timestamp,attr,omitempty"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Skipped   *junitSkipped `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// WriteResultJUnit writes the attempt as a JUnit XML test suite with one
+// test case per question, so CI can show a training check like a test run.
+// Wrong answers are failures, and a failed quiz gets a failing test case
+// of its own in case no single question failed.
+func WriteResultJUnit(w io.Writer, result Result) error {
+	name := result.QuizID
+	if name == "" {
+		name = result.Title
+	}
+	suite := junitSuite{
+		Name: name,
+		Time: formatSeconds(result.Seconds),
+		Properties: []junitProperty{
+			{Name: "title", Value: result.Title},
+			{Name: "learner", Value: result.Learner},
+			{Name: "seed", Value: strconv.FormatInt(result.Seed, 10)},
+			{Name: "score", Value: strconv.Itoa(result.Score)},
+			{Name: "passed", Value: strconv.FormatBool(result.Passed)},
+		},
+	}
+	if !result.Finished.IsZero() {
+		suite.Timestamp = result.Finished.Format("2006-01-02T15:04:05")
+	}
+
+	for _, question := range result.Questions {
+		testCase := junitCase{
+			Name:      question.ID + ": " + question.Question,
+			ClassName: name,
+			Time:      formatSeconds(question.Seconds),
+		}
+		switch {
+		case question.Skipped:
+			testCase.Skipped = &junitSkipped{Message: "not answered"}
+			suite.Skipped++
+		case !question.Correct:
+			testCase.Failure = &junitFailure{
+				Message: fmt.Sprintf("answered %q", question.Answer),
+				Text:    "expected " + strings.Join(question.Expected, " or "),
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	score := junitCase{Name: "passing score", ClassName: name, Time: formatSeconds(0)}
+	if !result.Passed {
+		score.Failure = &junitFailure{Message: fmt.Sprintf("scored %d%%", result.Score)}
+		suite.Failures++
+	}
+	suite.Cases = append(suite.Cases, score)
+	suite.Tests = len(suite.Cases)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', 3, 64)
+}
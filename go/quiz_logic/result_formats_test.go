@@ -0,0 +1,113 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sampleResult() Result {
+	return Result{
+		QuizID:   "science/physics",
+		Title:    "Physics",
+		Learner:  "ada",
+		Finished: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		Seed:     42,
+		Correct:  1,
+		Total:    3,
+		Score:    33,
+		Passed:   false,
+		Seconds:  12.5,
+		Questions: []QuestionResult{
+			{ID: "gravity", Question: "g on Earth, in m/s²?", Answer: "9.8", Expected: []string{"9.8", "9.81"}, Correct: true, Points: 2, MaxPoints: 2, Seconds: 4},
+			{ID: "light", Question: "Is light a wave?", Answer: "2", Expected: []string{"true"}, MaxPoints: 1, Seconds: 3.25},
+			{ID: "units", Question: "SI unit of force?", Expected: []string{"newton"}, Skipped: true, MaxPoints: 1},
+		},
+	}
+}
+
+func TestWriteResult_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, sampleResult(), "json"); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+
+	var got Result
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("Output is not JSON: %v", err)
+	}
+	if !reflect.DeepEqual(got, sampleResult()) {
+		t.Errorf("JSON round trip = %+v, want %+v", got, sampleResult())
+	}
+}
+
+func TestWriteResult_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, sampleResult(), "csv"); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("Output is not CSV: %v", err)
+	}
+	if len(rows) != 4 || !reflect.DeepEqual(rows[0], ResultCSVColumns) {
+		t.Fatalf("Expected a header and 3 rows, got %v", rows)
+	}
+	want := []string{"science/physics", "Physics", "ada", "2024-05-01T09:30:00Z", "42", "33", "false",
+		"gravity", "g on Earth, in m/s²?", "9.8", "9.8|9.81", "true", "false", "2", "2", "4.000"}
+	if !reflect.DeepEqual(rows[1], want) {
+		t.Errorf("First row = %v, want %v", rows[1], want)
+	}
+	if rows[3][12] != "true" {
+		t.Errorf("Expected the last question to be skipped, got %v", rows[3])
+	}
+}
+
+func TestWriteResult_JUnit(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteResult(&buf, sampleResult(), "junit"); err != nil {
+		t.Fatalf("WriteResult() error = %v", err)
+	}
+
+	var suites junitSuites
+	if err := xml.Unmarshal(buf.Bytes(), &suites); err != nil {
+		t.Fatalf("Output is not XML: %v", err)
+	}
+	if len(suites.Suites) != 1 {
+		t.Fatalf("Expected one test suite, got %d", len(suites.Suites))
+	}
+	suite := suites.Suites[0]
+	// Three questions and the passing score; the wrong answer and the
+	// failed quiz are failures
+	if suite.Name != "science/physics" || suite.Tests != 4 || suite.Failures != 2 || suite.Skipped != 1 {
+		t.Errorf("Suite %s: tests=%d failures=%d skipped=%d, want 4, 2 and 1", suite.Name, suite.Tests, suite.Failures, suite.Skipped)
+	}
+	if failure := suite.Cases[1].Failure; failure == nil || !strings.Contains(failure.Text, "expected true") {
+		t.Errorf("Expected the wrong answer to fail with the expected answer, got %+v", failure)
+	}
+	if suite.Cases[2].Skipped == nil {
+		t.Error("Expected the unanswered question to be skipped")
+	}
+
+	passed := sampleResult()
+	passed.Passed = true
+	buf.Reset()
+	if err := WriteResultJUnit(&buf, passed); err != nil {
+		t.Fatalf("WriteResultJUnit() error = %v", err)
+	}
+	if strings.Count(buf.String(), "<failure") != 1 {
+		t.Errorf("Expected only the wrong answer to fail when the quiz is passed, got %s", buf.String())
+	}
+}
+
+func TestWriteResult_UnknownFormat(t *testing.T) {
+	if err := WriteResult(&bytes.Buffer{}, sampleResult(), "pdf"); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
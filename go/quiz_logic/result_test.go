@@ -0,0 +1,60 @@
+package quiz_logic
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveResult(t *testing.T) {
+	resultsPath := filepath.Join(t.TempDir(), "quiz", "results.jsonl")
+
+	results, err := LoadResults(resultsPath)
+	if err != nil || results != nil {
+		t.Fatalf("LoadResults() before any result = %v, %v", results, err)
+	}
+
+	finished := time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC)
+	saved := []Result{
+		{QuizID: "quiz01", Title: "Quiz 1", Learner: "ada", Finished: finished, Seed: 7, Correct: 3, Total: 4, Score: 75, Passed: true},
+		{QuizID: "science/physics", Title: "Physics", Learner: "Grace", Finished: finished.Add(time.Hour), Seed: 9, Correct: 1, Total: 4, Score: 25},
+	}
+	for _, result := range saved {
+		if err := SaveResult(resultsPath, result); err != nil {
+			t.Fatalf("SaveResult() error = %v", err)
+		}
+	}
+
+	results, err = LoadResults(resultsPath)
+	if err != nil {
+		t.Fatalf("LoadResults() error = %v", err)
+	}
+	if !reflect.DeepEqual(results, saved) {
+		t.Errorf("LoadResults() = %+v, want %+v", results, saved)
+	}
+
+	if got := FilterResults(results, "QUIZ01", ""); len(got) != 1 || got[0].Learner != "ada" {
+		t.Errorf("FilterResults() by quiz = %+v", got)
+	}
+	if got := FilterResults(results, "", "grace"); len(got) != 1 || got[0].QuizID != "science/physics" {
+		t.Errorf("FilterResults() by learner = %+v", got)
+	}
+	if got := FilterResults(results, "quiz01", "grace"); len(got) != 0 {
+		t.Errorf("FilterResults() by both = %+v, want none", got)
+	}
+}
+
+func TestLoadResults_Invalid(t *testing.T) {
+	resultsPath := filepath.Join(t.TempDir(), "results.jsonl")
+	if err := os.WriteFile(resultsPath, []byte("{\"quizId\": \"quiz01\"}\n{\"quizId\": \n"), 0644); err != nil {
+		t.Fatalf("Failed to write results: %v", err)
+	}
+
+	_, err := LoadResults(resultsPath)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("LoadResults() error = %v, want one naming line 2", err)
+	}
+}
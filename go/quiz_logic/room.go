@@ -0,0 +1,536 @@
+package quiz_logic
+
+import (
+	"crypto/rand"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Phases of a live room
+const (
+	roomLobby       = "lobby"       // players are joining
+	roomQuestion    = "question"    // a question is open for answers
+	roomLeaderboard = "leaderboard" // between questions
+	roomFinished    = "finished"
+)
+
+// Limits on the time a live room gives for each question
+const (
+	DefaultQuestionTime = 20 * time.Second
+	MinQuestionTime     = 5 * time.Second
+	MaxQuestionTime     = 5 * time.Minute
+)
+
+// roomCodeLetters leaves out letters and digits that are easily confused
+// when read off a projector
+const roomCodeLetters = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const roomCodeLength = 5
+
+// maxPlayerName is the longest name a player may join with, in characters
+const maxPlayerName = 30
+
+// Room is a live quiz an instructor hosts for a group of players. Every
+// question is open to all players at once for the same time, and a correct
+// answer earns more points the sooner it comes. Between questions the host
+// shows the leaderboard and moves on when ready.
+//
+// Clients receive roomMessages on their send channel. Every message is sent
+// with mu held, so a client may close its channel once it has left.
+type Room struct {
+	Code      string
+	HostToken string // lets a client control the room
+
+	quiz         *Quiz
+	questionTime time.Duration
+	resultsPath  string
+	created      time.Time
+
+	mu      sync.Mutex
+	phase   string
+	current int       // index of the question being asked or last asked
+	asked   time.Time // when the current question opened
+	timer   *time.Timer
+	players []*roomPlayer // in the order they joined
+	hosts   []*roomClient
+	results []Result // set when the room finishes
+}
+
+// roomClient is one browser connected to a room
+type roomClient struct {
+	send chan roomMessage
+}
+
+func newRoomClient() *roomClient {
+	return &roomClient{send: make(chan roomMessage, 32)}
+}
+
+// deliver passes a message on without waiting; a client that has fallen
+// that far behind misses it
+func (c *roomClient) deliver(message roomMessage) {
+	select {
+	case c.send <- message:
+	default:
+	}
+}
+
+type roomPlayer struct {
+	name    string
+	client  *roomClient // nil while disconnected
+	score   int         // points including speed bonuses
+	attempt *Quiz       // grades and records the player's answers
+
+	answered bool // for the current question
+	answer   string
+	spent    time.Duration
+}
+
+// roomMessage is what the room and its clients send each other as JSON.
+// Players send "answer"; the host sends "start", "next" and "end".
+type roomMessage struct {
+	Type        string             `json:"type"`
+	Code        string             `json:"code,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Name        string             `json:"name,omitempty"` // the player's, as their profile has it
+	Players     []string           `json:"players,omitempty"`
+	Number      int                `json:"number,omitempty"` // 1-based
+	Total       int                `json:"total,omitempty"`
+	Question    *QuestionView      `json:"question,omitempty"`
+	TimeLeft    float64            `json:"timeLeft,omitempty"` // seconds
+	TimeLimit   float64            `json:"timeLimit,omitempty"`
+	Answered    int                `json:"answered,omitempty"`
+	Answer      string             `json:"answer,omitempty"`
+	Expected    []string           `json:"expected,omitempty"`
+	Correct     *bool              `json:"correct,omitempty"` // for the player receiving it
+	Gained      int                `json:"gained,omitempty"`
+	Leaderboard []LeaderboardEntry `json:"leaderboard,omitempty"`
+	Last        bool               `json:"last,omitempty"` // no questions are left
+	Error       string             `json:"error,omitempty"`
+}
+
+// LeaderboardEntry is one player's standing in a live room
+type LeaderboardEntry struct {
+	Rank    int    `json:"rank"` // players with the same score share a rank
+	Name    string `json:"name"`
+	Score   int    `json:"score"`
+	Correct int    `json:"correct"`
+}
+
+// NewRoom opens a room in the lobby for the questions of quiz. Each
+// question is open for questionTime; results of every player are recorded
+// at resultsPath when the room finishes, unless it is empty.
+func NewRoom(quiz *Quiz, questionTime time.Duration, resultsPath string) (*Room, error) {
+	if len(quiz.Questions) == 0 {
+		return nil, errors.New("the quiz has no questions")
+	}
+	code, err := newRoomCode()
+	if err != nil {
+		return nil, err
+	}
+	token, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+	return &Room{
+		Code:         code,
+		HostToken:    token,
+		quiz:         quiz,
+		questionTime: questionTime,
+		resultsPath:  resultsPath,
+		created:      time.Now(),
+		phase:        roomLobby,
+	}, nil
+}
+
+// Title is the title of the quiz played in the room
+func (r *Room) Title() string {
+	return r.quiz.Config.Title
+}
+
+// Phase returns what the room is doing and how many players have joined
+func (r *Room) Phase() (phase string, players int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.phase, len(r.players)
+}
+
+// Leaderboard returns the players from the highest score down
+func (r *Room) Leaderboard() []LeaderboardEntry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.leaderboard()
+}
+
+// Results returns the result of every player once the room has finished
+func (r *Room) Results() []Result {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.results
+}
+
+// addHost connects a host client and brings it up to date
+func (r *Room) addHost(client *roomClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hosts = append(r.hosts, client)
+	r.catchUp(client, nil)
+}
+
+func (r *Room) removeHost(client *roomClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, host := range r.hosts {
+		if host == client {
+			r.hosts = append(r.hosts[:i], r.hosts[i+1:]...)
+			break
+		}
+	}
+}
+
+// join connects the player with the given profile, who plays under its
+// name. A name that is taken by a player who has lost their connection
+// takes their place, so a reloaded page keeps its score.
+func (r *Room) join(profile Profile, client *roomClient) (*roomPlayer, error) {
+	name := strings.TrimSpace(profile.Name)
+	if name == "" {
+		return nil, errors.New("enter a name to join")
+	}
+	if utf8.RuneCountInString(name) > maxPlayerName {
+		return nil, errors.New("that name is too long")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var player *roomPlayer
+	for _, p := range r.players {
+		if strings.EqualFold(p.name, name) {
+			player = p
+		}
+	}
+	switch {
+	case player != nil && player.client != nil:
+		return nil, errors.New("someone in the room already uses that name")
+	case player == nil && r.phase == roomFinished:
+		return nil, errors.New("the quiz in this room is over")
+	case player == nil:
+		profile.Name = name
+		player = &roomPlayer{name: name, attempt: r.newAttempt(profile)}
+		r.players = append(r.players, player)
+	}
+	player.client = client
+	r.catchUp(client, player)
+	r.broadcastPlayers()
+	return player, nil
+}
+
+// newAttempt starts the attempt a player's answers are recorded in
+func (r *Room) newAttempt(profile Profile) *Quiz {
+	attempt := &Quiz{
+		ID:        r.quiz.ID,
+		Config:    r.quiz.Config,
+		Questions: r.quiz.Questions,
+		Seed:      r.quiz.Seed,
+	}
+	attempt.SetProfile(profile)
+	attempt.startTime = time.Now()
+	// Questions asked before the player joined count as skipped
+	asked := 0
+	switch r.phase {
+	case roomQuestion:
+		asked = r.current
+	case roomLeaderboard:
+		asked = r.current + 1
+	}
+	for _, question := range r.quiz.Questions[:asked] {
+		attempt.record(question, "", false, true, 0)
+	}
+	attempt.totalQuestions = asked
+	return attempt
+}
+
+// leave disconnects a player; they can join again under the same name
+func (r *Room) leave(player *roomPlayer, client *roomClient) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if player.client != client {
+		return // already replaced by a newer connection
+	}
+	player.client = nil
+	r.broadcastPlayers()
+	if r.phase == roomQuestion && r.allAnswered() {
+		r.closeQuestion()
+	}
+}
+
+// command carries out a message from a host
+func (r *Room) command(kind string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch kind {
+	case "start":
+		if r.phase != roomLobby {
+			return errors.New("the quiz has already started")
+		}
+		r.ask(0)
+	case "next":
+		switch r.phase {
+		case roomQuestion:
+			r.closeQuestion() // stop waiting for the remaining answers
+		case roomLeaderboard:
+			if r.current+1 < len(r.quiz.Questions) {
+				r.ask(r.current + 1)
+			} else {
+				r.finish()
+			}
+		default:
+			return errors.New("there is no question to move on from")
+		}
+	case "end":
+		if r.phase == roomFinished {
+			return nil
+		}
+		if r.phase == roomQuestion {
+			r.closeQuestion()
+		}
+		r.finish()
+	default:
+		return errors.New("unknown command " + kind)
+	}
+	return nil
+}
+
+// submit takes a player's answer to the open question. Only the first
+// answer counts.
+func (r *Room) submit(player *roomPlayer, answer string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.phase != roomQuestion {
+		return errors.New("no question is open")
+	}
+	if player.answered {
+		return nil
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return errors.New("choose or type an answer first")
+	}
+	player.answered = true
+	player.answer = answer
+	player.spent = time.Since(r.asked)
+
+	if r.allAnswered() {
+		r.closeQuestion()
+		return nil
+	}
+	answered := 0
+	for _, p := range r.players {
+		if p.answered {
+			answered++
+		}
+	}
+	r.broadcast(roomMessage{Type: "answered", Number: r.current + 1, Answered: answered})
+	return nil
+}
+
+// ask opens the question at index i for everyone and starts its countdown
+func (r *Room) ask(i int) {
+	r.phase = roomQuestion
+	r.current = i
+	r.asked = time.Now()
+	for _, player := range r.players {
+		player.answered = false
+		player.answer = ""
+		player.spent = 0
+	}
+	r.timer = time.AfterFunc(r.questionTime, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.phase == roomQuestion && r.current == i {
+			r.closeQuestion()
+		}
+	})
+	r.broadcast(r.questionMessage())
+}
+
+// closeQuestion grades the open question, tells every player how they did
+// and shows the leaderboard
+func (r *Room) closeQuestion() {
+	r.timer.Stop()
+	question := r.quiz.Questions[r.current]
+	expected := question.getAnswers()
+	last := r.current+1 >= len(r.quiz.Questions)
+
+	right := 0
+	for _, player := range r.players {
+		correct, gained := false, 0
+		if player.answered {
+			correct = question.checkAnswer(player.answer)
+			if correct {
+				gained = speedPoints(question.getPoints(), player.spent, r.questionTime)
+				player.attempt.correctAnswers++
+				right++
+			}
+			player.attempt.record(question, player.answer, correct, false, player.spent)
+		} else {
+			player.attempt.record(question, "", false, true, r.questionTime)
+		}
+		player.attempt.totalQuestions++
+		player.score += gained
+
+		if player.client != nil {
+			player.client.deliver(roomMessage{
+				Type:     "reveal",
+				Number:   r.current + 1,
+				Answer:   player.answer,
+				Expected: expected,
+				Correct:  &correct,
+				Gained:   gained,
+			})
+		}
+	}
+	for _, host := range r.hosts {
+		host.deliver(roomMessage{Type: "reveal", Number: r.current + 1, Expected: expected, Answered: right})
+	}
+
+	r.phase = roomLeaderboard
+	r.broadcast(roomMessage{Type: "leaderboard", Number: r.current + 1, Total: len(r.quiz.Questions), Leaderboard: r.leaderboard(), Last: last})
+}
+
+// finish ends the room and records every player's result
+func (r *Room) finish() {
+	r.phase = roomFinished
+	finished := time.Now()
+	r.results = nil
+	for _, player := range r.players {
+		result := player.attempt.result()
+		result.Finished = finished
+		result.Seconds = finished.Sub(player.attempt.startTime).Seconds()
+		r.results = append(r.results, result)
+		if r.resultsPath != "" {
+			// Nobody is waiting on the outcome; a failed save must not
+			// keep the others from being recorded
+			_ = SaveResult(r.resultsPath, result)
+		}
+	}
+	r.broadcast(roomMessage{Type: "finished", Title: r.Title(), Leaderboard: r.leaderboard()})
+}
+
+// speedPoints is what a correct answer earns: half the question's worth
+// for being right and up to the other half for answering quickly, on a
+// scale of 1000 per point
+func speedPoints(points int, spent, limit time.Duration) int {
+	left := limit - spent
+	if left < 0 {
+		left = 0
+	}
+	return points * (500 + int(500*left/limit))
+}
+
+func (r *Room) allAnswered() bool {
+	connected := 0
+	for _, player := range r.players {
+		if player.client == nil {
+			continue
+		}
+		connected++
+		if !player.answered {
+			return false
+		}
+	}
+	return connected > 0
+}
+
+func (r *Room) leaderboard() []LeaderboardEntry {
+	entries := make([]LeaderboardEntry, len(r.players))
+	for i, player := range r.players {
+		entries[i] = LeaderboardEntry{Name: player.name, Score: player.score, Correct: player.attempt.correctAnswers}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Score > entries[j].Score
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+	return entries
+}
+
+func (r *Room) questionMessage() roomMessage {
+	question := r.quiz.Questions[r.current]
+	left := r.questionTime - time.Since(r.asked)
+	if left < 0 {
+		left = 0
+	}
+	return roomMessage{
+		Type:   "question",
+		Number: r.current + 1,
+		Total:  len(r.quiz.Questions),
+		Question: &QuestionView{
+			ID:      question.getID(),
+			Number:  r.current + 1,
+			Text:    question.getQuestion(),
+			Type:    question.getType(),
+			Options: question.getOptions(),
+		},
+		TimeLeft:  left.Seconds(),
+		TimeLimit: r.questionTime.Seconds(),
+	}
+}
+
+// catchUp sends a client that just connected what everyone else sees
+func (r *Room) catchUp(client *roomClient, player *roomPlayer) {
+	welcome := roomMessage{Type: "welcome", Code: r.Code, Title: r.Title(), Total: len(r.quiz.Questions)}
+	if player != nil {
+		welcome.Name = player.name
+	}
+	client.deliver(welcome)
+	switch r.phase {
+	case roomQuestion:
+		if player == nil || !player.answered {
+			client.deliver(r.questionMessage())
+		}
+	case roomLeaderboard:
+		client.deliver(roomMessage{Type: "leaderboard", Number: r.current + 1, Total: len(r.quiz.Questions), Leaderboard: r.leaderboard(), Last: r.current+1 >= len(r.quiz.Questions)})
+	case roomFinished:
+		client.deliver(roomMessage{Type: "finished", Title: r.Title(), Leaderboard: r.leaderboard()})
+	}
+}
+
+// broadcastPlayers tells everyone who is in the room
+func (r *Room) broadcastPlayers() {
+	var names []string
+	for _, player := range r.players {
+		if player.client != nil {
+			names = append(names, player.name)
+		}
+	}
+	r.broadcast(roomMessage{Type: "players", Players: names})
+}
+
+func (r *Room) broadcast(message roomMessage) {
+	for _, host := range r.hosts {
+		host.deliver(message)
+	}
+	for _, player := range r.players {
+		if player.client != nil {
+			player.client.deliver(message)
+		}
+	}
+}
+
+func newRoomCode() (string, error) {
+	b := make([]byte, roomCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		b[i] = roomCodeLetters[int(b[i])%len(roomCodeLetters)]
+	}
+	return string(b), nil
+}
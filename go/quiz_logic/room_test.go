@@ -0,0 +1,297 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func newTestRoom(t *testing.T, questionTime time.Duration) (*Room, string) {
+	t.Helper()
+	quiz := &Quiz{ID: "live", Config: Config{Title: "Live", PassingScore: 50}, Questions: sessionQuestions()}
+	resultsPath := filepath.Join(t.TempDir(), "results.jsonl")
+	room, err := NewRoom(quiz, questionTime, resultsPath)
+	if err != nil {
+		t.Fatalf("NewRoom() error = %v", err)
+	}
+	return room, resultsPath
+}
+
+// await returns the next message of the given type sent to the client
+func await(t *testing.T, client *roomClient, kind string) roomMessage {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case message := <-client.send:
+			if message.Type == kind {
+				return message
+			}
+		case <-timeout:
+			t.Fatalf("No %q message arrived", kind)
+		}
+	}
+}
+
+func TestRoom(t *testing.T) {
+	room, resultsPath := newTestRoom(t, time.Minute)
+	if len(room.Code) != roomCodeLength || strings.Trim(room.Code, roomCodeLetters) != "" {
+		t.Errorf("Code = %q", room.Code)
+	}
+
+	host, ada, bob := newRoomClient(), newRoomClient(), newRoomClient()
+	room.addHost(host)
+	if welcome := await(t, host, "welcome"); welcome.Code != room.Code || welcome.Title != "Live" || welcome.Total != 3 {
+		t.Errorf("welcome = %+v", welcome)
+	}
+	adaPlayer, err := room.join(Profile{Name: "Ada"}, ada)
+	if err != nil {
+		t.Fatalf("join(Ada) error = %v", err)
+	}
+	bobPlayer, err := room.join(Profile{Name: " Bob "}, bob)
+	if err != nil {
+		t.Fatalf("join(Bob) error = %v", err)
+	}
+	if _, err := room.join(Profile{Name: "ada"}, newRoomClient()); err == nil {
+		t.Error("Expected an error joining with a name in use")
+	}
+	if _, err := room.join(Profile{Name: "  "}, newRoomClient()); err == nil {
+		t.Error("Expected an error joining without a name")
+	}
+	if players := await(t, host, "players"); len(players.Players) != 1 {
+		t.Errorf("players = %v, want Ada", players.Players)
+	}
+	if players := await(t, host, "players"); strings.Join(players.Players, ",") != "Ada,Bob" {
+		t.Errorf("players = %v, want Ada,Bob", players.Players)
+	}
+
+	if err := room.submit(adaPlayer, "2"); err == nil {
+		t.Error("Expected an error answering before the quiz starts")
+	}
+	if err := room.command("start"); err != nil {
+		t.Fatalf("start error = %v", err)
+	}
+	question := await(t, ada, "question")
+	if question.Number != 1 || question.Total != 3 || question.Question.Text != "Capital of France?" || question.TimeLimit != 60 {
+		t.Errorf("question = %+v", question)
+	}
+	if err := room.command("start"); err == nil {
+		t.Error("Expected an error starting twice")
+	}
+
+	room.submit(adaPlayer, OptionAnswer(1))
+	room.submit(adaPlayer, OptionAnswer(0)) // only the first answer counts
+	if answered := await(t, host, "answered"); answered.Answered != 1 {
+		t.Errorf("answered = %d, want 1", answered.Answered)
+	}
+	room.submit(bobPlayer, OptionAnswer(0)) // the last answer closes the question
+
+	reveal := await(t, ada, "reveal")
+	if reveal.Correct == nil || !*reveal.Correct || reveal.Gained <= 1000 || reveal.Gained > 2000 {
+		t.Errorf("Ada's reveal = %+v, want correct with a speed bonus on 2 points", reveal)
+	}
+	if reveal := await(t, bob, "reveal"); reveal.Correct == nil || *reveal.Correct || reveal.Gained != 0 || reveal.Expected[0] != "Paris" {
+		t.Errorf("Bob's reveal = %+v", reveal)
+	}
+	if reveal := await(t, host, "reveal"); reveal.Answered != 1 {
+		t.Errorf("host reveal = %+v, want one right", reveal)
+	}
+	board := await(t, host, "leaderboard")
+	if len(board.Leaderboard) != 2 || board.Leaderboard[0].Name != "Ada" || board.Leaderboard[0].Rank != 1 || board.Leaderboard[1].Rank != 2 || board.Last {
+		t.Errorf("leaderboard = %+v", board)
+	}
+
+	// The host can close a question before everyone has answered
+	room.command("next")
+	await(t, bob, "question")
+	room.submit(bobPlayer, "false")
+	room.command("next")
+	if reveal := await(t, ada, "reveal"); reveal.Correct == nil || *reveal.Correct {
+		t.Errorf("Ada's reveal without an answer = %+v", reveal)
+	}
+
+	if err := room.command("end"); err != nil {
+		t.Fatalf("end error = %v", err)
+	}
+	finished := await(t, bob, "finished")
+	if len(finished.Leaderboard) != 2 {
+		t.Errorf("finished = %+v", finished)
+	}
+	if _, err := room.join(Profile{Name: "Cy"}, newRoomClient()); err == nil {
+		t.Error("Expected an error joining a finished room")
+	}
+
+	results, err := LoadResults(resultsPath)
+	if err != nil || len(results) != 2 {
+		t.Fatalf("LoadResults() = %v, %v, want both players", results, err)
+	}
+	for _, result := range results {
+		if result.QuizID != "live" || result.Total != 2 || result.Correct != 1 || result.Score != 50 || !result.Passed || len(result.Questions) != 2 {
+			t.Errorf("Result for %s = %+v", result.Learner, result)
+		}
+		if skipped := result.Questions[1].Skipped; skipped != (result.Learner == "Ada") {
+			t.Errorf("Second question skipped by %s = %v", result.Learner, skipped)
+		}
+	}
+}
+
+func TestRoom_Countdown(t *testing.T) {
+	room, _ := newTestRoom(t, 50*time.Millisecond)
+	ada := newRoomClient()
+	room.join(Profile{Name: "Ada"}, ada)
+	room.command("start")
+	await(t, ada, "question")
+
+	// Nobody answers, so the question closes when the time runs out
+	if reveal := await(t, ada, "reveal"); reveal.Number != 1 || *reveal.Correct {
+		t.Errorf("reveal = %+v", reveal)
+	}
+	if phase, _ := room.Phase(); phase != roomLeaderboard {
+		t.Errorf("Phase() = %q, want %q", phase, roomLeaderboard)
+	}
+}
+
+func TestRoom_Rejoin(t *testing.T) {
+	room, _ := newTestRoom(t, time.Minute)
+	ada := newRoomClient()
+	adaPlayer, _ := room.join(Profile{Name: "Ada"}, ada)
+	room.command("start")
+	room.submit(adaPlayer, OptionAnswer(1))
+	score := await(t, ada, "reveal").Gained
+
+	// A reloaded page takes the place of the lost connection
+	room.leave(adaPlayer, ada)
+	again := newRoomClient()
+	if _, err := room.join(Profile{Name: "Ada"}, again); err != nil {
+		t.Fatalf("join again error = %v", err)
+	}
+	if board := await(t, again, "leaderboard"); len(board.Leaderboard) != 1 || board.Leaderboard[0].Score != score {
+		t.Errorf("leaderboard after rejoining = %+v, want score %d", board.Leaderboard, score)
+	}
+
+	// Someone joining late has missed the questions already asked
+	room.join(Profile{Name: "Bob"}, newRoomClient())
+	room.command("end")
+	for _, result := range room.Results() {
+		if result.Total != 1 || len(result.Questions) != 1 {
+			t.Errorf("Result for %s = %+v, want one question", result.Learner, result)
+		}
+		if result.Learner == "Bob" && !result.Questions[0].Skipped {
+			t.Error("Expected the question Bob missed to count as skipped")
+		}
+	}
+}
+
+func TestSpeedPoints(t *testing.T) {
+	tests := []struct {
+		points       int
+		spent, limit time.Duration
+		want         int
+	}{
+		{1, 0, 10 * time.Second, 1000},
+		{1, 5 * time.Second, 10 * time.Second, 750},
+		{1, 10 * time.Second, 10 * time.Second, 500},
+		{1, 12 * time.Second, 10 * time.Second, 500},
+		{3, 5 * time.Second, 10 * time.Second, 2250},
+	}
+	for _, tt := range tests {
+		if got := speedPoints(tt.points, tt.spent, tt.limit); got != tt.want {
+			t.Errorf("speedPoints(%d, %v, %v) = %d, want %d", tt.points, tt.spent, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestWebHandler_LiveRoom(t *testing.T) {
+	server, resultsPath := newTestWebServer(t, `{}`)
+
+	call(t, "POST", server.URL+"/rooms", `{"quiz": "capitals", "seconds": 30}`, http.StatusUnauthorized)
+	callAs(t, testInstructorToken, "POST", server.URL+"/rooms", `{"quiz": "capitals", "seconds": 1}`, http.StatusBadRequest)
+	callAs(t, testInstructorToken, "POST", server.URL+"/rooms", `{"quiz": "nope"}`, http.StatusNotFound)
+	call(t, "GET", server.URL+"/rooms/ZZZZZ", "", http.StatusNotFound)
+
+	var room struct{ Code, HostToken, Title string }
+	request, _ := http.NewRequest("POST", server.URL+"/rooms", strings.NewReader(`{"quiz": "capitals", "seconds": 30}`))
+	request.Header.Set("Authorization", "Bearer "+testInstructorToken)
+	response, err := http.DefaultClient.Do(request)
+	if err != nil || response.StatusCode != http.StatusCreated {
+		t.Fatalf("POST /rooms = %v, %v", response, err)
+	}
+	defer response.Body.Close()
+	json.NewDecoder(response.Body).Decode(&room)
+	if room.Title != "Capitals" || room.Code == "" || room.HostToken == "" {
+		t.Fatalf("Opened room = %+v", room)
+	}
+
+	live := "ws" + strings.TrimPrefix(server.URL, "http") + "/rooms/" + strings.ToLower(room.Code) + "/live"
+	if _, response, err := websocket.DefaultDialer.Dial(live+"?host=wrong", nil); err == nil || response.StatusCode != http.StatusForbidden {
+		t.Errorf("Dial with a wrong host token = %v, want forbidden", err)
+	}
+	host := dialRoom(t, live+"?host="+room.HostToken)
+	player := dialRoom(t, live+"?name=grace")
+	if welcome := readRoom(t, player, "welcome"); welcome.Name != "Grace Hopper" {
+		t.Errorf("welcome = %+v, want the name of grace's profile", welcome)
+	}
+	readRoom(t, host, "players")
+
+	host.WriteJSON(roomMessage{Type: "start"})
+	for number := 1; number <= 2; number++ {
+		question := readRoom(t, player, "question")
+		answer := "Rome"
+		if question.Question.Options != nil {
+			answer = OptionAnswer(indexOf(question.Question.Options, "Paris"))
+		}
+		player.WriteJSON(roomMessage{Type: "answer", Answer: answer})
+		if reveal := readRoom(t, player, "reveal"); reveal.Correct == nil || !*reveal.Correct {
+			t.Errorf("reveal for question %d = %+v", number, reveal)
+		}
+		readRoom(t, host, "leaderboard")
+		host.WriteJSON(roomMessage{Type: "next"})
+	}
+	if finished := readRoom(t, player, "finished"); len(finished.Leaderboard) != 1 || finished.Leaderboard[0].Correct != 2 {
+		t.Errorf("finished = %+v", finished)
+	}
+
+	results, err := LoadResults(resultsPath)
+	if err != nil || len(results) != 1 || results[0].Learner != "Grace Hopper" || results[0].LearnerID != "grace" || results[0].Group != "navy" || results[0].Score != 100 {
+		t.Errorf("Recorded results = %+v, %v", results, err)
+	}
+}
+
+func dialRoom(t *testing.T, url string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial(%s) error = %v", url, err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// readRoom returns the next message of the given type on the connection
+func readRoom(t *testing.T, conn *websocket.Conn, kind string) roomMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var message roomMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			t.Fatalf("No %q message arrived: %v", kind, err)
+		}
+		if message.Type == kind {
+			return message
+		}
+	}
+}
+
+func indexOf(items []string, item string) int {
+	for i, it := range items {
+		if it == item {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,193 @@
+package quiz_logic
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// maxRoomMessage is the largest message a client may send, in bytes
+const maxRoomMessage = 4096
+
+type webRooms struct {
+	catalog      *Catalog
+	resultsPath  string
+	profilesPath string // players are looked up in, see LookupProfile
+	upgrader     websocket.Upgrader
+
+	mu     sync.Mutex
+	byCode map[string]*Room
+}
+
+func newWebRooms(catalog *Catalog, resultsPath, profilesPath string) *webRooms {
+	return &webRooms{catalog: catalog, resultsPath: resultsPath, profilesPath: profilesPath, byCode: make(map[string]*Room)}
+}
+
+func (s *webRooms) open(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Quiz    string  `json:"quiz"`
+		Seconds float64 `json:"seconds"` // per question; 0 is DefaultQuestionTime
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+	questionTime := DefaultQuestionTime
+	if request.Seconds != 0 {
+		questionTime = time.Duration(request.Seconds * float64(time.Second))
+	}
+	if questionTime < MinQuestionTime || questionTime > MaxQuestionTime {
+		writeAPIError(w, http.StatusBadRequest, "the time for each question must be between "+MinQuestionTime.String()+" and "+MaxQuestionTime.String())
+		return
+	}
+
+	quizzes, _ := s.catalog.Quizzes()
+	info, ok := FindQuiz(quizzes, request.Quiz)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "no quiz with ID "+request.Quiz)
+		return
+	}
+	quiz, err := info.Load(0)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	room, err := NewRoom(quiz, questionTime, s.resultsPath)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	for code, old := range s.byCode {
+		if time.Since(old.created) > webSessionLifetime {
+			delete(s.byCode, code)
+		}
+	}
+	for s.byCode[room.Code] != nil {
+		if room.Code, err = newRoomCode(); err != nil {
+			s.mu.Unlock()
+			writeAPIError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+	s.byCode[room.Code] = room
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusCreated, map[string]string{
+		"code":      room.Code,
+		"hostToken": room.HostToken,
+		"title":     room.Title(),
+	})
+}
+
+func (s *webRooms) get(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	phase, players := room.Phase()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"code":    room.Code,
+		"title":   room.Title(),
+		"phase":   phase,
+		"players": players,
+	})
+}
+
+// live connects a browser to a room over WebSocket: the host with
+// ?host=token, a player with ?name=name
+func (s *webRooms) live(w http.ResponseWriter, r *http.Request) {
+	room, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	host := r.URL.Query().Has("host")
+	if host && r.URL.Query().Get("host") != room.HostToken {
+		writeAPIError(w, http.StatusForbidden, "wrong host token")
+		return
+	}
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // the upgrader has replied
+	}
+	defer conn.Close()
+	conn.SetReadLimit(maxRoomMessage)
+
+	client := newRoomClient()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for message := range client.send {
+			if err := conn.WriteJSON(message); err != nil {
+				conn.Close() // ends the read loop below
+				return
+			}
+		}
+	}()
+
+	var player *roomPlayer
+	if host {
+		room.addHost(client)
+	} else if player, err = s.join(room, r, client); err != nil {
+		client.deliver(roomMessage{Type: "error", Error: err.Error()})
+		close(client.send)
+		<-done
+		return
+	}
+	defer func() {
+		if host {
+			room.removeHost(client)
+		} else {
+			room.leave(player, client)
+		}
+		// Once the room has let go of the client nothing sends to it
+		close(client.send)
+		<-done
+	}()
+
+	for {
+		var message roomMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			if _, broken := err.(*json.SyntaxError); broken {
+				continue
+			}
+			return
+		}
+		switch {
+		case host:
+			err = room.command(message.Type)
+		case message.Type == "answer":
+			err = room.submit(player, message.Answer)
+		default:
+			continue
+		}
+		if err != nil {
+			client.deliver(roomMessage{Type: "error", Error: err.Error()})
+		}
+	}
+}
+
+// join adds the player a request to go live names to the room
+func (s *webRooms) join(room *Room, r *http.Request, client *roomClient) (*roomPlayer, error) {
+	profile, err := LookupProfile(s.profilesPath, r.URL.Query().Get("name"))
+	if err != nil {
+		return nil, err
+	}
+	return room.join(profile, client)
+}
+
+func (s *webRooms) lookup(w http.ResponseWriter, r *http.Request) (*Room, bool) {
+	code := strings.ToUpper(strings.TrimSpace(r.PathValue("code")))
+	s.mu.Lock()
+	room, ok := s.byCode[code]
+	s.mu.Unlock()
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "no room with code "+code)
+	}
+	return room, ok
+}
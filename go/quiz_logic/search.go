@@ -0,0 +1,65 @@
+package quiz_logic
+
+import (
+	"sort"
+	"strings"
+)
+
+// QuizFilter narrows down a quiz list; fields left empty match every quiz
+type QuizFilter struct {
+	Category    string // the category or any category below it
+	Keyword     string // in the title, description or tags, ignoring case
+	MaxDuration int    // in minutes; quizzes without a duration are left out
+}
+
+// Match reports whether the quiz passes every part of the filter
+func (f QuizFilter) Match(quiz QuizInfo) bool {
+	if f.Category != "" && quiz.Category != f.Category && !strings.HasPrefix(quiz.Category, f.Category+"/") {
+		return false
+	}
+	if f.MaxDuration > 0 && (quiz.Duration == 0 || quiz.Duration > f.MaxDuration) {
+		return false
+	}
+	if f.Keyword != "" {
+		keyword := strings.ToLower(f.Keyword)
+		text := strings.ToLower(quiz.Title + "\n" + quiz.Description + "\n" + strings.Join(quiz.Tags, "\n"))
+		if !strings.Contains(text, keyword) {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterQuizzes returns the quizzes that match the filter, keeping their IDs
+func FilterQuizzes(quizzes []QuizInfo, filter QuizFilter) []QuizInfo {
+	var matches []QuizInfo
+	for _, quiz := range quizzes {
+		if filter.Match(quiz) {
+			matches = append(matches, quiz)
+		}
+	}
+	return matches
+}
+
+// Categories lists every category used by the quizzes, including parents
+// of nested categories such as "science" for "science/physics", in order
+func Categories(quizzes []QuizInfo) []string {
+	seen := make(map[string]bool)
+	for _, quiz := range quizzes {
+		for category := quiz.Category; category != ""; {
+			seen[category] = true
+			cut := strings.LastIndex(category, "/")
+			if cut < 0 {
+				break
+			}
+			category = category[:cut]
+		}
+	}
+
+	var categories []string
+	for category := range seen {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
@@ -0,0 +1,54 @@
+package quiz_logic
+
+import (
+	"reflect"
+	"testing"
+)
+
+var searchQuizzes = []QuizInfo{
+	{ID: "planets", Title: "Planets", Category: "science/astronomy", Duration: 10, Tags: []string{"space"}},
+	{ID: "forces", Title: "Forces", Category: "science/physics", Duration: 25, Description: "Newton's laws"},
+	{ID: "capitals", Title: "Capitals", Category: "geography", Duration: 5},
+	{ID: "warm-up", Title: "Warm-up", Description: "A few quick questions"},
+}
+
+func searchIDs(quizzes []QuizInfo) []string {
+	var ids []string
+	for _, quiz := range quizzes {
+		ids = append(ids, quiz.ID)
+	}
+	return ids
+}
+
+func TestFilterQuizzes(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter QuizFilter
+		want   []string
+	}{
+		{"Everything", QuizFilter{}, []string{"planets", "forces", "capitals", "warm-up"}},
+		{"Category and below", QuizFilter{Category: "science"}, []string{"planets", "forces"}},
+		{"Nested category", QuizFilter{Category: "science/physics"}, []string{"forces"}},
+		{"Category prefix is not a parent", QuizFilter{Category: "sci"}, nil},
+		{"Keyword in title", QuizFilter{Keyword: "PLANET"}, []string{"planets"}},
+		{"Keyword in description", QuizFilter{Keyword: "newton"}, []string{"forces"}},
+		{"Keyword in tags", QuizFilter{Keyword: "space"}, []string{"planets"}},
+		{"Duration leaves out unknown", QuizFilter{MaxDuration: 10}, []string{"planets", "capitals"}},
+		{"Combined", QuizFilter{Category: "science", MaxDuration: 30, Keyword: "laws"}, []string{"forces"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := searchIDs(FilterQuizzes(searchQuizzes, tt.filter)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FilterQuizzes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCategories(t *testing.T) {
+	want := []string{"geography", "science", "science/astronomy", "science/physics"}
+	if got := Categories(searchQuizzes); !reflect.DeepEqual(got, want) {
+		t.Errorf("Categories() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,28 @@
+package quiz_logic
+
+import (
+	"math/rand"
+	"time"
+)
+
+// resolveSeed picks the seed for a quiz form. An explicit seed wins over
+// the one in the config, and a fresh seed is drawn when neither is set.
+func resolveSeed(seed, configSeed int64) int64 {
+	if seed != 0 {
+		return seed
+	}
+	if configSeed != 0 {
+		return configSeed
+	}
+	return time.Now().UnixNano()
+}
+
+// random returns the quiz's random source, seeding it on first use so the
+// chosen seed can be recorded with the results
+func (q *Quiz) random() *rand.Rand {
+	if q.rng == nil {
+		q.Seed = resolveSeed(q.Seed, q.Config.Seed)
+		q.rng = rand.New(rand.NewSource(q.Seed))
+	}
+	return q.rng
+}
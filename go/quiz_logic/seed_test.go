@@ -0,0 +1,73 @@
+package quiz_logic
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSeed(t *testing.T) {
+	tests := []struct {
+		name       string
+		seed       int64
+		configSeed int64
+		want       int64
+	}{
+		{"Explicit seed wins", 7, 9, 7},
+		{"Config seed used", 0, 9, 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveSeed(tt.seed, tt.configSeed); got != tt.want {
+				t.Errorf("resolveSeed(%d, %d) = %d, want %d", tt.seed, tt.configSeed, got, tt.want)
+			}
+		})
+	}
+
+	if got := resolveSeed(0, 0); got == 0 {
+		t.Error("resolveSeed(0, 0) returned 0, want a fresh seed")
+	}
+}
+
+func TestQuiz_SelectQuestionsSeeded(t *testing.T) {
+	quizPath := filepath.Join("../../quiz", "test02")
+	config, err := LoadConfig(quizPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	form := func(seed int64) []string {
+		quiz := &Quiz{Config: config, Seed: seed}
+		if err := quiz.selectQuestions(quizPath); err != nil {
+			t.Fatalf("selectQuestions() error = %v", err)
+		}
+		if quiz.Seed != seed {
+			t.Errorf("Expected seed %d to be recorded, got %d", seed, quiz.Seed)
+		}
+		var texts []string
+		for _, question := range quiz.Questions {
+			texts = append(texts, question.getQuestion())
+		}
+		return texts
+	}
+
+	for _, seed := range []int64{1, 42, 1337} {
+		first, second := form(seed), form(seed)
+		if len(first) != len(second) {
+			t.Fatalf("Seed %d: got %d and %d questions", seed, len(first), len(second))
+		}
+		for i := range first {
+			if first[i] != second[i] {
+				t.Errorf("Seed %d: question %d differs: %q vs %q", seed, i+1, first[i], second[i])
+			}
+		}
+	}
+}
+
+func TestQuiz_RandomRecordsSeed(t *testing.T) {
+	quiz := &Quiz{Config: Config{Seed: 99}}
+	quiz.random()
+	if quiz.Seed != 99 {
+		t.Errorf("Expected config seed 99 to be recorded, got %d", quiz.Seed)
+	}
+}
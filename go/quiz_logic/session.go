@@ -0,0 +1,124 @@
+package quiz_logic
+
+import (
+	"strconv"
+	"time"
+)
+
+// Session steps through one attempt at a quiz a question at a time, for
+// interfaces that draw questions their own way. It grades and times the
+// answers exactly as Run does.
+type Session struct {
+	quiz     *Quiz
+	next     int       // index of the question being asked
+	asked    time.Time // when the current question was shown
+	finished bool
+}
+
+// QuestionView is what an interface needs to show a question
+type QuestionView struct {
+	ID            string   `json:"id"`
+	Number        int      `json:"number"` // 1-based position in the quiz
+	Text          string   `json:"text"`
+	Type          string   `json:"type"`
+	Options       []string `json:"options,omitempty"` // in display order; nil when the answer is typed
+	AllowSkipping bool     `json:"allowSkipping"`
+}
+
+// OptionAnswer is the answer that picks the option at the 0-based index i
+// of a QuestionView
+func OptionAnswer(i int) string {
+	return strconv.Itoa(i + 1)
+}
+
+// Begin starts an attempt; the time limit runs from now
+func (q *Quiz) Begin() *Session {
+	q.startTime = time.Now()
+	q.totalQuestions = len(q.Questions)
+	q.correctAnswers = 0
+	q.questions = nil
+	return &Session{quiz: q, asked: q.startTime}
+}
+
+// Current returns the question being asked, or false once every question
+// was answered or the time is up
+func (s *Session) Current() (QuestionView, bool) {
+	if s.finished || s.next >= len(s.quiz.Questions) || s.TimeUp() {
+		return QuestionView{}, false
+	}
+	question := s.quiz.Questions[s.next]
+	return QuestionView{
+		ID:            question.getID(),
+		Number:        s.next + 1,
+		Text:          question.getQuestion(),
+		Type:          question.getType(),
+		Options:       question.getOptions(),
+		AllowSkipping: s.quiz.Config.Settings.AllowSkipping,
+	}, true
+}
+
+// Answer grades the answer to the current question and moves on to the
+// next. An empty answer skips the question when skipping is allowed.
+func (s *Session) Answer(answer string) (correct, skipped bool) {
+	if _, ok := s.Current(); !ok {
+		return false, false
+	}
+	question := s.quiz.Questions[s.next]
+	spent := time.Since(s.asked)
+	s.next++
+	s.asked = time.Now()
+
+	if answer == "" && s.quiz.Config.Settings.AllowSkipping {
+		s.quiz.record(question, "", false, true, spent)
+		return false, true
+	}
+	correct = question.checkAnswer(answer)
+	s.quiz.record(question, answer, correct, false, spent)
+	if correct {
+		s.quiz.correctAnswers++
+	}
+	return correct, false
+}
+
+// Progress returns how many questions were answered or skipped so far and
+// how many there are
+func (s *Session) Progress() (done, total int) {
+	return s.next, len(s.quiz.Questions)
+}
+
+// TimeLeft returns the time remaining and the whole time limit, or false
+// when the quiz has no time limit or does not show a timer
+func (s *Session) TimeLeft() (left, limit time.Duration, ok bool) {
+	if s.quiz.Config.TimeLimit == 0 || !s.quiz.Config.Settings.ShowTimer {
+		return 0, 0, false
+	}
+	limit = time.Duration(s.quiz.Config.TimeLimit) * time.Minute
+	left = limit - time.Since(s.quiz.startTime)
+	if left < 0 {
+		left = 0
+	}
+	return left, limit, true
+}
+
+// TimeUp reports whether the time limit ended the attempt. As in Run, the
+// limit is only enforced when the quiz shows its timer.
+func (s *Session) TimeUp() bool {
+	return s.quiz.Config.Settings.ShowTimer && s.quiz.isTimeUp()
+}
+
+// Finish ends the attempt, counting questions that were not reached as
+// skipped, and returns the result
+func (s *Session) Finish() Result {
+	if !s.finished {
+		for _, question := range s.quiz.Questions[s.next:] {
+			s.quiz.record(question, "", false, true, 0)
+		}
+		s.next = len(s.quiz.Questions)
+		s.finished = true
+	}
+
+	result := s.quiz.result()
+	result.Finished = time.Now()
+	result.Seconds = result.Finished.Sub(s.quiz.startTime).Seconds()
+	return result
+}
@@ -0,0 +1,109 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func sessionQuestions() []Question {
+	return []Question{
+		&MultipleChoiceQuestion{
+			BaseQuestion: BaseQuestion{ID: "capital", QuestionText: "Capital of France?", Answers: []string{"Paris"}, Points: 2},
+			Options:      []string{"London", "Paris"},
+		},
+		&TrueFalseQuestion{BaseQuestion: BaseQuestion{ID: "earth", QuestionText: "The Earth is flat.", Answers: []string{"false"}}},
+		&FillInBlankQuestion{BaseQuestion: BaseQuestion{ID: "water", QuestionText: "Water boils at ___ degrees", Answers: []string{"100"}}},
+	}
+}
+
+func TestSession(t *testing.T) {
+	quiz := &Quiz{ID: "session", Config: Config{Title: "Session", PassingScore: 50}, Questions: sessionQuestions()}
+	quiz.Config.Settings.AllowSkipping = true
+	session := quiz.Begin()
+
+	view, ok := session.Current()
+	if !ok || view.ID != "capital" || view.Number != 1 || len(view.Options) != 2 || !view.AllowSkipping {
+		t.Fatalf("Current() = %+v, %v", view, ok)
+	}
+	if correct, skipped := session.Answer(OptionAnswer(1)); !correct || skipped {
+		t.Errorf("Answer(second option) = %v, %v, want correct", correct, skipped)
+	}
+	if correct, skipped := session.Answer(""); correct || !skipped {
+		t.Errorf("Answer(\"\") = %v, %v, want skipped", correct, skipped)
+	}
+	if done, total := session.Progress(); done != 2 || total != 3 {
+		t.Errorf("Progress() = %d, %d, want 2, 3", done, total)
+	}
+	if _, _, ok := session.TimeLeft(); ok {
+		t.Error("TimeLeft() reported a limit for a quiz without one")
+	}
+
+	result := session.Finish()
+	if _, ok := session.Current(); ok {
+		t.Error("Current() returned a question after Finish()")
+	}
+	if result.Correct != 1 || result.Total != 3 || result.Score != 33 || result.Passed {
+		t.Errorf("Finish() = %d/%d (%d%%) passed=%v, want 1/3 (33%%) failed", result.Correct, result.Total, result.Score, result.Passed)
+	}
+	if len(result.Questions) != 3 || result.Questions[0].Points != 2 || !result.Questions[1].Skipped || !result.Questions[2].Skipped {
+		t.Errorf("Finish() questions = %+v, want the unreached question skipped", result.Questions)
+	}
+}
+
+func TestSession_TimeUp(t *testing.T) {
+	quiz := &Quiz{Config: Config{TimeLimit: 1}, Questions: sessionQuestions()}
+	quiz.Config.Settings.ShowTimer = true
+	session := quiz.Begin()
+
+	left, limit, ok := session.TimeLeft()
+	if !ok || limit != time.Minute || left <= 0 || left > limit {
+		t.Errorf("TimeLeft() = %v, %v, %v", left, limit, ok)
+	}
+
+	quiz.startTime = time.Now().Add(-2 * time.Minute)
+	if !session.TimeUp() {
+		t.Error("TimeUp() = false after the limit")
+	}
+	if _, ok := session.Current(); ok {
+		t.Error("Current() returned a question after the time was up")
+	}
+	if correct, skipped := session.Answer("Paris"); correct || skipped {
+		t.Error("Answer() graded an answer after the time was up")
+	}
+	if result := session.Finish(); result.Total != 3 || len(result.Questions) != 3 {
+		t.Errorf("Finish() = %+v, want every question counted", result)
+	}
+}
+
+// lateReader gives its answer only after the time limit of quiz is up
+type lateReader struct {
+	quiz   *Quiz
+	answer *strings.Reader
+}
+
+func (r lateReader) Read(p []byte) (int, error) {
+	r.quiz.startTime = time.Now().Add(-2 * time.Minute)
+	return r.answer.Read(p)
+}
+
+func TestQuiz_Run_LateAnswer(t *testing.T) {
+	quiz := &Quiz{Config: Config{Title: "Late", TimeLimit: 1}, Questions: sessionQuestions()}
+	quiz.Config.Settings.ShowTimer = true
+	quiz.Config.Settings.ShowFeedbackAfterEach = true
+	var out bytes.Buffer
+	quiz.In = lateReader{quiz, strings.NewReader("Paris\n")}
+	quiz.Out = &out
+
+	result := quiz.Run()
+	if strings.Contains(out.String(), "Correct!") || strings.Contains(out.String(), "Incorrect.") {
+		t.Errorf("Run() gave feedback on an answer after the time was up:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Time's up!") {
+		t.Errorf("Run() did not say the time was up:\n%s", out.String())
+	}
+	if result.Correct != 0 {
+		t.Errorf("Correct = %d, want the late answer not to count", result.Correct)
+	}
+}
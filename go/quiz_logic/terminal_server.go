@@ -0,0 +1,383 @@
+package quiz_logic
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/term"
+)
+
+// TerminalServer runs the menu for learners who connect from a terminal,
+// with a plain TCP client such as telnet or nc, or with ssh. Every
+// connection gets its own Menu in its own goroutine, reading and writing
+// only that connection, so learners take quizzes independently while
+// sharing one catalog and one results file.
+type TerminalServer struct {
+	Catalog  *Catalog
+	Seed     int64                    // see Menu
+	Save     func(Result) error       // see Menu; called from many goroutines at once
+	Results  func() ([]Result, error) // see Menu; called from many goroutines at once
+	Ranking  LeaderboardOptions       // see Menu
+	Profiles string                   // file of learner profiles, see ProfilesPath; empty records names only
+	HostKey  ssh.Signer               // identifies the server to ssh clients, see LoadHostKey
+	Log      *log.Logger              // notes who connects; nil logs nothing
+}
+
+// ServeTCP runs a menu for each connection accepted on l until accepting
+// fails. Learners are asked for their name or profile ID first; with
+// Profiles set, only a profile that exists lets them in.
+func (s *TerminalServer) ServeTCP(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveTCP(conn)
+	}
+}
+
+// ServeSSH runs a menu for each ssh session on connections accepted on l
+// until accepting fails. The user name picks the learner's profile, and with
+// Profiles set only a profile that exists lets them in. No password is
+// asked for, as the server is meant for a trusted network such as a lab.
+func (s *TerminalServer) ServeSSH(l net.Listener) error {
+	if s.HostKey == nil {
+		return errors.New("ssh needs a host key")
+	}
+	config := &ssh.ServerConfig{
+		NoClientAuth: true,
+		NoClientAuthCallback: func(conn ssh.ConnMetadata) (*ssh.Permissions, error) {
+			profile, err := s.profile(conn.User())
+			if err != nil {
+				s.logf("%s from %s was refused: %v", conn.User(), conn.RemoteAddr(), err)
+				return nil, err
+			}
+			// The profile travels to the session in the extensions
+			return &ssh.Permissions{Extensions: map[string]string{
+				"id":    profile.ID,
+				"name":  profile.Name,
+				"group": profile.Group,
+			}}, nil
+		},
+	}
+	config.AddHostKey(s.HostKey)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveSSH(conn, config)
+	}
+}
+
+func (s *TerminalServer) serveTCP(conn net.Conn) {
+	defer conn.Close()
+	in := bufio.NewReader(&telnetReader{r: conn})
+	out := crlfWriter{conn}
+
+	var profile Profile
+	for profile.Name == "" {
+		fmt.Fprint(out, "Your name or ID: ")
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return
+		}
+		learner := strings.TrimSpace(line)
+		if learner == "" {
+			continue
+		}
+		if profile, err = s.profile(learner); err != nil {
+			fmt.Fprintf(out, "Sorry, %v.\n", err)
+		}
+	}
+	s.run(profile, conn.RemoteAddr(), in, out)
+}
+
+func (s *TerminalServer) serveSSH(conn net.Conn, config *ssh.ServerConfig) {
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		s.logf("ssh connection from %s failed: %v", conn.RemoteAddr(), err)
+		conn.Close()
+		return
+	}
+	defer sshConn.Close()
+	go ssh.DiscardRequests(requests)
+
+	for newChannel := range channels {
+		if newChannel.ChannelType() != "session" {
+			newChannel.Reject(ssh.UnknownChannelType, "only sessions are supported")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go s.serveSSHSession(sshConn, channel, requests)
+	}
+}
+
+// serveSSHSession runs the menu once the client asks for a shell. With a
+// terminal the server echoes and edits lines itself, as ssh clients leave
+// that to the other end.
+func (s *TerminalServer) serveSSHSession(conn *ssh.ServerConn, channel ssh.Channel, requests <-chan *ssh.Request) {
+	defer channel.Close()
+	var width, height int
+	pty := false
+	for request := range requests {
+		switch request.Type {
+		case "pty-req":
+			var payload struct {
+				Term          string
+				Columns, Rows uint32
+				Width, Height uint32
+				Modes         string
+			}
+			ssh.Unmarshal(request.Payload, &payload)
+			width, height, pty = int(payload.Columns), int(payload.Rows), true
+			request.Reply(true, nil)
+		case "env":
+			request.Reply(true, nil)
+		case "shell":
+			request.Reply(true, nil)
+			var in io.Reader = channel
+			var out io.Writer = channel
+			if pty {
+				terminal := term.NewTerminal(channel, "")
+				if width > 0 && height > 0 {
+					terminal.SetSize(width, height)
+				}
+				go resizeTerminal(terminal, requests)
+				in, out = &terminalReader{terminal: terminal}, terminal
+			} else {
+				go ssh.DiscardRequests(requests)
+			}
+			extensions := conn.Permissions.Extensions
+			s.run(Profile{ID: extensions["id"], Name: extensions["name"], Group: extensions["group"]}, conn.RemoteAddr(), in, out)
+			channel.SendRequest("exit-status", false, ssh.Marshal(struct{ Status uint32 }{0}))
+			return
+		default:
+			request.Reply(false, nil) // such as exec: only the menu is offered
+		}
+	}
+}
+
+// resizeTerminal follows the size of the learner's window, so that long
+// lines they type wrap where they expect
+func resizeTerminal(terminal *term.Terminal, requests <-chan *ssh.Request) {
+	for request := range requests {
+		if request.Type == "window-change" {
+			var size struct {
+				Columns, Rows uint32
+				Width, Height uint32
+			}
+			if ssh.Unmarshal(request.Payload, &size) == nil && size.Columns > 0 && size.Rows > 0 {
+				terminal.SetSize(int(size.Columns), int(size.Rows))
+			}
+		}
+		request.Reply(false, nil)
+	}
+}
+
+// profile returns the profile of the learner who signs in as ref. With
+// Profiles set it must be one of them, as connections never add profiles;
+// without, the learner is known by the name ref alone.
+func (s *TerminalServer) profile(ref string) (Profile, error) {
+	if s.Profiles == "" {
+		return Profile{Name: ref}, nil
+	}
+	profiles, err := LoadProfiles(s.Profiles)
+	if err != nil {
+		s.logf("%v", err)
+		return Profile{}, errors.New("profiles cannot be read right now")
+	}
+	profile, ok := FindProfile(profiles, ref)
+	if !ok {
+		return Profile{}, fmt.Errorf("there is no profile called %s; ask your instructor to add one", ref)
+	}
+	return profile, nil
+}
+
+func (s *TerminalServer) run(profile Profile, addr net.Addr, in io.Reader, out io.Writer) {
+	learner := profile.ID
+	if learner == "" {
+		learner = profile.Name
+	}
+	s.logf("%s connected from %s", learner, addr)
+	menu := &Menu{
+		Catalog: s.Catalog,
+		In:      in,
+		Out:     out,
+		Seed:    s.Seed,
+		Profile: profile,
+		Save:    s.Save,
+		Results: s.Results,
+		Ranking: s.Ranking,
+	}
+	fmt.Fprintf(out, "Hello, %s!\n", profile.Name)
+	menu.Run()
+	s.logf("%s from %s left", learner, addr)
+}
+
+func (s *TerminalServer) logf(format string, args ...interface{}) {
+	if s.Log != nil {
+		s.Log.Printf(format, args...)
+	}
+}
+
+// HostKeyPath returns the file the ssh host key is kept in by default:
+// ssh_host_ed25519_key next to the user's settings file
+func HostKeyPath() (string, error) {
+	settingsPath, err := UserSettingsPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(filepath.Dir(settingsPath), "ssh_host_ed25519_key"), nil
+}
+
+// LoadHostKey reads the ssh host key at path, creating a new one there the
+// first time so that learners' ssh clients recognise the server later
+func LoadHostKey(path string) (ssh.Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		data, err = newHostKey(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading host key: %v", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("error reading host key %s: %v", path, err)
+	}
+	return signer, nil
+}
+
+func newHostKey(path string) ([]byte, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	block, err := ssh.MarshalPrivateKey(key, "quiz terminal server")
+	if err != nil {
+		return nil, err
+	}
+	data := pem.EncodeToMemory(block)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	return data, os.WriteFile(path, data, 0600)
+}
+
+// terminalReader reads the lines typed at a term.Terminal, which echoes
+// them and lets the learner edit them first
+type terminalReader struct {
+	terminal *term.Terminal
+	pending  []byte
+}
+
+func (r *terminalReader) Read(p []byte) (int, error) {
+	if len(r.pending) == 0 {
+		line, err := r.terminal.ReadLine()
+		if err != nil {
+			return 0, err // io.EOF for Ctrl-C and Ctrl-D
+		}
+		r.pending = []byte(line + "\n")
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// Telnet commands, see RFC 854
+const (
+	telnetSE   = 240
+	telnetSB   = 250
+	telnetWILL = 251
+	telnetDONT = 254
+	telnetIAC  = 255
+)
+
+// telnetReader drops the commands a telnet client mixes into what the
+// learner types, such as its option negotiation
+type telnetReader struct {
+	r     io.Reader
+	state int // where in a command the last byte was, see below
+}
+
+// States of telnetReader
+const (
+	telnetText = iota
+	telnetCommand
+	telnetOption
+	telnetSubnegotiation
+	telnetSubnegotiationIAC
+)
+
+func (t *telnetReader) Read(p []byte) (int, error) {
+	for {
+		n, err := t.r.Read(p)
+		kept := 0
+		for _, b := range p[:n] {
+			switch t.state {
+			case telnetText:
+				if b == telnetIAC {
+					t.state = telnetCommand
+				} else if b != 0 { // telnet sends a carriage return as CR NUL
+					p[kept] = b
+					kept++
+				}
+			case telnetCommand:
+				switch {
+				case b == telnetIAC: // an escaped 255
+					p[kept] = b
+					kept++
+					t.state = telnetText
+				case b >= telnetWILL && b <= telnetDONT:
+					t.state = telnetOption
+				case b == telnetSB:
+					t.state = telnetSubnegotiation
+				default:
+					t.state = telnetText
+				}
+			case telnetOption:
+				t.state = telnetText
+			case telnetSubnegotiation:
+				if b == telnetIAC {
+					t.state = telnetSubnegotiationIAC
+				}
+			case telnetSubnegotiationIAC:
+				if b == telnetSE {
+					t.state = telnetText
+				} else {
+					t.state = telnetSubnegotiation
+				}
+			}
+		}
+		if kept > 0 || err != nil {
+			return kept, err
+		}
+	}
+}
+
+// crlfWriter ends lines with CR LF as network terminals expect
+type crlfWriter struct {
+	w io.Writer
+}
+
+func (c crlfWriter) Write(p []byte) (int, error) {
+	if _, err := c.w.Write([]byte(strings.ReplaceAll(string(p), "\n", "\r\n"))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,196 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// newTestTerminalServer serves the test catalog and collects the results
+func newTestTerminalServer(t *testing.T) (*TerminalServer, func() []Result) {
+	t.Helper()
+	var mu sync.Mutex
+	var results []Result
+	server := &TerminalServer{
+		Catalog: newTestCatalog(t, `{}`),
+		Save: func(result Result) error {
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, result)
+			return nil
+		},
+	}
+	return server, func() []Result {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]Result{}, results...)
+	}
+}
+
+func listen(t *testing.T) net.Listener {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+func TestTerminalServer_TCP(t *testing.T) {
+	server, results := newTestTerminalServer(t)
+	server.Profiles = filepath.Join(t.TempDir(), "profiles.json")
+	for _, learner := range []string{"ada", "bob"} {
+		if _, err := AddProfile(server.Profiles, learner, "", map[string]string{"bob": "7b"}[learner]); err != nil {
+			t.Fatalf("AddProfile() error = %v", err)
+		}
+	}
+	listener := listen(t)
+	go server.ServeTCP(listener)
+
+	// Two learners at once, each in a session of their own; the first
+	// one's telnet client negotiates options before it is typed at, and
+	// the other first gives a name no profile has
+	inputs := map[string]string{
+		"ada": "\xff\xfb\x18\xff\xfa\x18\x00xterm\xff\xf0\r\nada\r\n2\r\ncapitals\r\nParis\r\nRome\r\n6\r\n",
+		"bob": "zed\nbob\n2\ncapitals\nLyon\nRome\n6\n",
+	}
+	transcripts := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for learner, input := range inputs {
+		wg.Add(1)
+		go func(learner, input string) {
+			defer wg.Done()
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				t.Errorf("Dial() error = %v", err)
+				return
+			}
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(5 * time.Second))
+			conn.Write([]byte(input))
+			transcript, _ := io.ReadAll(conn)
+			mu.Lock()
+			transcripts[learner] = string(transcript)
+			mu.Unlock()
+		}(learner, input)
+	}
+	wg.Wait()
+
+	for learner, transcript := range transcripts {
+		if !strings.Contains(transcript, "Hello, "+learner+"!\r\n") || !strings.HasSuffix(transcript, "Goodbye!\r\n") {
+			t.Errorf("Transcript for %s:\n%s", learner, transcript)
+		}
+	}
+	if !strings.Contains(transcripts["ada"], "Score: 2/2") || !strings.Contains(transcripts["bob"], "Score: 1/2") {
+		t.Errorf("Scores are not kept apart:\nada: %s\nbob: %s", transcripts["ada"], transcripts["bob"])
+	}
+	if !strings.Contains(transcripts["bob"], "Sorry, there is no profile called zed") {
+		t.Errorf("Expected a name without a profile to be refused:\n%s", transcripts["bob"])
+	}
+	if profiles, _ := LoadProfiles(server.Profiles); len(profiles) != 2 {
+		t.Errorf("Got %d profiles, want none added by connections", len(profiles))
+	}
+	if strings.Contains(transcripts["ada"], "\nParis") {
+		t.Error("Expected the answers typed not to be repeated")
+	}
+
+	recorded := results()
+	if len(recorded) != 2 {
+		t.Fatalf("Recorded %d results, want 2", len(recorded))
+	}
+	for _, result := range recorded {
+		if want := map[string]int{"ada": 2, "bob": 1}[result.Learner]; result.Correct != want || result.QuizID != "capitals" {
+			t.Errorf("Result for %q = %+v, want %d correct", result.Learner, result, want)
+		}
+		if want := map[string]string{"ada": "", "bob": "7b"}[result.Learner]; result.LearnerID != result.Learner || result.Group != want {
+			t.Errorf("Result for %q = %+v, want their profile", result.Learner, result)
+		}
+	}
+}
+
+func TestTerminalServer_SSH(t *testing.T) {
+	server, results := newTestTerminalServer(t)
+	keyPath := filepath.Join(t.TempDir(), "keys", "host_key")
+	key, err := LoadHostKey(keyPath)
+	if err != nil {
+		t.Fatalf("LoadHostKey() error = %v", err)
+	}
+	again, err := LoadHostKey(keyPath)
+	if err != nil || !bytes.Equal(again.PublicKey().Marshal(), key.PublicKey().Marshal()) {
+		t.Fatalf("LoadHostKey() the second time = %v, want the key created the first time", err)
+	}
+	server.HostKey = key
+	server.Profiles = filepath.Join(t.TempDir(), "profiles.json")
+	if _, err := AddProfile(server.Profiles, "Cy Young", "cy", ""); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+	listener := listen(t)
+	go server.ServeSSH(listener)
+
+	if _, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+		User:            "zed",
+		HostKeyCallback: ssh.FixedHostKey(key.PublicKey()),
+		Timeout:         5 * time.Second,
+	}); err == nil {
+		t.Error("ssh.Dial() as a user without a profile succeeded")
+	}
+	client, err := ssh.Dial("tcp", listener.Addr().String(), &ssh.ClientConfig{
+		User:            "cy",
+		HostKeyCallback: ssh.FixedHostKey(key.PublicKey()),
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("ssh.Dial() error = %v", err)
+	}
+	defer client.Close()
+	session, err := client.NewSession()
+	if err != nil {
+		t.Fatalf("NewSession() error = %v", err)
+	}
+	defer session.Close()
+	if err := session.RequestPty("xterm", 24, 80, ssh.TerminalModes{}); err != nil {
+		t.Fatalf("RequestPty() error = %v", err)
+	}
+	var output bytes.Buffer
+	session.Stdout = &output
+	session.Stdin = strings.NewReader("2\rcapitals\rParis\rRome\r6\r")
+	if err := session.Shell(); err != nil {
+		t.Fatalf("Shell() error = %v", err)
+	}
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Wait() error = %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The session did not end")
+	}
+
+	transcript := output.String()
+	// The server echoes what is typed, as the client's terminal is raw
+	if !strings.Contains(transcript, "Hello, Cy Young!") || !strings.Contains(transcript, "Enter your answer: Paris") || !strings.Contains(transcript, "Goodbye!") {
+		t.Errorf("Transcript:\n%s", transcript)
+	}
+	if recorded := results(); len(recorded) != 1 || recorded[0].LearnerID != "cy" || recorded[0].Correct != 2 {
+		t.Errorf("Recorded results = %+v", recorded)
+	}
+}
+
+func TestTelnetReader(t *testing.T) {
+	input := "a\xff\xfd\x01b\xff\xffc\r\x00\xff\xfa\x1f\x00\x50\xff\xf0d\n"
+	got, err := io.ReadAll(&telnetReader{r: strings.NewReader(input)})
+	if err != nil || string(got) != "ab\xffc\rd\n" {
+		t.Errorf("telnetReader read %q, %v", got, err)
+	}
+}
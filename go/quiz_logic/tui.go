@@ -0,0 +1,542 @@
+package quiz_logic
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// Terminal control sequences used by the full-screen interface
+const (
+	altScreenOn  = "\x1b[?1049h"
+	altScreenOff = "\x1b[?1049l"
+	hideCursor   = "\x1b[?25l"
+	showCursor   = "\x1b[?25h"
+	clearScreen  = "\x1b[H\x1b[2J"
+
+	styleBold    = "\x1b[1m"
+	styleDim     = "\x1b[2m"
+	styleReverse = "\x1b[7m"
+	styleRed     = "\x1b[31m"
+	styleGreen   = "\x1b[32m"
+	styleYellow  = "\x1b[33m"
+	styleReset   = "\x1b[0m"
+)
+
+type keyKind int
+
+const (
+	keyRune keyKind = iota
+	keyUp
+	keyDown
+	keyLeft
+	keyRight
+	keyEnter
+	keyBackspace
+	keyEscape
+	keyTab
+	keyInterrupt // Ctrl+C, or the end of input
+)
+
+type key struct {
+	kind keyKind
+	r    rune // for keyRune
+}
+
+// parseKeys turns what one read from a terminal in raw mode returned into
+// keys. Arrow keys arrive as escape sequences; anything else is text.
+func parseKeys(data []byte) []key {
+	var keys []key
+	for len(data) > 0 {
+		if data[0] == 0x1b {
+			if len(data) >= 3 && (data[1] == '[' || data[1] == 'O') {
+				switch data[2] {
+				case 'A':
+					keys = append(keys, key{kind: keyUp})
+				case 'B':
+					keys = append(keys, key{kind: keyDown})
+				case 'C':
+					keys = append(keys, key{kind: keyRight})
+				case 'D':
+					keys = append(keys, key{kind: keyLeft})
+				}
+				data = data[3:]
+				continue
+			}
+			keys = append(keys, key{kind: keyEscape})
+			data = data[1:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		switch r {
+		case '\r', '\n':
+			keys = append(keys, key{kind: keyEnter})
+		case 0x7f, 0x08:
+			keys = append(keys, key{kind: keyBackspace})
+		case '\t':
+			keys = append(keys, key{kind: keyTab})
+		case 0x03, 0x04:
+			keys = append(keys, key{kind: keyInterrupt})
+		default:
+			if r >= ' ' && r != utf8.RuneError {
+				keys = append(keys, key{kind: keyRune, r: r})
+			}
+		}
+	}
+	return keys
+}
+
+// CanUseTUI reports whether standard input and output are a terminal that
+// can show the full-screen interface. Dumb terminals and pipes cannot.
+func CanUseTUI() bool {
+	switch os.Getenv("TERM") {
+	case "", "dumb":
+		return false
+	}
+	return term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// TUI is the full-screen terminal interface: a quiz list chosen with the
+// arrow keys, one screen per question with a progress bar and timer, and a
+// results screen. It runs quizzes through a Session like the line mode.
+type TUI struct {
+	keys    <-chan key
+	out     io.Writer
+	size    func() (width, height int)
+	restore func() error
+}
+
+// OpenTUI switches the terminal to the full-screen interface until Close
+func OpenTUI() (*TUI, error) {
+	fd := int(os.Stdin.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return nil, fmt.Errorf("error starting full-screen interface: %v", err)
+	}
+
+	keys := make(chan key, 16)
+	go func() {
+		buf := make([]byte, 64)
+		for {
+			n, err := os.Stdin.Read(buf)
+			for _, k := range parseKeys(buf[:n]) {
+				keys <- k
+			}
+			if err != nil {
+				close(keys)
+				return
+			}
+		}
+	}()
+
+	fmt.Fprint(os.Stdout, altScreenOn+hideCursor)
+	size := func() (int, int) {
+		width, height, err := term.GetSize(int(os.Stdout.Fd()))
+		if err != nil || width <= 0 || height <= 0 {
+			return 80, 24 // such as a terminal that does not report its size
+		}
+		return width, height
+	}
+	restore := func() error {
+		fmt.Fprint(os.Stdout, showCursor+altScreenOff)
+		return term.Restore(fd, state)
+	}
+	return &TUI{keys: keys, out: os.Stdout, size: size, restore: restore}, nil
+}
+
+// Close gives the terminal back in the state it was found in
+func (t *TUI) Close() error {
+	return t.restore()
+}
+
+// ChooseQuiz shows the quizzes from list, asking it again every second so
+// reloads show up, and returns the one picked, or false to quit
+func (t *TUI) ChooseQuiz(list func() ([]QuizInfo, []QuizProblem)) (QuizInfo, bool) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	var filter QuizFilter
+	selected, category := 0, 0 // category 0 is every category
+	searching := false
+	for {
+		quizzes, problems := list()
+		categories := Categories(quizzes)
+		if category > len(categories) {
+			category = 0
+		}
+		filter.Category = ""
+		if category > 0 {
+			filter.Category = categories[category-1]
+		}
+		matches := FilterQuizzes(quizzes, filter)
+		selected = clamp(selected, len(matches))
+
+		t.drawQuizList(matches, problems, filter, selected, searching)
+
+		k, ticked := t.wait(ticker.C)
+		if ticked {
+			continue
+		}
+		if searching {
+			switch k.kind {
+			case keyRune:
+				filter.Keyword += string(k.r)
+			case keyBackspace:
+				if keyword := []rune(filter.Keyword); len(keyword) > 0 {
+					filter.Keyword = string(keyword[:len(keyword)-1])
+				}
+			case keyEnter, keyEscape, keyTab:
+				searching = false
+			case keyInterrupt:
+				return QuizInfo{}, false
+			}
+			selected = 0
+			continue
+		}
+
+		switch {
+		case k.kind == keyUp || k.kind == keyRune && k.r == 'k':
+			selected--
+		case k.kind == keyDown || k.kind == keyRune && k.r == 'j':
+			selected++
+		case k.kind == keyEnter:
+			if len(matches) > 0 {
+				return matches[selected], true
+			}
+		case k.kind == keyRune && k.r == '/':
+			searching = true
+		case k.kind == keyTab || k.kind == keyRune && k.r == 'c':
+			category = (category + 1) % (len(categories) + 1)
+			selected = 0
+		case k.kind == keyEscape || k.kind == keyInterrupt || k.kind == keyRune && k.r == 'q':
+			return QuizInfo{}, false
+		}
+	}
+}
+
+func (t *TUI) drawQuizList(quizzes []QuizInfo, problems []QuizProblem, filter QuizFilter, selected int, searching bool) {
+	width, height := t.size()
+	lines := []string{styleBold + fit("Quizzes", width) + styleReset}
+
+	category := filter.Category
+	if category == "" {
+		category = "all"
+	}
+	search := filter.Keyword
+	if searching {
+		search += "█"
+	}
+	lines = append(lines, fit(fmt.Sprintf("Category: %s   Search: %s   (%d quizzes)", category, search, len(quizzes)), width), "")
+
+	// Scroll so the selected quiz stays on screen
+	rows := height - 7
+	if rows < 1 {
+		rows = 1
+	}
+	first := 0
+	if selected >= rows {
+		first = selected - rows + 1
+	}
+	if len(quizzes) == 0 {
+		lines = append(lines, "  No quizzes match.")
+	}
+	for i := first; i < len(quizzes) && i < first+rows; i++ {
+		quiz := quizzes[i]
+		line := fmt.Sprintf("%s  %s%s", quiz.ID, quiz.Title, quizDetails(quiz))
+		if i == selected {
+			lines = append(lines, styleReverse+fit("> "+line, width)+styleReset)
+		} else {
+			lines = append(lines, fit("  "+line, width))
+		}
+	}
+
+	lines = append(lines, "")
+	if len(problems) > 0 {
+		lines = append(lines, styleYellow+fit(fmt.Sprintf("%d quizzes could not be loaded; run \"quiz lint\" for details.", len(problems)), width)+styleReset)
+	}
+	help := "↑/↓ choose  Enter start  / search  c category  q quit"
+	if searching {
+		help = "Type to search  Enter done"
+	}
+	lines = append(lines, styleDim+fit(help, width)+styleReset)
+	t.draw(lines)
+}
+
+// RunQuiz takes the quiz on screen and shows the result. Ctrl+C ends the
+// attempt early; unanswered questions count as skipped.
+func (t *TUI) RunQuiz(quiz *Quiz) Result {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	session := quiz.Begin()
+	for {
+		view, ok := session.Current()
+		if !ok {
+			break
+		}
+		answer, stop := t.ask(quiz, session, view, ticker.C)
+		if stop || session.TimeUp() {
+			break // an answer given after the time is up does not count
+		}
+
+		correct, skipped := session.Answer(answer)
+		if skipped || !quiz.Config.Settings.ShowFeedbackAfterEach {
+			continue
+		}
+		feedback := styleRed + "Incorrect." + styleReset
+		if correct {
+			feedback = styleGreen + "Correct!" + styleReset
+		}
+		chosen, _ := strconv.Atoi(answer) // highlight the option that was picked
+		for {
+			t.drawQuestion(quiz, session, view, chosen-1, []rune(answer), feedback, "Press any key to continue")
+			if _, ticked := t.wait(ticker.C); !ticked || session.TimeUp() {
+				break
+			}
+		}
+	}
+
+	timeUp := session.TimeUp()
+	result := session.Finish()
+	t.drawResult(result, timeUp)
+	t.wait(nil)
+	return result
+}
+
+// ask shows a question until it is answered, returning the answer, or stop
+// when the time is up or the learner ends the quiz
+func (t *TUI) ask(quiz *Quiz, session *Session, view QuestionView, tick <-chan time.Time) (answer string, stop bool) {
+	selected := 0
+	var input []rune
+	message := ""
+	for {
+		if len(view.Options) > 0 {
+			t.drawQuestion(quiz, session, view, selected, nil, message, "")
+		} else {
+			t.drawQuestion(quiz, session, view, -1, append(input, '█'), message, "")
+		}
+
+		k, ticked := t.wait(tick)
+		if ticked {
+			if session.TimeUp() {
+				return "", true
+			}
+			continue
+		}
+		message = ""
+
+		switch k.kind {
+		case keyInterrupt:
+			return "", true
+		case keyEscape:
+			if view.AllowSkipping {
+				return "", false
+			}
+			message = "This question cannot be skipped."
+		case keyUp:
+			if len(view.Options) > 0 {
+				selected = (selected + len(view.Options) - 1) % len(view.Options)
+			}
+		case keyDown, keyTab:
+			if len(view.Options) > 0 {
+				selected = (selected + 1) % len(view.Options)
+			}
+		case keyEnter:
+			if len(view.Options) > 0 {
+				return OptionAnswer(selected), false
+			}
+			if len(input) > 0 || view.AllowSkipping {
+				return strings.TrimSpace(string(input)), false
+			}
+			message = "Type an answer first."
+		case keyBackspace:
+			if len(input) > 0 {
+				input = input[:len(input)-1]
+			}
+		case keyRune:
+			if len(view.Options) == 0 {
+				input = append(input, k.r)
+			} else if n := int(k.r - '1'); n >= 0 && n < len(view.Options) && n < 9 {
+				selected = n
+			}
+		}
+	}
+}
+
+// drawQuestion shows a question with the option at selected highlighted,
+// or the typed input when the question has no options
+func (t *TUI) drawQuestion(quiz *Quiz, session *Session, view QuestionView, selected int, input []rune, message, help string) {
+	width, _ := t.size()
+	lines := []string{styleBold + fit(quiz.Config.Title, width) + styleReset}
+
+	done, total := session.Progress()
+	lines = append(lines, fit(fmt.Sprintf("Question %d of %d  %s", view.Number, total, bar(done, total, 20)), width))
+	if left, limit, ok := session.TimeLeft(); ok {
+		timer := fmt.Sprintf("Time left %02d:%02d  %s", int(left.Minutes()), int(left.Seconds())%60, bar(int(left), int(limit), 20))
+		if left < time.Minute {
+			timer = styleRed + fit(timer, width) + styleReset
+		} else {
+			timer = fit(timer, width)
+		}
+		lines = append(lines, timer)
+	}
+
+	lines = append(lines, "")
+	lines = append(lines, wrapText(view.Text, width)...)
+	lines = append(lines, "")
+
+	if len(view.Options) > 0 {
+		for i, option := range view.Options {
+			line := fmt.Sprintf("%d. %s", i+1, option)
+			if i == selected {
+				lines = append(lines, styleReverse+fit("> "+line, width)+styleReset)
+			} else {
+				lines = append(lines, fit("  "+line, width))
+			}
+		}
+	} else {
+		lines = append(lines, fit("Answer: "+string(input), width))
+	}
+
+	lines = append(lines, "")
+	if message != "" {
+		lines = append(lines, message)
+	}
+	if help == "" {
+		help = "Type your answer  Enter answer"
+		if len(view.Options) > 0 {
+			help = "↑/↓ choose  Enter answer"
+		}
+		if view.AllowSkipping {
+			help += "  Esc skip"
+		}
+		help += "  Ctrl+C end quiz"
+	}
+	lines = append(lines, styleDim+fit(help, width)+styleReset)
+	t.draw(lines)
+}
+
+func (t *TUI) drawResult(result Result, timeUp bool) {
+	width, height := t.size()
+	lines := []string{styleBold + fit("Quiz completed: "+result.Title, width) + styleReset}
+	if timeUp {
+		lines = append(lines, styleYellow+"Time's up!"+styleReset)
+	}
+	lines = append(lines, fmt.Sprintf("Score: %d/%d (%d%%)", result.Correct, result.Total, result.Score))
+	if result.Passed {
+		lines = append(lines, styleGreen+"Congratulations! You passed!"+styleReset)
+	} else {
+		lines = append(lines, styleRed+"Sorry, you didn't pass. Keep practicing!"+styleReset)
+	}
+	lines = append(lines, fmt.Sprintf("Seed: %d", result.Seed), "")
+
+	for i, question := range result.Questions {
+		if len(lines) >= height-2 {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(result.Questions)-i))
+			break
+		}
+		mark := styleRed + "✗" + styleReset
+		switch {
+		case question.Skipped:
+			mark = styleDim + "-" + styleReset
+		case question.Correct:
+			mark = styleGreen + "✓" + styleReset
+		}
+		lines = append(lines, mark+" "+fit(fmt.Sprintf("%d. %s", i+1, question.Question), width-2))
+	}
+
+	lines = append(lines, "", styleDim+"Press any key to continue"+styleReset)
+	t.draw(lines)
+}
+
+// ShowError shows err until a key is pressed
+func (t *TUI) ShowError(err error) {
+	width, _ := t.size()
+	lines := []string{styleRed + "Error" + styleReset, ""}
+	lines = append(lines, wrapText(err.Error(), width)...)
+	lines = append(lines, "", styleDim+"Press any key to continue"+styleReset)
+	t.draw(lines)
+	t.wait(nil)
+}
+
+// wait returns the next key, or ticked when tick fires first. The end of
+// input reads as Ctrl+C.
+func (t *TUI) wait(tick <-chan time.Time) (k key, ticked bool) {
+	select {
+	case k, ok := <-t.keys:
+		if !ok {
+			return key{kind: keyInterrupt}, false
+		}
+		return k, false
+	case <-tick:
+		return key{}, true
+	}
+}
+
+// draw replaces the screen with lines. The terminal is in raw mode, so
+// every line break needs a carriage return.
+func (t *TUI) draw(lines []string) {
+	fmt.Fprint(t.out, clearScreen+strings.Join(lines, "\r\n"))
+}
+
+// fit cuts s to width characters
+func fit(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// wrapText breaks text into lines of at most width characters at spaces
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, fit(line, width))
+				line = word
+			}
+		}
+		lines = append(lines, fit(line, width))
+	}
+	return lines
+}
+
+// bar draws a bar of width characters filled in the proportion value/max
+func bar(value, max, width int) string {
+	filled := 0
+	if max > 0 {
+		filled = value * width / max
+	}
+	filled = clamp(filled, width+1)
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// clamp keeps i within 0 and n-1, or at 0 when n is 0
+func clamp(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
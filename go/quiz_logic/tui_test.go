@@ -0,0 +1,119 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	got := parseKeys([]byte("a\x1b[B\x1b[A\r\x7f\x1bé\x03"))
+	want := []key{
+		{kind: keyRune, r: 'a'}, {kind: keyDown}, {kind: keyUp}, {kind: keyEnter},
+		{kind: keyBackspace}, {kind: keyEscape}, {kind: keyRune, r: 'é'}, {kind: keyInterrupt},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseKeys() = %v, want %v", got, want)
+	}
+}
+
+// newTestTUI returns a TUI that reads the given keys and draws to out
+func newTestTUI(out *bytes.Buffer, keys ...key) *TUI {
+	ch := make(chan key, len(keys))
+	for _, k := range keys {
+		ch <- k
+	}
+	close(ch)
+	return &TUI{keys: ch, out: out, size: func() (int, int) { return 80, 24 }}
+}
+
+func typed(text string) []key {
+	var keys []key
+	for _, r := range text {
+		keys = append(keys, key{kind: keyRune, r: r})
+	}
+	return keys
+}
+
+func TestTUI_RunQuiz(t *testing.T) {
+	quiz := &Quiz{ID: "tui", Config: Config{Title: "TUI Quiz"}, Questions: sessionQuestions()}
+	quiz.Config.Settings.ShowFeedbackAfterEach = true
+
+	keys := []key{
+		{kind: keyDown}, {kind: keyEnter}, // Paris
+		{kind: keyRune, r: 'x'},                   // dismiss the feedback
+		{kind: keyEscape},                         // cannot skip
+		{kind: keyRune, r: '1'}, {kind: keyEnter}, // True
+		{kind: keyRune, r: 'x'},
+		{kind: keyEnter}, // no answer typed yet
+	}
+	keys = append(keys, typed("1000")...)
+	keys = append(keys, key{kind: keyBackspace}, key{kind: keyEnter}, key{kind: keyRune, r: 'x'}, key{kind: keyRune, r: 'x'})
+
+	var out bytes.Buffer
+	result := newTestTUI(&out, keys...).RunQuiz(quiz)
+
+	if result.Correct != 2 || result.Total != 3 {
+		t.Errorf("RunQuiz() = %d/%d correct, want 2/3", result.Correct, result.Total)
+	}
+	if got := result.Questions[2].Answer; got != "100" {
+		t.Errorf("Typed answer = %q, want %q", got, "100")
+	}
+	screen := out.String()
+	for _, want := range []string{"Question 1 of 3", "> 2. Paris", "Correct!", "Incorrect.", "This question cannot be skipped.", "Type an answer first.", "Score: 2/3 (66%)"} {
+		if !strings.Contains(screen, want) {
+			t.Errorf("Expected the screen to show %q", want)
+		}
+	}
+}
+
+func TestTUI_RunQuizEndedEarly(t *testing.T) {
+	quiz := &Quiz{Config: Config{Title: "Early"}, Questions: sessionQuestions()}
+	var out bytes.Buffer
+	result := newTestTUI(&out, key{kind: keyEnter}, key{kind: keyInterrupt}).RunQuiz(quiz)
+
+	if len(result.Questions) != 3 || !result.Questions[1].Skipped || !result.Questions[2].Skipped {
+		t.Errorf("Expected the questions after Ctrl+C to be skipped, got %+v", result.Questions)
+	}
+}
+
+func TestTUI_ChooseQuiz(t *testing.T) {
+	quizzes := []QuizInfo{
+		{ID: "basics", Index: 1, Title: "Basics"},
+		{ID: "science/physics", Index: 2, Title: "Physics", Category: "science"},
+		{ID: "science/chemistry", Index: 3, Title: "Chemistry", Category: "science", Tags: []string{"atoms"}},
+	}
+	list := func() ([]QuizInfo, []QuizProblem) { return quizzes, nil }
+
+	tests := []struct {
+		name   string
+		keys   []key
+		want   string
+		wantOK bool
+	}{
+		{"Arrow keys", []key{{kind: keyDown}, {kind: keyDown}, {kind: keyDown}, {kind: keyUp}, {kind: keyEnter}}, "science/physics", true},
+		{"Search", append(append([]key{{kind: keyRune, r: '/'}}, typed("atom")...), key{kind: keyEnter}, key{kind: keyEnter}), "science/chemistry", true},
+		{"Category", []key{{kind: keyRune, r: 'c'}, {kind: keyEnter}}, "science/physics", true},
+		{"Quit", []key{{kind: keyDown}, {kind: keyRune, r: 'q'}}, "", false},
+		{"End of input", nil, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			quiz, ok := newTestTUI(&out, tt.keys...).ChooseQuiz(list)
+			if ok != tt.wantOK || quiz.ID != tt.want {
+				t.Errorf("ChooseQuiz() = %q, %v, want %q, %v", quiz.ID, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	got := wrapText("The process of converting water\nfrom liquid to gas", 16)
+	want := []string{"The process of", "converting water", "from liquid to", "gas"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("wrapText() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,357 @@
+package quiz_logic
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//go:embed web
+var webFiles embed.FS
+
+// webSessionLifetime is how long an attempt started in the browser is kept
+const webSessionLifetime = 24 * time.Hour
+
+// InstructorTokenEnv names the token instructors use with the web front end
+// instead of a new one each time it starts
+const InstructorTokenEnv = "QUIZ_INSTRUCTOR_TOKEN"
+
+// NewInstructorToken makes a token that is hard to guess, for
+// NewWebHandler
+func NewInstructorToken() (string, error) {
+	return newSessionID()
+}
+
+// NewWebHandler serves the learner front end, with these routes for the
+// catalog and results as JSON:
+//
+//	GET /quizzes                 quizzes, filtered by ?category=, ?q= and ?maxDuration=
+//	GET /quizzes/{id}            one quiz by ID
+//	GET /problems                quizzes that cannot be taken
+//	GET /results                 results, filtered by ?quiz= and ?learner=
+//	GET /dashboard               how each learner did at each quiz, see Standings,
+//	                             filtered by ?quiz=, ?learner= and ?group=
+//	GET /leaderboards/{id}       a quiz's leaderboard, see LeaderboardOptions
+//
+// these for taking quizzes in the browser:
+//
+//	POST /sessions               start a quiz: {"quiz": id, "learner": name}
+//	GET  /sessions/{id}          the question being asked, or the result
+//	POST /sessions/{id}/answers  answer it: {"answer": text}
+//	POST /sessions/{id}/finish   end the attempt early
+//
+// and these for live rooms, see Room:
+//
+//	POST /rooms                  open one: {"quiz": id, "seconds": per question}
+//	GET  /rooms/{code}           its title, phase and number of players
+//	GET  /rooms/{code}/live      WebSocket, ?host=token or ?name=player
+//
+// An answer to a question with options is its number, as in the terminal.
+// A learner named by the ID or name of one of the profiles kept at
+// profilesPath is recorded under that profile; any other name is recorded
+// as it is, and no profile is added for it. Finished attempts, and
+// everyone's results when a room ends, are recorded at resultsPath.
+//
+// GET /results and GET /dashboard show every learner's results, and POST
+// /rooms makes its caller the host, so they answer only requests with the
+// header "Authorization: Bearer <token>" for instructorToken; with an empty
+// token they answer none.
+func NewWebHandler(catalog *Catalog, resultsPath, profilesPath, instructorToken string) http.Handler {
+	mux := http.NewServeMux()
+	handleAPI(mux, catalog, resultsPath, instructorOnly(instructorToken))
+
+	sessions := newSessionStore(catalog, resultsPath, profilesPath)
+	mux.HandleFunc("POST /sessions", sessions.start)
+	mux.HandleFunc("GET /sessions/{id}", sessions.get)
+	mux.HandleFunc("POST /sessions/{id}/answers", sessions.answer)
+	mux.HandleFunc("POST /sessions/{id}/finish", sessions.finish)
+
+	rooms := newWebRooms(catalog, resultsPath, profilesPath)
+	mux.HandleFunc("POST /rooms", instructorOnly(instructorToken)(rooms.open))
+	mux.HandleFunc("GET /rooms/{code}", rooms.get)
+	mux.HandleFunc("GET /rooms/{code}/live", rooms.live)
+
+	assets, err := fs.Sub(webFiles, "web")
+	if err != nil {
+		panic(err) // the folder is embedded, so this cannot happen
+	}
+	mux.Handle("GET /", http.FileServerFS(assets))
+	return mux
+}
+
+// instructorOnly lets through requests that carry token as a bearer token
+func instructorOnly(token string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if token == "" || !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeAPIError(w, http.StatusUnauthorized, "this needs the instructor token")
+				return
+			}
+			handler(w, r)
+		}
+	}
+}
+
+// sessionStore keeps the attempts started in the browser or over gRPC
+type sessionStore struct {
+	catalog      *Catalog
+	resultsPath  string
+	profilesPath string // learners are looked up in, see LookupProfile
+
+	mu   sync.Mutex
+	byID map[string]*storedSession
+}
+
+func newSessionStore(catalog *Catalog, resultsPath, profilesPath string) *sessionStore {
+	return &sessionStore{catalog: catalog, resultsPath: resultsPath, profilesPath: profilesPath, byID: make(map[string]*storedSession)}
+}
+
+// storedSession is one attempt
+type storedSession struct {
+	mu      sync.Mutex
+	quiz    *Quiz
+	session *Session
+	started time.Time
+	result  *Result // set once the attempt is over
+}
+
+// webState is what the front end needs to show an attempt
+type webState struct {
+	ID            string        `json:"id"`
+	Title         string        `json:"title"`
+	AllowSkipping bool          `json:"allowSkipping"`
+	ShowFeedback  bool          `json:"showFeedback"`
+	Done          int           `json:"done"`
+	Total         int           `json:"total"`
+	TimeLeft      *float64      `json:"timeLeft,omitempty"`  // seconds, when a timer is shown
+	TimeLimit     *float64      `json:"timeLimit,omitempty"` // seconds
+	Question      *QuestionView `json:"question,omitempty"`
+	Result        *Result       `json:"result,omitempty"`
+	Feedback      *webFeedback  `json:"feedback,omitempty"` // on the answer just given
+}
+
+type webFeedback struct {
+	Correct bool `json:"correct"`
+	Skipped bool `json:"skipped"`
+}
+
+func (s *sessionStore) start(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Quiz    string `json:"quiz"`
+		Learner string `json:"learner"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	learner, err := LookupProfile(s.profilesPath, request.Learner)
+	if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	id, attempt, err := s.open(request.Quiz, learner, 0)
+	if errors.Is(err, errNoQuiz) {
+		writeAPIError(w, http.StatusNotFound, err.Error())
+		return
+	} else if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+	writeJSON(w, http.StatusCreated, s.state(id, attempt))
+}
+
+func (s *sessionStore) get(w http.ResponseWriter, r *http.Request) {
+	id, attempt, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+
+	if err := s.endIfOver(attempt); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.state(id, attempt))
+}
+
+func (s *sessionStore) answer(w http.ResponseWriter, r *http.Request) {
+	id, attempt, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	var request struct {
+		Answer string `json:"answer"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid request: "+err.Error())
+		return
+	}
+
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+
+	feedback, err := s.submit(attempt, request.Answer)
+	if errors.Is(err, errCannotSkip) {
+		writeAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	state := s.state(id, attempt)
+	state.Feedback = feedback
+	writeJSON(w, http.StatusOK, state)
+}
+
+func (s *sessionStore) finish(w http.ResponseWriter, r *http.Request) {
+	id, attempt, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	attempt.mu.Lock()
+	defer attempt.mu.Unlock()
+
+	if err := s.end(attempt); err != nil {
+		writeAPIError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, s.state(id, attempt))
+}
+
+func (s *sessionStore) lookup(w http.ResponseWriter, r *http.Request) (string, *storedSession, bool) {
+	id := r.PathValue("id")
+	attempt, ok := s.find(id)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "no quiz session "+id)
+	}
+	return id, attempt, ok
+}
+
+// errNoQuiz is returned for sessions of quizzes that are not in the catalog
+var errNoQuiz = errors.New("no quiz with ID")
+
+// open starts the learner's attempt at the quiz with the given ID or number
+func (s *sessionStore) open(quizRef string, learner Profile, seed int64) (string, *storedSession, error) {
+	quizzes, _ := s.catalog.Quizzes()
+	info, ok := FindQuiz(quizzes, quizRef)
+	if !ok {
+		return "", nil, fmt.Errorf("%w %s", errNoQuiz, quizRef)
+	}
+	quiz, err := info.Load(seed)
+	if err != nil {
+		return "", nil, err
+	}
+	quiz.SetProfile(learner)
+
+	id, err := newSessionID()
+	if err != nil {
+		return "", nil, err
+	}
+	attempt := &storedSession{quiz: quiz, session: quiz.Begin(), started: time.Now()}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for other, old := range s.byID {
+		if time.Since(old.started) > webSessionLifetime {
+			delete(s.byID, other)
+		}
+	}
+	s.byID[id] = attempt
+	return id, attempt, nil
+}
+
+func (s *sessionStore) find(id string) (*storedSession, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	attempt, ok := s.byID[id]
+	return attempt, ok
+}
+
+// errCannotSkip is returned for an empty answer to a quiz that does not
+// allow skipping
+var errCannotSkip = errors.New("this question cannot be skipped")
+
+// submit answers the question being asked, returning the feedback to show
+// if any, and ends the attempt after the last question. The caller holds
+// attempt.mu.
+func (s *sessionStore) submit(attempt *storedSession, answer string) (*webFeedback, error) {
+	var feedback *webFeedback
+	if _, asking := attempt.session.Current(); asking {
+		if answer == "" && !attempt.quiz.Config.Settings.AllowSkipping {
+			return nil, errCannotSkip
+		}
+		correct, skipped := attempt.session.Answer(answer)
+		if attempt.quiz.Config.Settings.ShowFeedbackAfterEach || skipped {
+			feedback = &webFeedback{Correct: correct, Skipped: skipped}
+		}
+	}
+	return feedback, s.endIfOver(attempt)
+}
+
+// endIfOver ends the attempt once every question was answered or the time
+// is up
+func (s *sessionStore) endIfOver(attempt *storedSession) error {
+	if _, asking := attempt.session.Current(); asking {
+		return nil
+	}
+	return s.end(attempt)
+}
+
+// end finishes the attempt and records its result, once
+func (s *sessionStore) end(attempt *storedSession) error {
+	if attempt.result != nil {
+		return nil
+	}
+	result := attempt.session.Finish()
+	attempt.result = &result
+	if s.resultsPath == "" {
+		return nil
+	}
+	return SaveResult(s.resultsPath, result)
+}
+
+func (s *sessionStore) state(id string, attempt *storedSession) webState {
+	settings := attempt.quiz.Config.Settings
+	state := webState{
+		ID:            id,
+		Title:         attempt.quiz.Config.Title,
+		AllowSkipping: settings.AllowSkipping,
+		ShowFeedback:  settings.ShowFeedbackAfterEach,
+		Result:        attempt.result,
+	}
+	state.Done, state.Total = attempt.session.Progress()
+	if left, limit, ok := attempt.session.TimeLeft(); ok && attempt.result == nil {
+		leftSeconds, limitSeconds := left.Seconds(), limit.Seconds()
+		state.TimeLeft, state.TimeLimit = &leftSeconds, &limitSeconds
+	}
+	if question, ok := attempt.session.Current(); ok && attempt.result == nil {
+		state.Question = &question
+	}
+	return state
+}
+
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
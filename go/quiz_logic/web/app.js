@@ -0,0 +1,291 @@
+// Learner front end for the quiz server. It talks to the JSON API served
+// next to it and keeps no state of its own beyond the learner's name.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+let quizzes = [];
+let session = null;  // the attempt being shown
+let deadline = null; // when the time runs out, in ms since the epoch
+let timerInterval = null;
+let given = {};      // the text of each answer given, by question ID
+
+async function api(method, path, body) {
+  const response = await fetch(path, {
+    method,
+    headers: body ? { "Content-Type": "application/json" } : {},
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || response.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err ? String(err.message || err) : "";
+  $("error").hidden = !err;
+}
+
+function showSection(id) {
+  for (const section of ["choose", "question", "result"]) {
+    $(section).hidden = section !== id;
+  }
+}
+
+// Choosing a quiz
+
+async function loadQuizzes() {
+  try {
+    quizzes = await api("GET", "quizzes");
+    showError(null);
+  } catch (err) {
+    showError(err);
+    return;
+  }
+
+  const select = $("category");
+  const chosen = select.value;
+  const categories = new Set();
+  for (const quiz of quizzes) {
+    const parts = (quiz.category || "").split("/").filter(Boolean);
+    for (let i = 1; i <= parts.length; i++) {
+      categories.add(parts.slice(0, i).join("/"));
+    }
+  }
+  select.replaceChildren(new Option("All", ""));
+  for (const category of [...categories].sort()) {
+    select.add(new Option(category, category));
+  }
+  select.value = categories.has(chosen) ? chosen : "";
+  renderQuizzes();
+}
+
+function renderQuizzes() {
+  const category = $("category").value;
+  const keyword = $("search").value.trim().toLowerCase();
+  const list = $("quizzes");
+  list.replaceChildren();
+
+  const matches = quizzes.filter((quiz) => {
+    const inCategory = !category || quiz.category === category || (quiz.category || "").startsWith(category + "/");
+    const text = [quiz.title, quiz.description, ...(quiz.tags || [])].join(" ").toLowerCase();
+    return inCategory && (!keyword || text.includes(keyword));
+  });
+
+  for (const quiz of matches) {
+    const button = document.createElement("button");
+    button.type = "button";
+    button.textContent = quiz.title;
+    const details = [quiz.category, quiz.difficulty, quiz.estimatedDuration && `~${quiz.estimatedDuration} min`]
+      .filter(Boolean).join(" · ");
+    if (details || quiz.description) {
+      const note = document.createElement("span");
+      note.className = "details";
+      note.textContent = [quiz.description, details].filter(Boolean).join(" — ");
+      button.append(note);
+    }
+    button.addEventListener("click", () => startQuiz(quiz.id));
+    const item = document.createElement("li");
+    item.append(button);
+    list.append(item);
+  }
+  $("no-quizzes").hidden = matches.length > 0;
+}
+
+async function startQuiz(id) {
+  const learner = $("learner").value.trim();
+  localStorage.setItem("learner", learner);
+  given = {};
+  try {
+    show(await api("POST", "sessions", { quiz: id, learner }));
+  } catch (err) {
+    showError(err);
+  }
+}
+
+// Answering
+
+function show(state) {
+  session = state;
+  showError(null);
+  if (state.result) {
+    showResult(state.result, deadline !== null && Date.now() >= deadline);
+  } else {
+    showQuestion(state);
+  }
+}
+
+function showQuestion(state) {
+  const question = state.question;
+  showSection("question");
+  $("quiz-title").textContent = state.title;
+  $("progress-text").textContent = `Question ${question.number} of ${state.total}`;
+  $("progress").max = state.total;
+  $("progress").value = state.done;
+  $("question-text").textContent = question.text;
+  $("feedback").hidden = true;
+  $("next").hidden = true;
+  $("submit").hidden = false;
+  $("skip").hidden = !state.allowSkipping;
+  startTimer(state);
+
+  const widget = $("widget");
+  widget.replaceChildren();
+  widget.className = question.type === "true_false" ? "true-false" : "";
+  if (question.options && question.options.length > 0) {
+    question.options.forEach((option, i) => {
+      const label = document.createElement("label");
+      label.className = "option";
+      const input = document.createElement("input");
+      input.type = "radio";
+      input.name = "answer";
+      input.value = String(i + 1); // options are answered by number
+      input.dataset.text = option;
+      label.append(input, option);
+      widget.append(label);
+    });
+  } else {
+    const input = document.createElement("input");
+    input.className = "blank";
+    input.name = "answer";
+    input.autocomplete = "off";
+    input.placeholder = "Type your answer";
+    widget.append(input);
+    input.focus();
+  }
+}
+
+function currentAnswer() {
+  const checked = document.querySelector('#widget input[type="radio"]:checked');
+  if (checked) {
+    return { value: checked.value, text: checked.dataset.text };
+  }
+  const typed = document.querySelector("#widget input.blank");
+  const value = typed ? typed.value.trim() : "";
+  return { value, text: value };
+}
+
+async function answer(skip) {
+  const { value, text } = skip ? { value: "", text: "" } : currentAnswer();
+  if (!skip && value === "") {
+    showError("Choose or type an answer first.");
+    return;
+  }
+  given[session.question.id] = text;
+
+  let state;
+  try {
+    state = await api("POST", `sessions/${session.id}/answers`, { answer: value });
+  } catch (err) {
+    showError(err);
+    return;
+  }
+
+  const feedback = state.feedback;
+  if (!feedback || feedback.skipped || !state.showFeedback) {
+    show(state);
+    return;
+  }
+  const message = $("feedback");
+  message.textContent = feedback.correct ? "Correct!" : "Incorrect.";
+  message.className = "feedback " + (feedback.correct ? "correct" : "incorrect");
+  message.hidden = false;
+  for (const input of document.querySelectorAll("#widget input")) {
+    input.disabled = true;
+  }
+  $("submit").hidden = true;
+  $("skip").hidden = true;
+  $("next").hidden = false;
+  $("next").onclick = () => show(state);
+  $("next").focus();
+}
+
+// Timer
+
+function startTimer(state) {
+  clearInterval(timerInterval);
+  if (state.timeLeft === undefined) {
+    deadline = null;
+    $("timer").hidden = true;
+    return;
+  }
+  deadline = Date.now() + state.timeLeft * 1000;
+  $("timer").hidden = false;
+  $("timer-bar").max = state.timeLimit;
+  const tick = () => {
+    const left = Math.max(0, (deadline - Date.now()) / 1000);
+    const minutes = Math.floor(left / 60);
+    const seconds = Math.floor(left % 60);
+    $("timer-text").textContent = `Time left ${minutes}:${String(seconds).padStart(2, "0")}`;
+    $("timer-bar").value = left;
+    $("timer").classList.toggle("low", left < 60);
+    if (left <= 0) {
+      clearInterval(timerInterval);
+      api("GET", `sessions/${session.id}`).then(show, showError);
+    }
+  };
+  tick();
+  timerInterval = setInterval(tick, 250);
+}
+
+// Results
+
+function showResult(result, timeUp) {
+  clearInterval(timerInterval);
+  showSection("result");
+  $("result-title").textContent = `Quiz completed: ${result.title}`;
+  $("time-up").hidden = !timeUp;
+  $("score").textContent = `Score: ${result.correct}/${result.total} (${result.score}%)`;
+  $("verdict").textContent = result.passed
+    ? "Congratulations! You passed!"
+    : "Sorry, you didn't pass. Keep practicing!";
+
+  const list = $("answers");
+  list.replaceChildren();
+  for (const question of result.questions || []) {
+    const item = document.createElement("li");
+    item.className = question.correct ? "correct" : "incorrect";
+    item.textContent = question.question;
+    const note = document.createElement("span");
+    note.className = "note";
+    if (question.skipped) {
+      note.textContent = "Skipped";
+    } else if (question.correct) {
+      note.textContent = "Correct";
+    } else {
+      const answer = given[question.id] !== undefined ? given[question.id] : question.answer;
+      note.textContent = `You answered ${answer || "nothing"}; expected ${(question.expected || []).join(" or ")}`;
+    }
+    item.append(note);
+    list.append(item);
+  }
+}
+
+// Wiring
+
+$("learner").value = localStorage.getItem("learner") || "";
+$("category").addEventListener("change", renderQuizzes);
+$("search").addEventListener("input", renderQuizzes);
+$("answer-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  answer(false);
+});
+$("skip").addEventListener("click", () => answer(true));
+$("end").addEventListener("click", async () => {
+  if (!confirm("End the quiz now? Questions you have not answered count as skipped.")) {
+    return;
+  }
+  try {
+    show(await api("POST", `sessions/${session.id}/finish`));
+  } catch (err) {
+    showError(err);
+  }
+});
+$("again").addEventListener("click", () => {
+  showSection("choose");
+  loadQuizzes();
+});
+
+loadQuizzes();
@@ -0,0 +1,128 @@
+// Host page of a live room: opens the room, shows the code to join with
+// and moves everyone through the questions.
+"use strict";
+
+let send = null;
+let players = [];
+let question = null; // the question last asked
+
+async function loadQuizzes() {
+  try {
+    const quizzes = await api("GET", "quizzes");
+    const select = $("quiz");
+    for (const quiz of quizzes) {
+      select.add(new Option(quiz.title, quiz.id));
+    }
+  } catch (err) {
+    showError(err);
+  }
+}
+
+// takeToken fills in the instructor token from a link such as
+// host.html#token=..., as printed by quiz serve, and drops it from the
+// address bar
+function takeToken() {
+  const token = new URLSearchParams(location.hash.slice(1)).get("token");
+  if (token) {
+    $("token").value = token;
+    history.replaceState(null, "", location.pathname + location.search);
+  }
+}
+
+async function openRoom() {
+  let room;
+  try {
+    room = await api("POST", "rooms", { quiz: $("quiz").value, seconds: Number($("seconds").value) }, $("token").value);
+  } catch (err) {
+    showError(err);
+    return;
+  }
+  showError(null);
+  for (const title of document.querySelectorAll(".room-title")) {
+    title.textContent = room.title;
+  }
+  $("code").textContent = room.code;
+  $("join-url").textContent = new URL("play.html", location.href).href;
+  showSection("lobby");
+  $("end").hidden = false;
+  send = connect(room.code, { host: room.hostToken }, handle, () => showError("Lost the connection to the room."));
+}
+
+function handle(message) {
+  switch (message.type) {
+    case "players":
+      players = message.players || [];
+      showPlayers();
+      break;
+    case "question":
+      showQuestion(message);
+      break;
+    case "answered":
+      $("answered").textContent = `${message.answered} of ${players.length} answered`;
+      break;
+    case "reveal":
+      stopCountdown();
+      $("expected").textContent = `Answer: ${(message.expected || []).join(" or ")}`;
+      $("right").textContent = `${message.answered || 0} of ${players.length} got it right`;
+      break;
+    case "leaderboard":
+      showSection("leaderboard");
+      $("leaderboard-title").textContent = `After question ${message.number} of ${message.total}`;
+      renderLeaderboard("standings", message.leaderboard, null, 10);
+      $("next").textContent = message.last ? "Show final standings" : "Next question";
+      break;
+    case "finished":
+      stopCountdown();
+      showSection("finished");
+      $("end").hidden = true;
+      renderLeaderboard("final", message.leaderboard);
+      break;
+    case "error":
+      showError(message.error);
+      break;
+  }
+}
+
+function showPlayers() {
+  $("player-count").textContent = players.length === 1 ? "1 player has joined" : `${players.length} players have joined`;
+  $("start").disabled = players.length === 0;
+  const list = $("players");
+  list.replaceChildren();
+  for (const name of players) {
+    const item = document.createElement("li");
+    item.textContent = name;
+    list.append(item);
+  }
+}
+
+function showQuestion(message) {
+  question = message.question;
+  showSection("question");
+  $("question-number").textContent = `Question ${message.number} of ${message.total}`;
+  $("answered").textContent = `0 of ${players.length} answered`;
+  $("question-text").textContent = question.text;
+  const list = $("options");
+  list.replaceChildren();
+  for (const option of question.options || []) {
+    const item = document.createElement("li");
+    item.textContent = option;
+    list.append(item);
+  }
+  startCountdown("countdown", message);
+}
+
+$("setup-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  openRoom();
+});
+$("start").addEventListener("click", () => send({ type: "start" }));
+$("close").addEventListener("click", () => send({ type: "next" }));
+$("next").addEventListener("click", () => send({ type: "next" }));
+$("end").addEventListener("click", () => {
+  if (confirm("End the quiz for everyone now?")) {
+    send({ type: "end" });
+  }
+});
+
+takeToken();
+loadQuizzes();
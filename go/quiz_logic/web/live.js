@@ -0,0 +1,93 @@
+// Shared by the host and player pages of live rooms. Both keep a WebSocket
+// open to the room and redraw themselves from the messages it sends.
+"use strict";
+
+const $ = (id) => document.getElementById(id);
+
+// api calls a JSON route, sending token as the instructor token if given
+async function api(method, path, body, token) {
+  const headers = body ? { "Content-Type": "application/json" } : {};
+  if (token) {
+    headers.Authorization = `Bearer ${token}`;
+  }
+  const response = await fetch(path, {
+    method,
+    headers,
+    body: body ? JSON.stringify(body) : undefined,
+  });
+  const data = await response.json();
+  if (!response.ok) {
+    throw new Error(data.error || response.statusText);
+  }
+  return data;
+}
+
+function showError(err) {
+  $("error").textContent = err ? String(err.message || err) : "";
+  $("error").hidden = !err;
+}
+
+function showSection(id) {
+  for (const section of document.querySelectorAll("main > section")) {
+    section.hidden = section.id !== id;
+  }
+}
+
+// connect opens the room's WebSocket with the given query and passes every
+// message to onMessage; onClose runs if the connection drops
+function connect(code, query, onMessage, onClose) {
+  const url = new URL(`rooms/${encodeURIComponent(code)}/live?${new URLSearchParams(query)}`, location.href);
+  url.protocol = location.protocol === "https:" ? "wss:" : "ws:";
+  const socket = new WebSocket(url);
+  socket.addEventListener("message", (event) => onMessage(JSON.parse(event.data)));
+  socket.addEventListener("close", onClose);
+  return (message) => socket.send(JSON.stringify(message));
+}
+
+let countdownInterval = null;
+
+// startCountdown shows the seconds left for the open question in the
+// element with the given ID, counting down from the time the room sent
+function startCountdown(id, message) {
+  clearInterval(countdownInterval);
+  const deadline = Date.now() + (message.timeLeft || 0) * 1000;
+  const bar = $(id + "-bar");
+  bar.max = message.timeLimit;
+  const tick = () => {
+    const left = Math.max(0, (deadline - Date.now()) / 1000);
+    $(id + "-text").textContent = String(Math.ceil(left));
+    bar.value = left;
+    $(id).classList.toggle("low", left <= 5);
+    if (left <= 0) {
+      clearInterval(countdownInterval);
+    }
+  };
+  tick();
+  countdownInterval = setInterval(tick, 100);
+}
+
+function stopCountdown() {
+  clearInterval(countdownInterval);
+}
+
+// renderLeaderboard fills the list with the given ID, marking the named
+// player, and shows at most limit places when a limit is given
+function renderLeaderboard(id, entries, me, limit) {
+  const list = $(id);
+  list.replaceChildren();
+  for (const entry of (entries || []).slice(0, limit || undefined)) {
+    const item = document.createElement("li");
+    item.classList.toggle("me", entry.name === me);
+    const rank = document.createElement("span");
+    rank.className = "rank";
+    rank.textContent = entry.rank;
+    const name = document.createElement("span");
+    name.className = "name";
+    name.textContent = entry.name;
+    const score = document.createElement("span");
+    score.className = "points";
+    score.textContent = entry.score;
+    item.append(rank, name, score);
+    list.append(item);
+  }
+}
@@ -0,0 +1,129 @@
+// Player page of a live room: joins with the room code and a name, then
+// answers each question the host opens.
+"use strict";
+
+let send = null;
+let code = "";
+let name = "";
+let joined = false; // the room has let us in
+let over = false;   // the quiz in the room has finished
+
+function join() {
+  code = $("code").value.trim().toUpperCase();
+  name = $("name").value.trim();
+  localStorage.setItem("learner", name);
+  showError(null);
+  joined = false;
+  send = connect(code, { name }, handle, () => {
+    stopCountdown();
+    if (!joined) {
+      showSection("join"); // the room has said why
+    } else if (!over) {
+      showSection("lost");
+    }
+  });
+}
+
+function handle(message) {
+  switch (message.type) {
+    case "welcome":
+      joined = true;
+      name = message.name || name; // a profile ID plays under its name
+      for (const title of document.querySelectorAll(".room-title")) {
+        title.textContent = message.title;
+      }
+      for (const element of document.querySelectorAll(".player-name")) {
+        element.textContent = name;
+      }
+      showSection("waiting");
+      break;
+    case "players": {
+      const count = (message.players || []).length;
+      $("player-count").textContent = count === 1 ? "1 player is here" : `${count} players are here`;
+      break;
+    }
+    case "question":
+      showQuestion(message);
+      break;
+    case "reveal":
+      stopCountdown();
+      $("feedback").textContent = message.correct ? `Correct! +${message.gained}` : message.answer ? "Incorrect." : "Time's up!";
+      $("feedback").className = "feedback " + (message.correct ? "correct" : "incorrect");
+      $("expected").textContent = message.correct ? "" : `Answer: ${(message.expected || []).join(" or ")}`;
+      break;
+    case "leaderboard":
+      showSection("standings-section");
+      $("place").textContent = placeText(message.leaderboard);
+      renderLeaderboard("standings", message.leaderboard, name, 5);
+      break;
+    case "finished":
+      over = true;
+      stopCountdown();
+      showSection("finished");
+      $("final-place").textContent = placeText(message.leaderboard);
+      renderLeaderboard("final", message.leaderboard, name);
+      break;
+    case "error":
+      showError(message.error);
+      break;
+  }
+}
+
+function placeText(leaderboard) {
+  const me = (leaderboard || []).find((entry) => entry.name.toLowerCase() === name.toLowerCase());
+  return me ? `You are number ${me.rank} of ${leaderboard.length} with ${me.score} points` : "";
+}
+
+function showQuestion(message) {
+  const question = message.question;
+  showError(null);
+  showSection("question");
+  $("question-number").textContent = `Question ${message.number} of ${message.total}`;
+  $("question-text").textContent = question.text;
+  startCountdown("countdown", message);
+
+  const widget = $("widget");
+  widget.replaceChildren();
+  widget.className = "tiles";
+  if (question.options && question.options.length > 0) {
+    question.options.forEach((option, i) => {
+      const button = document.createElement("button");
+      button.type = "button";
+      button.textContent = option;
+      button.addEventListener("click", () => answer(String(i + 1))); // options are answered by number
+      widget.append(button);
+    });
+  } else {
+    const input = document.createElement("input");
+    input.className = "blank";
+    input.autocomplete = "off";
+    input.placeholder = "Type your answer";
+    const submit = document.createElement("button");
+    submit.type = "submit";
+    submit.textContent = "Answer";
+    widget.className = "";
+    widget.append(input, submit);
+    input.focus();
+  }
+}
+
+function answer(value) {
+  if (value === "") {
+    return;
+  }
+  send({ type: "answer", answer: value });
+  showSection("locked");
+}
+
+$("code").value = new URLSearchParams(location.search).get("code") || "";
+$("name").value = localStorage.getItem("learner") || "";
+$("join-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  join();
+});
+$("answer-form").addEventListener("submit", (event) => {
+  event.preventDefault();
+  const input = document.querySelector("#widget input.blank");
+  answer(input ? input.value.trim() : "");
+});
+$("rejoin").addEventListener("click", join);
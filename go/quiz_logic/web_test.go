@@ -0,0 +1,246 @@
+package quiz_logic
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testInstructorToken is the instructor token of newTestWebServer
+const testInstructorToken = "teacher"
+
+func newTestWebServer(t *testing.T, settings string) (*httptest.Server, string) {
+	t.Helper()
+	resultsPath := filepath.Join(t.TempDir(), "results.jsonl")
+	server := httptest.NewServer(NewWebHandler(newTestCatalog(t, settings), resultsPath, newTestProfiles(t), testInstructorToken))
+	t.Cleanup(server.Close)
+	return server, resultsPath
+}
+
+// newTestProfiles keeps two profiles: grace, Grace Hopper of the navy group,
+// and alan, Alan Turing, without a group
+func newTestProfiles(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "profiles.json")
+	if _, err := AddProfile(path, "Grace Hopper", "grace", "navy"); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+	if _, err := AddProfile(path, "Alan Turing", "alan", ""); err != nil {
+		t.Fatalf("AddProfile() error = %v", err)
+	}
+	return path
+}
+
+// newTestCatalog holds one quiz, capitals, with a multiple choice question
+// answered by Paris and a fill in the blank one answered by Rome
+func newTestCatalog(t *testing.T, settings string) *Catalog {
+	t.Helper()
+	root := t.TempDir()
+	quizDir := filepath.Join(root, "capitals")
+	if err := os.MkdirAll(quizDir, 0755); err != nil {
+		t.Fatalf("Failed to create quiz: %v", err)
+	}
+	files := map[string]string{
+		"config.json": `{"title": "Capitals", "passingScore": 50, "questions": [["france"], ["italy"]], "settings": ` + settings + `}`,
+		"france.json": `{"question": "Capital of France?", "type": "multiple_choice", "options": ["Paris", "Lyon"], "answers": ["Paris"]}`,
+		"italy.json":  `{"question": "Capital of Italy?", "type": "fill_in_blank", "answers": ["Rome"]}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(quizDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	catalog, err := NewCatalog(Discovery{Roots: []string{root}}, nil)
+	if err != nil {
+		t.Fatalf("NewCatalog() error = %v", err)
+	}
+	return catalog
+}
+
+// call makes a request and decodes the JSON reply into a webState
+func call(t *testing.T, method, url, body string, wantStatus int) webState {
+	t.Helper()
+	return callAs(t, "", method, url, body, wantStatus)
+}
+
+// callAs is call with token sent as the bearer token, if any
+func callAs(t *testing.T, token, method, url, body string, wantStatus int) webState {
+	t.Helper()
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer response.Body.Close()
+
+	var buf bytes.Buffer
+	buf.ReadFrom(response.Body)
+	if response.StatusCode != wantStatus {
+		t.Fatalf("%s %s status = %d, want %d: %s", method, url, response.StatusCode, wantStatus, buf.String())
+	}
+	var state webState
+	json.Unmarshal(buf.Bytes(), &state)
+	return state
+}
+
+func TestWebHandler_TakeQuiz(t *testing.T) {
+	server, resultsPath := newTestWebServer(t, `{"showFeedbackAfterEach": true}`)
+
+	state := call(t, "POST", server.URL+"/sessions", `{"quiz": "capitals", "learner": "ada"}`, http.StatusCreated)
+	if state.Question == nil || state.Question.Number != 1 || state.Total != 2 || !state.ShowFeedback || state.AllowSkipping {
+		t.Fatalf("Started session = %+v", state)
+	}
+	if state.TimeLeft != nil {
+		t.Error("Expected no timer for a quiz without a time limit")
+	}
+	sessionURL := server.URL + "/sessions/" + state.ID
+
+	// Skipping is not allowed by this quiz
+	call(t, "POST", sessionURL+"/answers", `{"answer": ""}`, http.StatusBadRequest)
+
+	for state.Question != nil {
+		answer := "Rome"
+		if state.Question.ID == "france" {
+			answer = "9" // not an option, so wrong
+		}
+		state = call(t, "POST", sessionURL+"/answers", `{"answer": "`+answer+`"}`, http.StatusOK)
+		if state.Feedback == nil || state.Feedback.Correct != (answer == "Rome") {
+			t.Errorf("Feedback on %q = %+v", answer, state.Feedback)
+		}
+	}
+
+	if state.Result == nil || state.Result.Correct != 1 || state.Result.Learner != "ada" || !state.Result.Passed {
+		t.Fatalf("Finished session result = %+v", state.Result)
+	}
+	if again := call(t, "GET", sessionURL, "", http.StatusOK); again.Result == nil || again.Question != nil {
+		t.Errorf("GET after the last answer = %+v, want the result", again)
+	}
+
+	results, err := LoadResults(resultsPath)
+	if err != nil || len(results) != 1 || results[0].QuizID != "capitals" {
+		t.Errorf("Recorded results = %+v, %v, want the one attempt", results, err)
+	}
+}
+
+func TestWebHandler_FinishEarly(t *testing.T) {
+	server, resultsPath := newTestWebServer(t, `{"allowSkipping": true, "showTimer": true}`)
+
+	state := call(t, "POST", server.URL+"/sessions", `{"quiz": "capitals"}`, http.StatusCreated)
+	sessionURL := server.URL + "/sessions/" + state.ID
+
+	state = call(t, "POST", sessionURL+"/answers", `{"answer": ""}`, http.StatusOK)
+	if state.Feedback == nil || !state.Feedback.Skipped {
+		t.Errorf("Skipping gave feedback %+v, want skipped", state.Feedback)
+	}
+	state = call(t, "POST", sessionURL+"/finish", "", http.StatusOK)
+	if state.Result == nil || state.Result.Correct != 0 || len(state.Result.Questions) != 2 {
+		t.Fatalf("Finished early result = %+v", state.Result)
+	}
+	call(t, "POST", sessionURL+"/finish", "", http.StatusOK) // already over
+
+	if results, _ := LoadResults(resultsPath); len(results) != 1 {
+		t.Errorf("Expected the attempt to be recorded once, got %d results", len(results))
+	}
+}
+
+func TestWebHandler_Profiles(t *testing.T) {
+	server, resultsPath := newTestWebServer(t, `{}`)
+
+	for _, learner := range []string{"GRACE", "Alan Turing", "Ada"} {
+		state := call(t, "POST", server.URL+"/sessions", `{"quiz": "capitals", "learner": "`+learner+`"}`, http.StatusCreated)
+		call(t, "POST", server.URL+"/sessions/"+state.ID+"/finish", "", http.StatusOK)
+	}
+
+	results, err := LoadResults(resultsPath)
+	if err != nil || len(results) != 3 {
+		t.Fatalf("Recorded results = %+v, %v", results, err)
+	}
+	if grace := results[0]; grace.Learner != "Grace Hopper" || grace.LearnerID != "grace" || grace.Group != "navy" {
+		t.Errorf("Result of a learner giving a profile ID = %+v, want it under their profile", grace)
+	}
+	if alan := results[1]; alan.Learner != "Alan Turing" || alan.LearnerID != "alan" {
+		t.Errorf("Result of a learner giving a profile name = %+v, want it under their profile", alan)
+	}
+	if ada := results[2]; ada.Learner != "Ada" || ada.LearnerID != "" {
+		t.Errorf("Result of a learner without a profile = %+v, want just the name", ada)
+	}
+}
+
+func TestWebHandler_Errors(t *testing.T) {
+	server, _ := newTestWebServer(t, `{}`)
+
+	call(t, "POST", server.URL+"/sessions", `{"quiz": "history"}`, http.StatusNotFound)
+	call(t, "POST", server.URL+"/sessions", `not json`, http.StatusBadRequest)
+	call(t, "GET", server.URL+"/sessions/unknown", "", http.StatusNotFound)
+	call(t, "GET", server.URL+"/quizzes", "", http.StatusOK)
+}
+
+func TestWebHandler_InstructorRoutes(t *testing.T) {
+	server, _ := newTestWebServer(t, `{}`)
+	call(t, "POST", server.URL+"/sessions", `{"quiz": "capitals", "learner": "ada"}`, http.StatusCreated)
+
+	for _, path := range []string{"/results", "/dashboard"} {
+		for _, tt := range []struct {
+			authorization string
+			want          int
+		}{
+			{"", http.StatusUnauthorized},
+			{"Bearer student", http.StatusUnauthorized},
+			{testInstructorToken, http.StatusUnauthorized},
+			{"Bearer " + testInstructorToken, http.StatusOK},
+		} {
+			request, _ := http.NewRequest("GET", server.URL+path, nil)
+			if tt.authorization != "" {
+				request.Header.Set("Authorization", tt.authorization)
+			}
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatalf("GET %s error = %v", path, err)
+			}
+			response.Body.Close()
+			if response.StatusCode != tt.want {
+				t.Errorf("GET %s with %q status = %d, want %d", path, tt.authorization, response.StatusCode, tt.want)
+			}
+		}
+	}
+
+	closed := httptest.NewServer(NewWebHandler(newTestCatalog(t, `{}`), "", "", ""))
+	defer closed.Close()
+	request, _ := http.NewRequest("GET", closed.URL+"/results", nil)
+	request.Header.Set("Authorization", "Bearer ")
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		t.Fatalf("GET /results error = %v", err)
+	}
+	response.Body.Close()
+	if response.StatusCode != http.StatusUnauthorized {
+		t.Errorf("GET /results with no instructor token set = %d, want refused", response.StatusCode)
+	}
+}
+
+func TestWebHandler_Assets(t *testing.T) {
+	server, _ := newTestWebServer(t, `{}`)
+
+	for _, asset := range []string{"/", "/app.js", "/style.css"} {
+		response, err := http.Get(server.URL + asset)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", asset, err)
+		}
+		response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			t.Errorf("GET %s status = %d", asset, response.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+// Package quizpb holds the gRPC service of the quiz engine, generated from
+// quiz.proto. The server is quiz_logic.GRPCServer.
+package quizpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative quiz.proto